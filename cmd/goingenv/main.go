@@ -1,7 +1,6 @@
 package main
 
 import (
-	"fmt"
 	"os"
 
 	"goingenv/internal/cli"
@@ -15,10 +14,5 @@ var (
 
 func main() {
 	// Initialize and execute the root command
-	rootCmd := cli.NewRootCommand(Version)
-
-	if err := rootCmd.Execute(); err != nil {
-		fmt.Printf("Error: %v\n", err)
-		os.Exit(1)
-	}
+	os.Exit(cli.Execute(Version))
 }
@@ -0,0 +1,150 @@
+package agent
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"goingenv/internal/crypto"
+)
+
+// startAgent runs an agent on a socket in a temporary directory
+func startAgent(t *testing.T) (*Server, *Client) {
+	t.Helper()
+	if !Supported() {
+		t.Skip("key agent not supported on this platform")
+	}
+
+	tmpDir, err := os.MkdirTemp("", "goingenv-agent-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	path := filepath.Join(tmpDir, "run", "agent.sock")
+	l, err := Listen(path)
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+
+	srv := NewServer()
+	go srv.Serve(l) //nolint:errcheck // stopped by cleanup
+	t.Cleanup(srv.Stop)
+
+	return srv, NewClient(path)
+}
+
+func TestAgentAddGetLock(t *testing.T) {
+	_, client := startAgent(t)
+
+	if _, err := client.Get("/project"); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Get() before Add error = %v, want ErrLocked", err)
+	}
+
+	if err := client.Add("/project", "correct horse", time.Minute); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	got, err := client.Get("/project")
+	if err != nil {
+		t.Fatalf("Get() error = %v", err)
+	}
+	if got != "correct horse" {
+		t.Errorf("Get() = %q, want the added password", got)
+	}
+	if _, err := client.Get("/other"); !errors.Is(err, ErrLocked) {
+		t.Errorf("Get() for another scope error = %v, want ErrLocked", err)
+	}
+
+	if err := client.Lock("/project"); err != nil {
+		t.Fatalf("Lock() error = %v", err)
+	}
+	if _, err := client.Get("/project"); !errors.Is(err, ErrLocked) {
+		t.Errorf("Get() after Lock error = %v, want ErrLocked", err)
+	}
+}
+
+func TestAgentDerive(t *testing.T) {
+	_, client := startAgent(t)
+	salt := bytes.Repeat([]byte{7}, crypto.SaltSize)
+
+	if err := client.Add("/project", "correct horse", time.Minute); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+
+	key, err := client.Derive("/project", "correct horse", salt)
+	if err != nil {
+		t.Fatalf("Derive() error = %v", err)
+	}
+	if !bytes.Equal(key, crypto.DeriveKey("correct horse", salt)) {
+		t.Error("Derive() returned a key that differs from PBKDF2")
+	}
+
+	if _, err := client.Derive("/project", "wrong", salt); err == nil {
+		t.Error("Derive() with a different password should fail")
+	}
+
+	status, err := client.Status()
+	if err != nil {
+		t.Fatalf("Status() error = %v", err)
+	}
+	if len(status.Entries) != 1 || status.Entries[0].CachedKeys != 1 {
+		t.Errorf("Status() entries = %+v, want one entry with one cached key", status.Entries)
+	}
+}
+
+func TestAgentExpiry(t *testing.T) {
+	srv := NewServer()
+	now := time.Now()
+	srv.now = func() time.Time { return now }
+
+	if resp := srv.Handle(Request{Op: OpAdd, Scope: "/project", Password: "secret", TTL: 60}); !resp.OK {
+		t.Fatalf("Handle(add) error = %s", resp.Error)
+	}
+
+	now = now.Add(59 * time.Second)
+	if resp := srv.Handle(Request{Op: OpGet, Scope: "/project"}); !resp.OK {
+		t.Errorf("Handle(get) before expiry error = %s", resp.Error)
+	}
+
+	now = now.Add(time.Second)
+	if resp := srv.Handle(Request{Op: OpGet, Scope: "/project"}); resp.OK {
+		t.Error("Handle(get) after expiry should fail")
+	}
+}
+
+func TestAgentStop(t *testing.T) {
+	srv, client := startAgent(t)
+
+	if err := client.Stop(); err != nil {
+		t.Fatalf("Stop() error = %v", err)
+	}
+	select {
+	case <-srv.Done():
+	case <-time.After(time.Second):
+		t.Fatal("agent did not stop")
+	}
+	if _, err := client.Status(); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Status() after Stop error = %v, want ErrNotRunning", err)
+	}
+}
+
+func TestListenRejectsSharedDir(t *testing.T) {
+	if !Supported() {
+		t.Skip("key agent not supported on this platform")
+	}
+	tmpDir, err := os.MkdirTemp("", "goingenv-agent-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if chmodErr := os.Chmod(tmpDir, 0o777); chmodErr != nil {
+		t.Fatalf("Failed to chmod: %v", chmodErr)
+	}
+	if _, err := Listen(filepath.Join(tmpDir, "agent.sock")); err == nil {
+		t.Error("Listen() should refuse a world-writable directory")
+	}
+}
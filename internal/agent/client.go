@@ -0,0 +1,106 @@
+package agent
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"time"
+)
+
+// requestTimeout bounds a whole request, including key derivation
+const requestTimeout = 10 * time.Second
+
+// Client talks to a running agent
+type Client struct {
+	path string
+}
+
+// NewClient creates a client for the agent socket at path
+func NewClient(path string) *Client {
+	return &Client{path: path}
+}
+
+// Add unlocks scope with password for ttl (DefaultTTL when zero)
+func (c *Client) Add(scope, password string, ttl time.Duration) error {
+	_, err := c.call(Request{Op: OpAdd, Scope: scope, Password: password, TTL: int64(ttl / time.Second)})
+	return err
+}
+
+// Get returns the unlocked password for scope
+func (c *Client) Get(scope string) (string, error) {
+	resp, err := c.call(Request{Op: OpGet, Scope: scope})
+	if err != nil {
+		return "", err
+	}
+	return resp.Password, nil
+}
+
+// Derive returns the key for password and salt, cached by the agent
+func (c *Client) Derive(scope, password string, salt []byte) ([]byte, error) {
+	resp, err := c.call(Request{Op: OpDerive, Scope: scope, Password: password, Salt: salt})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Key, nil
+}
+
+// Lock forgets the password for scope, or every password when scope is empty
+func (c *Client) Lock(scope string) error {
+	_, err := c.call(Request{Op: OpLock, Scope: scope})
+	return err
+}
+
+// Status describes the running agent
+func (c *Client) Status() (*Status, error) {
+	resp, err := c.call(Request{Op: OpStatus})
+	if err != nil {
+		return nil, err
+	}
+	return resp.Status, nil
+}
+
+// Stop shuts the agent down, wiping everything it holds
+func (c *Client) Stop() error {
+	_, err := c.call(Request{Op: OpStop})
+	return err
+}
+
+// call sends one request and waits for its response
+func (c *Client) call(req Request) (*Response, error) {
+	conn, err := net.DialTimeout("unix", c.path, dialTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNotRunning, err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(requestTimeout)) //nolint:errcheck // best effort
+
+	if encErr := json.NewEncoder(conn).Encode(req); encErr != nil {
+		return nil, fmt.Errorf("failed to send agent request: %w", encErr)
+	}
+
+	var resp Response
+	if decErr := json.NewDecoder(conn).Decode(&resp); decErr != nil {
+		return nil, fmt.Errorf("failed to read agent response: %w", decErr)
+	}
+	if !resp.OK {
+		if resp.Error == ErrLocked.Error() {
+			return nil, ErrLocked
+		}
+		return nil, errors.New(resp.Error)
+	}
+	return &resp, nil
+}
+
+// KeyDeriver derives keys through the agent for the password it holds for
+// a scope. It implements crypto.KeyDeriver.
+type KeyDeriver struct {
+	Client *Client
+	Scope  string
+}
+
+// DeriveKey asks the agent for the key; callers fall back to local
+// derivation when it fails
+func (d *KeyDeriver) DeriveKey(password string, salt []byte) ([]byte, error) {
+	return d.Client.Derive(d.Scope, password, salt)
+}
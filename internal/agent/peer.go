@@ -0,0 +1,34 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net"
+)
+
+// checkPeer refuses connections from any user other than the agent's owner
+func checkPeer(conn net.Conn) error {
+	unixConn, ok := conn.(*net.UnixConn)
+	if !ok {
+		return errors.New("not a unix socket connection")
+	}
+	raw, err := unixConn.SyscallConn()
+	if err != nil {
+		return fmt.Errorf("failed to inspect connection: %w", err)
+	}
+
+	uid := -1
+	var credErr error
+	if ctrlErr := raw.Control(func(fd uintptr) {
+		uid, credErr = peerUID(fd)
+	}); ctrlErr != nil {
+		return fmt.Errorf("failed to inspect connection: %w", ctrlErr)
+	}
+	if credErr != nil {
+		return fmt.Errorf("failed to read peer credentials: %w", credErr)
+	}
+	if uid != currentUID() {
+		return fmt.Errorf("connection from user %d refused", uid)
+	}
+	return nil
+}
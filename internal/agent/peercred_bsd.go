@@ -0,0 +1,28 @@
+//go:build darwin || freebsd
+
+package agent
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// Supported reports whether the agent can verify its peers on this platform
+func Supported() bool {
+	return true
+}
+
+// peerUID returns the user ID of the process on the other end of fd
+func peerUID(fd uintptr) (int, error) {
+	cred, err := unix.GetsockoptXucred(int(fd), unix.SOL_LOCAL, unix.LOCAL_PEERCRED)
+	if err != nil {
+		return -1, err
+	}
+	return int(cred.Uid), nil
+}
+
+// currentUID returns the user ID the agent runs as
+func currentUID() int {
+	return os.Getuid()
+}
@@ -0,0 +1,20 @@
+//go:build !linux && !darwin && !freebsd
+
+package agent
+
+import "errors"
+
+// Supported reports whether the agent can verify its peers on this platform
+func Supported() bool {
+	return false
+}
+
+// peerUID is unavailable here, so every peer is refused
+func peerUID(fd uintptr) (int, error) {
+	return -1, errors.New("peer credentials are not supported on this platform")
+}
+
+// currentUID returns a value no peer can match
+func currentUID() int {
+	return -1
+}
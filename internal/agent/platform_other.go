@@ -0,0 +1,23 @@
+//go:build !unix
+
+package agent
+
+import (
+	"os"
+	"syscall"
+)
+
+// fileOwner is unknown on this platform
+func fileOwner(info os.FileInfo) (int, bool) {
+	return 0, false
+}
+
+// DetachedProcAttr has nothing to add on this platform
+func DetachedProcAttr() *syscall.SysProcAttr {
+	return nil
+}
+
+// DisableCoreDumps has nothing to do on this platform
+func DisableCoreDumps() error {
+	return nil
+}
@@ -0,0 +1,63 @@
+// Package agent implements a local daemon that caches unlocked archive
+// passwords and derived keys in memory for a limited time, so consecutive
+// commands don't prompt and run PBKDF2 again. Nothing it holds is written to
+// disk; access is limited to the owning user by socket permissions and a
+// peer-credential check on every connection.
+package agent
+
+import (
+	"errors"
+	"time"
+)
+
+// Operations understood by the agent
+const (
+	OpAdd    = "add"
+	OpGet    = "get"
+	OpDerive = "derive"
+	OpLock   = "lock"
+	OpStatus = "status"
+	OpStop   = "stop"
+)
+
+// DefaultTTL is how long an added password stays unlocked
+const DefaultTTL = 15 * time.Minute
+
+// ErrNotRunning is returned when no agent listens on the socket
+var ErrNotRunning = errors.New("agent is not running")
+
+// ErrLocked is returned when the agent holds no password for a scope
+var ErrLocked = errors.New("no unlocked password for this project")
+
+// Request is one message sent to the agent. Each connection carries a single
+// request and response, encoded as JSON lines.
+type Request struct {
+	Op       string `json:"op"`
+	Scope    string `json:"scope,omitempty"`
+	Password string `json:"password,omitempty"`
+	Salt     []byte `json:"salt,omitempty"`
+	TTL      int64  `json:"ttl_seconds,omitempty"`
+}
+
+// Response is the agent's reply to a Request
+type Response struct {
+	OK       bool    `json:"ok"`
+	Error    string  `json:"error,omitempty"`
+	Password string  `json:"password,omitempty"`
+	Key      []byte  `json:"key,omitempty"`
+	Status   *Status `json:"status,omitempty"`
+}
+
+// Status describes a running agent without revealing any secret
+type Status struct {
+	PID     int           `json:"pid"`
+	Started time.Time     `json:"started"`
+	Entries []EntryStatus `json:"entries"`
+}
+
+// EntryStatus describes one unlocked scope
+type EntryStatus struct {
+	Scope      string    `json:"scope"`
+	Expires    time.Time `json:"expires"`
+	CachedKeys int       `json:"cached_keys"`
+}
@@ -0,0 +1,284 @@
+package agent
+
+import (
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"goingenv/internal/crypto"
+)
+
+// connTimeout bounds how long a client may take to send its request
+const connTimeout = 5 * time.Second
+
+// maxRequestSize bounds a single request line
+const maxRequestSize = 64 * 1024
+
+// entry is one unlocked password and the keys derived from it
+type entry struct {
+	password []byte
+	keys     map[string][]byte // hex salt -> derived key
+	expires  time.Time
+}
+
+// wipe overwrites the secrets held by the entry
+func (e *entry) wipe() {
+	clear(e.password)
+	for salt, key := range e.keys {
+		clear(key)
+		delete(e.keys, salt)
+	}
+}
+
+// Server holds unlocked passwords in memory and answers client requests
+type Server struct {
+	mu      sync.Mutex
+	entries map[string]*entry
+	started time.Time
+	now     func() time.Time
+
+	listener net.Listener
+	stopOnce sync.Once
+	done     chan struct{}
+}
+
+// NewServer creates an agent server with no unlocked passwords
+func NewServer() *Server {
+	return &Server{
+		entries: make(map[string]*entry),
+		started: time.Now(),
+		now:     time.Now,
+		done:    make(chan struct{}),
+	}
+}
+
+// Serve accepts connections on l until Stop is called. Connections from
+// other users are closed without reading them.
+func (s *Server) Serve(l net.Listener) error {
+	s.mu.Lock()
+	s.listener = l
+	s.mu.Unlock()
+	go s.expireLoop()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			select {
+			case <-s.done:
+				return nil
+			default:
+			}
+			var netErr net.Error
+			if errors.As(err, &netErr) && netErr.Timeout() {
+				continue
+			}
+			return fmt.Errorf("agent accept failed: %w", err)
+		}
+		go s.handleConn(conn)
+	}
+}
+
+// Stop closes the listener and wipes every unlocked password
+func (s *Server) Stop() {
+	s.stopOnce.Do(func() {
+		close(s.done)
+		s.mu.Lock()
+		l := s.listener
+		s.mu.Unlock()
+		if l != nil {
+			_ = l.Close() //nolint:errcheck // shutting down
+		}
+		s.lock("")
+	})
+}
+
+// Done is closed once the server has been stopped
+func (s *Server) Done() <-chan struct{} {
+	return s.done
+}
+
+// handleConn answers the single request sent on conn
+func (s *Server) handleConn(conn net.Conn) {
+	defer conn.Close()
+
+	if err := checkPeer(conn); err != nil {
+		return
+	}
+	_ = conn.SetDeadline(time.Now().Add(connTimeout)) //nolint:errcheck // best effort
+
+	var req Request
+	if err := json.NewDecoder(io.LimitReader(conn, maxRequestSize)).Decode(&req); err != nil {
+		_ = json.NewEncoder(conn).Encode(Response{Error: "invalid request"}) //nolint:errcheck // client went away
+		return
+	}
+
+	resp := s.Handle(req)
+	_ = json.NewEncoder(conn).Encode(resp) //nolint:errcheck // client went away
+
+	if req.Op == OpStop && resp.OK {
+		s.Stop()
+	}
+}
+
+// Handle answers one request
+func (s *Server) Handle(req Request) Response {
+	s.expire()
+
+	switch req.Op {
+	case OpAdd:
+		if req.Scope == "" || req.Password == "" {
+			return Response{Error: "scope and password are required"}
+		}
+		ttl := DefaultTTL
+		if req.TTL > 0 {
+			ttl = time.Duration(req.TTL) * time.Second
+		}
+		s.add(req.Scope, req.Password, ttl)
+		return Response{OK: true}
+
+	case OpGet:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		e, ok := s.entries[req.Scope]
+		if !ok {
+			return Response{Error: ErrLocked.Error()}
+		}
+		return Response{OK: true, Password: string(e.password)}
+
+	case OpDerive:
+		key, err := s.derive(req.Scope, req.Password, req.Salt)
+		if err != nil {
+			return Response{Error: err.Error()}
+		}
+		return Response{OK: true, Key: key}
+
+	case OpLock:
+		s.lock(req.Scope)
+		return Response{OK: true}
+
+	case OpStatus:
+		return Response{OK: true, Status: s.status()}
+
+	case OpStop:
+		return Response{OK: true}
+
+	default:
+		return Response{Error: fmt.Sprintf("unknown operation %q", req.Op)}
+	}
+}
+
+// add unlocks scope with password for ttl, replacing any previous entry
+func (s *Server) add(scope, password string, ttl time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if old, ok := s.entries[scope]; ok {
+		old.wipe()
+	}
+	s.entries[scope] = &entry{
+		password: []byte(password),
+		keys:     make(map[string][]byte),
+		expires:  s.now().Add(ttl),
+	}
+}
+
+// derive returns the key for salt, deriving and caching it on first use.
+// The caller must present the unlocked password so a cached key is never
+// handed out for a different one.
+func (s *Server) derive(scope, password string, salt []byte) ([]byte, error) {
+	if len(salt) == 0 {
+		return nil, errors.New("salt is required")
+	}
+
+	s.mu.Lock()
+	e, ok := s.entries[scope]
+	if !ok {
+		s.mu.Unlock()
+		return nil, ErrLocked
+	}
+	if subtle.ConstantTimeCompare(e.password, []byte(password)) != 1 {
+		s.mu.Unlock()
+		return nil, errors.New("password does not match the unlocked password")
+	}
+	id := hex.EncodeToString(salt)
+	if key, cached := e.keys[id]; cached {
+		s.mu.Unlock()
+		return append([]byte(nil), key...), nil
+	}
+	s.mu.Unlock()
+
+	// Derive outside the lock; PBKDF2 is deliberately slow
+	key := crypto.DeriveKey(password, salt)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current, stillOpen := s.entries[scope]; stillOpen && current == e {
+		e.keys[id] = append([]byte(nil), key...)
+	}
+	return key, nil
+}
+
+// lock wipes the entry for scope, or every entry when scope is empty
+func (s *Server) lock(scope string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for name, e := range s.entries {
+		if scope == "" || name == scope {
+			e.wipe()
+			delete(s.entries, name)
+		}
+	}
+}
+
+// expire wipes entries whose TTL has passed
+func (s *Server) expire() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	for name, e := range s.entries {
+		if !now.Before(e.expires) {
+			e.wipe()
+			delete(s.entries, name)
+		}
+	}
+}
+
+// expireLoop expires entries periodically so secrets don't outlive their
+// TTL while the agent sits idle
+func (s *Server) expireLoop() {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.expire()
+		}
+	}
+}
+
+// status lists the unlocked scopes, soonest expiry first
+func (s *Server) status() *Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	st := &Status{PID: os.Getpid(), Started: s.started, Entries: []EntryStatus{}}
+	for name, e := range s.entries {
+		st.Entries = append(st.Entries, EntryStatus{Scope: name, Expires: e.expires, CachedKeys: len(e.keys)})
+	}
+	sort.Slice(st.Entries, func(i, j int) bool {
+		return st.Entries[i].Expires.Before(st.Entries[j].Expires)
+	})
+	return st
+}
@@ -0,0 +1,84 @@
+package agent
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SocketEnv overrides the agent socket path
+const SocketEnv = "GOINGENV_AGENT_SOCK"
+
+// dialTimeout bounds connecting to the agent, so commands fall back to the
+// password prompt quickly when it is stuck
+const dialTimeout = time.Second
+
+// SocketPath returns the agent socket path: $GOINGENV_AGENT_SOCK, else a
+// per-user directory under $XDG_RUNTIME_DIR or the system temp directory
+func SocketPath() string {
+	if path := os.Getenv(SocketEnv); path != "" {
+		return path
+	}
+	base := os.Getenv("XDG_RUNTIME_DIR")
+	if base == "" {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, fmt.Sprintf("goingenv-%d", os.Getuid()), "agent.sock")
+}
+
+// Listen creates the agent socket at path. The parent directory must be
+// private to the current user; it is created with mode 0700 if missing.
+func Listen(path string) (net.Listener, error) {
+	if !Supported() {
+		return nil, errors.New("the key agent is not supported on this platform")
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create agent directory: %w", err)
+	}
+	if err := checkPrivateDir(dir); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Lstat(path); err == nil {
+		if conn, dialErr := net.DialTimeout("unix", path, dialTimeout); dialErr == nil {
+			conn.Close()
+			return nil, fmt.Errorf("an agent is already listening on %s", path)
+		}
+		if removeErr := os.Remove(path); removeErr != nil {
+			return nil, fmt.Errorf("failed to remove stale agent socket: %w", removeErr)
+		}
+	}
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", path, err)
+	}
+	if chmodErr := os.Chmod(path, 0o600); chmodErr != nil {
+		l.Close()
+		return nil, fmt.Errorf("failed to restrict agent socket: %w", chmodErr)
+	}
+	return l, nil
+}
+
+// checkPrivateDir refuses a socket directory other users could tamper with
+func checkPrivateDir(dir string) error {
+	info, err := os.Lstat(dir)
+	if err != nil {
+		return fmt.Errorf("failed to check agent directory: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("agent directory %s is not a directory", dir)
+	}
+	if info.Mode().Perm()&0o077 != 0 {
+		return fmt.Errorf("agent directory %s must not be accessible by other users (mode %o)", dir, info.Mode().Perm())
+	}
+	if uid, ok := fileOwner(info); ok && uid != os.Getuid() {
+		return fmt.Errorf("agent directory %s is owned by another user", dir)
+	}
+	return nil
+}
@@ -2,18 +2,24 @@ package archive
 
 import (
 	"archive/tar"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"goingenv/internal/config"
 	"goingenv/pkg/types"
+	"goingenv/pkg/utils"
 )
 
+// metadataName is the tar entry holding the archive metadata
+const metadataName = "metadata.json"
+
 // Service implements the Archiver interface
 type Service struct {
 	crypto types.Cryptor
@@ -49,6 +55,9 @@ func (s *Service) Pack(opts types.PackOptions) error {
 		TotalSize:   totalSize,
 		Description: opts.Description,
 		Version:     "1.0.0", // You might want to make this configurable
+		Profile:     opts.Profile,
+		NotBefore:   opts.NotBefore,
+		ExpiresAt:   opts.ExpiresAt,
 	}
 
 	// Create temporary file for the tar archive
@@ -185,7 +194,7 @@ func backupFile(path string) error {
 
 // handleExisting handles existing file (skip, backup, or overwrite)
 func handleExisting(path string, overwrite, backup bool) (skip bool, err error) {
-	if _, statErr := os.Stat(path); os.IsNotExist(statErr) {
+	if _, statErr := os.Lstat(path); os.IsNotExist(statErr) {
 		return false, nil // file doesn't exist, proceed
 	}
 
@@ -219,7 +228,7 @@ func (s *Service) decryptArchive(archivePath, password string) ([]byte, error) {
 
 // extractEntry extracts a single tar entry
 func (s *Service) extractEntry(tarReader *tar.Reader, header *tar.Header, opts types.UnpackOptions) error {
-	if header.Name == "metadata.json" {
+	if header.Name == metadataName {
 		return nil // skip metadata
 	}
 
@@ -228,6 +237,21 @@ func (s *Service) extractEntry(tarReader *tar.Reader, header *tar.Header, opts t
 		return pathErr
 	}
 
+	if header.Typeflag == tar.TypeSymlink {
+		if linkErr := safeLinkTarget(header.Name, header.Linkname, opts.TargetDir); linkErr != nil {
+			return linkErr
+		}
+	}
+
+	// Creating directories or files below a symlink would follow it, and a
+	// chain of individually safe links can point anywhere
+	if dir := parentLink(header.Name, func(dir string) bool {
+		info, err := os.Lstat(filepath.Join(opts.TargetDir, filepath.FromSlash(dir)))
+		return err == nil && info.Mode()&os.ModeSymlink != 0
+	}); dir != "" {
+		return fmt.Errorf("unsafe path detected: %s passes through symlink %s", header.Name, dir)
+	}
+
 	if dirErr := ensureDir(targetPath); dirErr != nil {
 		return fmt.Errorf("failed to create directory: %w", dirErr)
 	}
@@ -240,6 +264,9 @@ func (s *Service) extractEntry(tarReader *tar.Reader, header *tar.Header, opts t
 		return nil
 	}
 
+	if header.Typeflag == tar.TypeSymlink {
+		return s.extractLink(targetPath, header)
+	}
 	return s.extractFile(tarReader, targetPath, header)
 }
 
@@ -269,6 +296,17 @@ func (s *Service) Unpack(opts types.UnpackOptions) error {
 			}
 		}
 
+		if header.Name == metadataName && !opts.IgnoreExpiry {
+			if validityErr := checkValidity(tarReader); validityErr != nil {
+				return &types.ArchiveError{
+					Operation: "unpack",
+					Path:      opts.ArchivePath,
+					Err:       validityErr,
+				}
+			}
+			continue
+		}
+
 		if extractErr := s.extractEntry(tarReader, header, opts); extractErr != nil {
 			return &types.ArchiveError{
 				Operation: "unpack",
@@ -281,6 +319,38 @@ func (s *Service) Unpack(opts types.UnpackOptions) error {
 	return nil
 }
 
+// Rekey re-encrypts an archive under a new password, replacing the file
+// atomically. The contents are not changed.
+func (s *Service) Rekey(archivePath, oldPassword, newPassword string) error {
+	tarData, err := s.decryptArchive(archivePath, oldPassword)
+	if err != nil {
+		return &types.ArchiveError{
+			Operation: "rekey",
+			Path:      archivePath,
+			Err:       err,
+		}
+	}
+
+	encryptedData, err := s.crypto.Encrypt(tarData, newPassword)
+	if err != nil {
+		return &types.ArchiveError{
+			Operation: "rekey",
+			Path:      archivePath,
+			Err:       fmt.Errorf("failed to encrypt data: %w", err),
+		}
+	}
+
+	if writeErr := utils.WriteFileAtomic(archivePath, encryptedData, 0o600); writeErr != nil {
+		return &types.ArchiveError{
+			Operation: "rekey",
+			Path:      archivePath,
+			Err:       fmt.Errorf("failed to write encrypted file: %w", writeErr),
+		}
+	}
+
+	return nil
+}
+
 // List returns the contents of an archive without extracting
 func (s *Service) List(archivePath, password string) (*types.Archive, error) {
 	// Read encrypted file
@@ -303,43 +373,76 @@ func (s *Service) List(archivePath, password string) (*types.Archive, error) {
 		}
 	}
 
-	// Create tar reader
-	tarReader := tar.NewReader(strings.NewReader(string(tarData)))
-
-	// Read metadata (should be first entry)
-	header, err := tarReader.Next()
+	// Metadata is the first entry
+	archive, err := readMetadata(tar.NewReader(strings.NewReader(string(tarData))))
 	if err != nil {
 		return nil, &types.ArchiveError{
 			Operation: "list",
 			Path:      archivePath,
-			Err:       fmt.Errorf("failed to read metadata: %w", err),
+			Err:       err,
 		}
 	}
 
-	if header.Name != "metadata.json" {
-		return nil, &types.ArchiveError{
-			Operation: "list",
-			Path:      archivePath,
-			Err:       fmt.Errorf("invalid archive format: missing metadata"),
+	return archive, nil
+}
+
+// Read decrypts an archive and returns its metadata and the content of each
+// file entry by relative path, without writing anything to disk. Preserved
+// links have no content; their target is in the metadata.
+func (s *Service) Read(archivePath, password string) (*types.Archive, map[string][]byte, error) {
+	tarData, err := s.decryptArchive(archivePath, password)
+	if err != nil {
+		return nil, nil, &types.ArchiveError{Operation: "read", Path: archivePath, Err: err}
+	}
+
+	tarReader := tar.NewReader(bytes.NewReader(tarData))
+	archive, err := readMetadata(tarReader)
+	if err != nil {
+		return nil, nil, &types.ArchiveError{Operation: "read", Path: archivePath, Err: err}
+	}
+	stored, err := readEntries(tarReader)
+	if err != nil {
+		return nil, nil, &types.ArchiveError{Operation: "read", Path: archivePath, Err: err}
+	}
+
+	contents := make(map[string][]byte, len(stored))
+	for name, entry := range stored {
+		if entry.header.Typeflag != tar.TypeSymlink {
+			contents[name] = entry.content
 		}
 	}
+	return archive, contents, nil
+}
+
+// checkValidity decodes the metadata entry and refuses an archive outside
+// its validity period
+func checkValidity(metadata io.Reader) error {
+	var archive types.Archive
+	if err := json.NewDecoder(metadata).Decode(&archive); err != nil {
+		return fmt.Errorf("failed to unmarshal metadata: %w", err)
+	}
+	return archive.CheckValidity(time.Now())
+}
+
+// readMetadata reads the metadata.json entry that starts every archive
+func readMetadata(tarReader *tar.Reader) (*types.Archive, error) {
+	header, err := tarReader.Next()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
+	}
+
+	if header.Name != metadataName {
+		return nil, fmt.Errorf("invalid archive format: missing metadata")
+	}
 
 	metadataBytes, err := io.ReadAll(tarReader)
 	if err != nil {
-		return nil, &types.ArchiveError{
-			Operation: "list",
-			Path:      archivePath,
-			Err:       fmt.Errorf("failed to read metadata: %w", err),
-		}
+		return nil, fmt.Errorf("failed to read metadata: %w", err)
 	}
 
 	var archive types.Archive
 	if err := json.Unmarshal(metadataBytes, &archive); err != nil {
-		return nil, &types.ArchiveError{
-			Operation: "list",
-			Path:      archivePath,
-			Err:       fmt.Errorf("failed to unmarshal metadata: %w", err),
-		}
+		return nil, fmt.Errorf("failed to unmarshal metadata: %w", err)
 	}
 
 	return &archive, nil
@@ -379,7 +482,7 @@ func (s *Service) writeMetadata(tarWriter *tar.Writer, archive *types.Archive) e
 	}
 
 	header := &tar.Header{
-		Name: "metadata.json",
+		Name: metadataName,
 		Mode: 0o600,
 		Size: int64(len(metadataJSON)),
 	}
@@ -397,6 +500,10 @@ func (s *Service) writeMetadata(tarWriter *tar.Writer, archive *types.Archive) e
 
 // writeFileToTar writes a file to the tar archive
 func (s *Service) writeFileToTar(tarWriter *tar.Writer, file *types.EnvFile) error {
+	if file.LinkTarget != "" {
+		return writeLinkToTar(tarWriter, file)
+	}
+
 	fileInfo, err := os.Stat(file.Path)
 	if err != nil {
 		return fmt.Errorf("failed to stat file %s: %w", file.Path, err)
@@ -426,8 +533,78 @@ func (s *Service) writeFileToTar(tarWriter *tar.Writer, file *types.EnvFile) err
 	return nil
 }
 
+// writeLinkToTar writes a preserved symlink entry to the tar archive
+func writeLinkToTar(tarWriter *tar.Writer, file *types.EnvFile) error {
+	header := &tar.Header{
+		Typeflag: tar.TypeSymlink,
+		Name:     file.RelativePath,
+		Linkname: file.LinkTarget,
+		Mode:     0o777,
+		ModTime:  file.ModTime,
+	}
+
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write link header for %s: %w", file.Path, err)
+	}
+	return nil
+}
+
+// safeLinkTarget validates that a symlink entry resolves inside baseDir,
+// applying the same containment rules as safePath (pure function)
+func safeLinkTarget(name, linkname, baseDir string) error {
+	if linkname == "" || filepath.IsAbs(linkname) {
+		return fmt.Errorf("unsafe link target detected: %s -> %s", name, linkname)
+	}
+	resolved := filepath.Join(filepath.Dir(name), linkname)
+	if _, err := safePath(resolved, baseDir); err != nil {
+		return fmt.Errorf("unsafe link target detected: %s -> %s", name, linkname)
+	}
+	return nil
+}
+
+// parentLink returns the first parent directory of the entry name that
+// isLink reports as a symlink, or "" when there is none
+func parentLink(name string, isLink func(dir string) bool) string {
+	dir := ""
+	for _, part := range strings.Split(path.Dir(filepath.ToSlash(name)), "/") {
+		if part == "." || part == "" {
+			continue
+		}
+		dir = path.Join(dir, part)
+		if isLink(dir) {
+			return dir
+		}
+	}
+	return ""
+}
+
+// removeExistingLink removes a symlink at path so that extraction never
+// writes through a link that was already on disk
+func removeExistingLink(path string) error {
+	info, err := os.Lstat(path)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return nil
+	}
+	return os.Remove(path)
+}
+
+// extractLink recreates a preserved symlink on the filesystem
+func (s *Service) extractLink(targetPath string, header *tar.Header) error {
+	if err := removeExistingLink(targetPath); err != nil {
+		return fmt.Errorf("failed to replace link %s: %w", targetPath, err)
+	}
+	if err := os.Symlink(header.Linkname, targetPath); err != nil {
+		return fmt.Errorf("failed to create link %s: %w", targetPath, err)
+	}
+	return nil
+}
+
 // extractFile extracts a single file from tar to the filesystem
 func (s *Service) extractFile(tarReader *tar.Reader, targetPath string, header *tar.Header) error {
+	if err := removeExistingLink(targetPath); err != nil {
+		return fmt.Errorf("failed to replace link %s: %w", targetPath, err)
+	}
+
 	file, err := os.Create(targetPath)
 	if err != nil {
 		return fmt.Errorf("failed to create file %s: %w", targetPath, err)
@@ -3,6 +3,8 @@ package archive
 import (
 	"archive/tar"
 	"bytes"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
@@ -295,6 +297,92 @@ func TestService_List(t *testing.T) {
 	}
 }
 
+func TestService_Rekey(t *testing.T) {
+	cryptoService := crypto.NewService()
+	service := NewService(cryptoService)
+
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	testContent := []byte("TEST_VAR=test_value")
+	testFilePath := filepath.Join(tmpDir, ".env")
+	if err := os.WriteFile(testFilePath, testContent, 0o600); err != nil {
+		t.Fatalf("Failed to create test file: %v", err)
+	}
+
+	archivePath := filepath.Join(tmpDir, "test.enc")
+	packOpts := types.PackOptions{
+		Files: []types.EnvFile{
+			{Path: testFilePath, RelativePath: ".env", Size: int64(len(testContent)), ModTime: time.Now()},
+		},
+		OutputPath: archivePath,
+		Password:   "old-password-123",
+	}
+	if err := service.Pack(packOpts); err != nil {
+		t.Fatalf("Failed to pack test archive: %v", err)
+	}
+
+	if err := service.Rekey(archivePath, "wrong-password", "new-password-456"); err == nil {
+		t.Fatal("Rekey() with the wrong password should fail")
+	}
+
+	if err := service.Rekey(archivePath, "old-password-123", "new-password-456"); err != nil {
+		t.Fatalf("Rekey() error = %v", err)
+	}
+
+	if _, err := service.List(archivePath, "old-password-123"); err == nil {
+		t.Error("List() with the old password should fail after Rekey()")
+	}
+	archive, err := service.List(archivePath, "new-password-456")
+	if err != nil {
+		t.Fatalf("List() with the new password error = %v", err)
+	}
+	if len(archive.Files) != 1 || archive.Files[0].RelativePath != ".env" {
+		t.Errorf("List() files = %+v, want the original .env", archive.Files)
+	}
+}
+
+func TestService_Read(t *testing.T) {
+	service := NewService(crypto.NewService())
+
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	const password = "testpassword123"
+	archivePath := filepath.Join(tmpDir, "read.enc")
+	metadata := &types.Archive{
+		Files: []types.EnvFile{
+			{RelativePath: ".env", Size: 3, Checksum: checksumOf("A=1")},
+			{RelativePath: ".env.local", LinkTarget: ".env", Checksum: checksumOf(".env")},
+		},
+		TotalSize: 3,
+	}
+	writeVerifyArchive(t, archivePath, password, metadata, []tarEntry{
+		{name: ".env", content: "A=1"}, {name: ".env.local", linkname: ".env"},
+	})
+
+	if _, _, readErr := service.Read(archivePath, "wrong-password"); readErr == nil {
+		t.Error("Read() with the wrong password should fail")
+	}
+
+	archive, contents, err := service.Read(archivePath, password)
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if len(archive.Files) != 2 {
+		t.Errorf("Read() metadata files = %d, want 2", len(archive.Files))
+	}
+	if len(contents) != 1 || string(contents[".env"]) != "A=1" {
+		t.Errorf("Read() contents = %q, want only .env", contents)
+	}
+}
+
 func TestService_GetAvailableArchives(t *testing.T) {
 	cryptoService := crypto.NewService()
 	service := NewService(cryptoService)
@@ -705,6 +793,238 @@ func TestService_Unpack_OverwriteAndBackup(t *testing.T) {
 	}
 }
 
+func TestService_PackUnpack_PreservedLink(t *testing.T) {
+	cryptoService := crypto.NewService()
+	service := NewService(cryptoService)
+
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	realPath := filepath.Join(tmpDir, ".env.shared")
+	if writeErr := os.WriteFile(realPath, []byte("SHARED=1"), 0o600); writeErr != nil {
+		t.Fatalf("Failed to create test file: %v", writeErr)
+	}
+
+	archivePath := filepath.Join(tmpDir, "links.enc")
+	password := "testpassword123"
+	err = service.Pack(types.PackOptions{
+		Files: []types.EnvFile{
+			{Path: realPath, RelativePath: ".env.shared", Size: 8, ModTime: time.Now()},
+			{Path: filepath.Join(tmpDir, ".env"), RelativePath: ".env", ModTime: time.Now(), LinkTarget: ".env.shared"},
+		},
+		OutputPath: archivePath,
+		Password:   password,
+	})
+	if err != nil {
+		t.Fatalf("Pack() error = %v", err)
+	}
+
+	targetDir := filepath.Join(tmpDir, "extracted")
+	if mkdirErr := os.MkdirAll(targetDir, 0o700); mkdirErr != nil {
+		t.Fatalf("Failed to create target dir: %v", mkdirErr)
+	}
+
+	err = service.Unpack(types.UnpackOptions{ArchivePath: archivePath, Password: password, TargetDir: targetDir})
+	if err != nil {
+		t.Fatalf("Unpack() error = %v", err)
+	}
+
+	target, err := os.Readlink(filepath.Join(targetDir, ".env"))
+	if err != nil {
+		t.Fatalf("Expected .env to be extracted as a symlink: %v", err)
+	}
+	if target != ".env.shared" {
+		t.Errorf("link target = %q, want %q", target, ".env.shared")
+	}
+}
+
+func TestService_Unpack_Expiry(t *testing.T) {
+	cryptoService := crypto.NewService()
+	service := NewService(cryptoService)
+
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	envPath := filepath.Join(tmpDir, ".env")
+	if writeErr := os.WriteFile(envPath, []byte("TOKEN=temp"), 0o600); writeErr != nil {
+		t.Fatalf("Failed to create test file: %v", writeErr)
+	}
+
+	past, future := time.Now().Add(-time.Hour), time.Now().Add(time.Hour)
+	tests := []struct {
+		name         string
+		notBefore    *time.Time
+		expiresAt    *time.Time
+		ignoreExpiry bool
+		wantErr      bool
+	}{
+		{name: "No validity period", wantErr: false},
+		{name: "Not expired yet", notBefore: &past, expiresAt: &future, wantErr: false},
+		{name: "Expired", expiresAt: &past, wantErr: true},
+		{name: "Not valid yet", notBefore: &future, wantErr: true},
+		{name: "Expired but ignored", expiresAt: &past, ignoreExpiry: true, wantErr: false},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := filepath.Join(tmpDir, fmt.Sprintf("expiry-%d.enc", i))
+			err := service.Pack(types.PackOptions{
+				Files:      []types.EnvFile{{Path: envPath, RelativePath: ".env", Size: 10, ModTime: time.Now()}},
+				OutputPath: archivePath,
+				Password:   "testpassword123",
+				NotBefore:  tt.notBefore,
+				ExpiresAt:  tt.expiresAt,
+			})
+			if err != nil {
+				t.Fatalf("Pack() error = %v", err)
+			}
+
+			err = service.Unpack(types.UnpackOptions{
+				ArchivePath:  archivePath,
+				Password:     "testpassword123",
+				TargetDir:    filepath.Join(tmpDir, fmt.Sprintf("out-%d", i)),
+				IgnoreExpiry: tt.ignoreExpiry,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unpack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr && !errors.Is(err, types.ErrArchiveExpired) {
+				t.Errorf("Unpack() error = %v, want ErrArchiveExpired", err)
+			}
+		})
+	}
+}
+
+func TestService_Unpack_UnsafeLinkPrevention(t *testing.T) {
+	cryptoService := crypto.NewService()
+	service := NewService(cryptoService)
+
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	tests := []struct {
+		name     string
+		linkname string
+	}{
+		{name: "Absolute target", linkname: "/etc/passwd"},
+		{name: "Relative escape", linkname: "../../outside/.env"},
+		{name: "Empty target", linkname: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			header := &tar.Header{
+				Typeflag: tar.TypeSymlink,
+				Name:     "config/.env",
+				Linkname: tt.linkname,
+				Mode:     0o777,
+			}
+			if headerErr := tw.WriteHeader(header); headerErr != nil {
+				t.Fatalf("Failed to write tar header: %v", headerErr)
+			}
+			_ = tw.Close()
+
+			password := "testpassword123"
+			encryptedData, encErr := cryptoService.Encrypt(buf.Bytes(), password)
+			if encErr != nil {
+				t.Fatalf("Failed to encrypt: %v", encErr)
+			}
+
+			archivePath := filepath.Join(tmpDir, "malicious.enc")
+			if writeErr := os.WriteFile(archivePath, encryptedData, 0o600); writeErr != nil {
+				t.Fatalf("Failed to write archive: %v", writeErr)
+			}
+
+			targetDir := filepath.Join(tmpDir, "extracted-"+strings.ReplaceAll(tt.name, " ", "-"))
+			unpackErr := service.Unpack(types.UnpackOptions{
+				ArchivePath: archivePath,
+				Password:    password,
+				TargetDir:   targetDir,
+				Overwrite:   true,
+			})
+			if unpackErr == nil {
+				t.Fatal("Unpack should fail for a link escaping the target directory")
+			}
+			if !strings.Contains(unpackErr.Error(), "unsafe link target") {
+				t.Errorf("Expected unsafe link error, got: %v", unpackErr)
+			}
+			if _, statErr := os.Lstat(filepath.Join(targetDir, "config", ".env")); !os.IsNotExist(statErr) {
+				t.Error("Unsafe link should not be created")
+			}
+		})
+	}
+}
+
+func TestService_Unpack_LinkChainPrevention(t *testing.T) {
+	cryptoService := crypto.NewService()
+	service := NewService(cryptoService)
+
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Each link on its own stays inside the target, but writing through
+	// both of them lands two levels above it
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	headers := []*tar.Header{
+		{Typeflag: tar.TypeSymlink, Name: "a/b/l1", Linkname: "..", Mode: 0o777},
+		{Typeflag: tar.TypeSymlink, Name: "a/b/l1/l2", Linkname: "../..", Mode: 0o777},
+		{Typeflag: tar.TypeReg, Name: "a/b/l1/l2/escaped.env", Size: 6, Mode: 0o600},
+	}
+	for _, header := range headers {
+		if headerErr := tw.WriteHeader(header); headerErr != nil {
+			t.Fatalf("Failed to write tar header: %v", headerErr)
+		}
+		if header.Typeflag == tar.TypeReg {
+			_, _ = tw.Write([]byte("KEY=1\n"))
+		}
+	}
+	_ = tw.Close()
+
+	password := "testpassword123"
+	encryptedData, err := cryptoService.Encrypt(buf.Bytes(), password)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	archivePath := filepath.Join(tmpDir, "malicious.enc")
+	if err := os.WriteFile(archivePath, encryptedData, 0o600); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+
+	targetDir := filepath.Join(tmpDir, "out", "extracted")
+	unpackErr := service.Unpack(types.UnpackOptions{
+		ArchivePath: archivePath,
+		Password:    password,
+		TargetDir:   targetDir,
+		Overwrite:   true,
+	})
+	if unpackErr == nil {
+		t.Fatal("Unpack should fail for an entry written through a link")
+	}
+	if !strings.Contains(unpackErr.Error(), "passes through symlink") {
+		t.Errorf("Expected symlink path error, got: %v", unpackErr)
+	}
+	for _, dir := range []string{tmpDir, filepath.Join(tmpDir, "out"), targetDir} {
+		if _, statErr := os.Stat(filepath.Join(dir, "escaped.env")); !os.IsNotExist(statErr) {
+			t.Errorf("escaped.env was written to %s", dir)
+		}
+	}
+}
+
 func BenchmarkPack(b *testing.B) {
 	cryptoService := crypto.NewService()
 	service := NewService(cryptoService)
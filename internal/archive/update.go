@@ -0,0 +1,219 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"time"
+
+	"goingenv/pkg/types"
+	"goingenv/pkg/utils"
+)
+
+// storedEntry is an entry read back from an existing archive
+type storedEntry struct {
+	header  *tar.Header
+	content []byte
+}
+
+// Update rewrites an archive with entries added, replaced or removed. The
+// description, profile and creation time are kept; the file list and total
+// size are rebuilt from the resulting entries, and the archive is replaced
+// atomically. Kept entries are checked against their recorded checksums so
+// a damaged archive is never rewritten as if it were intact.
+func (s *Service) Update(opts types.UpdateOptions) (*types.Archive, error) {
+	archive, err := s.update(opts)
+	if err != nil {
+		return nil, &types.ArchiveError{
+			Operation: "update",
+			Path:      opts.ArchivePath,
+			Err:       err,
+		}
+	}
+	return archive, nil
+}
+
+// update does the work of Update
+func (s *Service) update(opts types.UpdateOptions) (*types.Archive, error) {
+	tarData, err := s.decryptArchive(opts.ArchivePath, opts.Password)
+	if err != nil {
+		return nil, err
+	}
+
+	tarReader := tar.NewReader(bytes.NewReader(tarData))
+	archive, err := readMetadata(tarReader)
+	if err != nil {
+		return nil, err
+	}
+	stored, err := readEntries(tarReader)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := applyUpdate(archive.Files, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	put := make(map[string]bool, len(opts.Put))
+	for _, file := range opts.Put {
+		put[file.RelativePath] = true
+	}
+
+	var buf bytes.Buffer
+	tarWriter := tar.NewWriter(&buf)
+
+	now := time.Now()
+	archive.Files = files
+	archive.TotalSize = 0
+	for _, file := range files {
+		archive.TotalSize += file.Size
+	}
+	archive.UpdatedAt = &now
+
+	if metaErr := s.writeMetadata(tarWriter, archive); metaErr != nil {
+		return nil, fmt.Errorf("failed to write metadata: %w", metaErr)
+	}
+	for i := range files {
+		if content, ok := opts.Contents[files[i].RelativePath]; ok && put[files[i].RelativePath] {
+			if writeErr := writeContentToTar(tarWriter, &files[i], content); writeErr != nil {
+				return nil, fmt.Errorf("failed to write file to archive: %w", writeErr)
+			}
+			continue
+		}
+		if put[files[i].RelativePath] {
+			if writeErr := s.writeFileToTar(tarWriter, &files[i]); writeErr != nil {
+				return nil, fmt.Errorf("failed to write file to archive: %w", writeErr)
+			}
+			continue
+		}
+		if copyErr := copyStoredEntry(tarWriter, &files[i], stored); copyErr != nil {
+			return nil, copyErr
+		}
+	}
+	if closeErr := tarWriter.Close(); closeErr != nil {
+		return nil, fmt.Errorf("failed to close tar writer: %w", closeErr)
+	}
+
+	encryptedData, err := s.crypto.Encrypt(buf.Bytes(), opts.Password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt data: %w", err)
+	}
+	if writeErr := utils.WriteFileAtomic(opts.ArchivePath, encryptedData, 0o600); writeErr != nil {
+		return nil, fmt.Errorf("failed to write encrypted file: %w", writeErr)
+	}
+
+	return archive, nil
+}
+
+// writeContentToTar writes a file entry from content held in memory
+func writeContentToTar(tarWriter *tar.Writer, file *types.EnvFile, content []byte) error {
+	header := &tar.Header{
+		Name:    file.RelativePath,
+		Mode:    0o600,
+		Size:    int64(len(content)),
+		ModTime: file.ModTime,
+	}
+	if err := tarWriter.WriteHeader(header); err != nil {
+		return fmt.Errorf("failed to write header for %s: %w", file.RelativePath, err)
+	}
+	if _, err := tarWriter.Write(content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file.RelativePath, err)
+	}
+	return nil
+}
+
+// readEntries reads the remaining entries of an archive by name
+func readEntries(tarReader *tar.Reader) (map[string]storedEntry, error) {
+	stored := make(map[string]storedEntry)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return stored, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar header: %w", err)
+		}
+		if _, dup := stored[header.Name]; dup {
+			return nil, fmt.Errorf("%w: %s: duplicate entry", types.ErrIntegrity, header.Name)
+		}
+
+		content, err := io.ReadAll(tarReader)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", header.Name, err)
+		}
+		stored[header.Name] = storedEntry{header: header, content: content}
+	}
+}
+
+// applyUpdate returns the archive's file list with opts applied: replaced
+// files keep their position, added files go last
+func applyUpdate(files []types.EnvFile, opts types.UpdateOptions) ([]types.EnvFile, error) {
+	remove := make(map[string]bool, len(opts.Remove))
+	for _, name := range opts.Remove {
+		remove[name] = true
+	}
+	put := make(map[string]int, len(opts.Put))
+	for i, file := range opts.Put {
+		if remove[file.RelativePath] {
+			return nil, fmt.Errorf("%s is both added and removed", file.RelativePath)
+		}
+		put[file.RelativePath] = i
+	}
+
+	result := make([]types.EnvFile, 0, len(files)+len(opts.Put))
+	for _, file := range files {
+		if remove[file.RelativePath] {
+			delete(remove, file.RelativePath)
+			continue
+		}
+		if i, ok := put[file.RelativePath]; ok {
+			file = opts.Put[i]
+			delete(put, file.RelativePath)
+		}
+		result = append(result, file)
+	}
+	for _, name := range opts.Remove {
+		if remove[name] {
+			return nil, fmt.Errorf("%s is not in the archive", name)
+		}
+	}
+	for _, file := range opts.Put {
+		if i, added := put[file.RelativePath]; added {
+			result = append(result, opts.Put[i])
+			delete(put, file.RelativePath)
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no files would be left in the archive")
+	}
+	return result, nil
+}
+
+// copyStoredEntry writes a kept entry unchanged, after checking it still
+// matches its metadata
+func copyStoredEntry(tarWriter *tar.Writer, file *types.EnvFile, stored map[string]storedEntry) error {
+	entry, ok := stored[file.RelativePath]
+	if !ok {
+		return fmt.Errorf("%w: %s: listed in metadata but missing from archive", types.ErrIntegrity, file.RelativePath)
+	}
+
+	checksum := fmt.Sprintf("%x", sha256.Sum256(entry.content))
+	if entry.header.Typeflag == tar.TypeSymlink {
+		checksum = fmt.Sprintf("%x", sha256.Sum256([]byte(entry.header.Linkname)))
+	}
+	if checksum != file.Checksum {
+		return fmt.Errorf("%w: %s: checksum mismatch", types.ErrIntegrity, file.RelativePath)
+	}
+
+	if err := tarWriter.WriteHeader(entry.header); err != nil {
+		return fmt.Errorf("failed to write header for %s: %w", file.RelativePath, err)
+	}
+	if _, err := tarWriter.Write(entry.content); err != nil {
+		return fmt.Errorf("failed to write %s: %w", file.RelativePath, err)
+	}
+	return nil
+}
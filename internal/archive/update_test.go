@@ -0,0 +1,190 @@
+package archive
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"goingenv/internal/crypto"
+	"goingenv/pkg/types"
+)
+
+func TestService_Update(t *testing.T) {
+	service := NewService(crypto.NewService())
+
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	const password = "testpassword123"
+	// Packed files live in their own directory so the replacement files
+	// below keep their content
+	packedDir := filepath.Join(tmpDir, "packed")
+	if mkdirErr := os.Mkdir(packedDir, 0o700); mkdirErr != nil {
+		t.Fatalf("Failed to create dir: %v", mkdirErr)
+	}
+	envFileIn := func(dir, name, content string) types.EnvFile {
+		path := filepath.Join(dir, name)
+		if writeErr := os.WriteFile(path, []byte(content), 0o600); writeErr != nil {
+			t.Fatalf("Failed to write %s: %v", name, writeErr)
+		}
+		return types.EnvFile{Path: path, RelativePath: name, Size: int64(len(content)), ModTime: time.Now(), Checksum: checksumOf(content)}
+	}
+	envFile := func(name, content string) types.EnvFile {
+		return envFileIn(tmpDir, name, content)
+	}
+	pack := func() string {
+		archivePath := filepath.Join(tmpDir, "test.enc")
+		err := service.Pack(types.PackOptions{
+			Files:       []types.EnvFile{envFileIn(packedDir, ".env", "A=1"), envFileIn(packedDir, ".env.test", "T=1")},
+			OutputPath:  archivePath,
+			Password:    password,
+			Description: "original",
+		})
+		if err != nil {
+			t.Fatalf("Pack() error = %v", err)
+		}
+		return archivePath
+	}
+
+	tests := []struct {
+		name      string
+		put       []types.EnvFile
+		remove    []string
+		contents  map[string][]byte
+		wantFiles []string
+		wantErr   bool
+	}{
+		{
+			name:      "Add a file",
+			put:       []types.EnvFile{envFile(".env.local", "L=1")},
+			wantFiles: []string{".env", ".env.test", ".env.local"},
+		},
+		{
+			name:      "Replace a file in place",
+			put:       []types.EnvFile{envFile(".env", "A=2\nB=3")},
+			wantFiles: []string{".env", ".env.test"},
+		},
+		{
+			name:      "Replace a file from memory",
+			put:       []types.EnvFile{{Path: filepath.Join(tmpDir, "missing"), RelativePath: ".env", Size: 3, Checksum: checksumOf("A=9")}},
+			contents:  map[string][]byte{".env": []byte("A=9")},
+			wantFiles: []string{".env", ".env.test"},
+		},
+		{
+			name:      "Remove a file",
+			remove:    []string{".env.test"},
+			wantFiles: []string{".env"},
+		},
+		{
+			name:    "Remove a missing file",
+			remove:  []string{".env.missing"},
+			wantErr: true,
+		},
+		{
+			name:    "Remove every file",
+			remove:  []string{".env", ".env.test"},
+			wantErr: true,
+		},
+		{
+			name:    "Add and remove the same file",
+			put:     []types.EnvFile{envFile(".env.test", "T=2")},
+			remove:  []string{".env.test"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := pack()
+			before, readErr := os.ReadFile(archivePath)
+			if readErr != nil {
+				t.Fatalf("Failed to read archive: %v", readErr)
+			}
+
+			archive, err := service.Update(types.UpdateOptions{
+				ArchivePath: archivePath,
+				Password:    password,
+				Put:         tt.put,
+				Remove:      tt.remove,
+				Contents:    tt.contents,
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Update() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				after, _ := os.ReadFile(archivePath) //nolint:errcheck // compared below
+				if string(after) != string(before) {
+					t.Error("Update() changed the archive despite failing")
+				}
+				return
+			}
+
+			var names []string
+			for _, f := range archive.Files {
+				names = append(names, f.RelativePath)
+			}
+			if len(names) != len(tt.wantFiles) {
+				t.Fatalf("Update() files = %v, want %v", names, tt.wantFiles)
+			}
+			for i := range names {
+				if names[i] != tt.wantFiles[i] {
+					t.Errorf("Update() files = %v, want %v", names, tt.wantFiles)
+					break
+				}
+			}
+			if archive.Description != "original" || archive.UpdatedAt == nil {
+				t.Errorf("Update() description = %q, updated at %v; want the original description and a time",
+					archive.Description, archive.UpdatedAt)
+			}
+
+			result, err := service.Verify(archivePath, password)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if !result.OK() {
+				t.Errorf("Verify() after Update() problems = %v", result.Problems)
+			}
+		})
+	}
+}
+
+func TestService_Update_DamagedArchive(t *testing.T) {
+	service := NewService(crypto.NewService())
+
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	const password = "testpassword123"
+	archivePath := filepath.Join(tmpDir, "damaged.enc")
+	metadata := &types.Archive{
+		Files:     []types.EnvFile{{RelativePath: ".env", Size: 3, Checksum: checksumOf("A=1")}},
+		TotalSize: 3,
+	}
+	writeVerifyArchive(t, archivePath, password, metadata, []tarEntry{{name: ".env", content: "A=2"}})
+
+	newFile := filepath.Join(tmpDir, ".env.local")
+	if writeErr := os.WriteFile(newFile, []byte("L=1"), 0o600); writeErr != nil {
+		t.Fatalf("Failed to write file: %v", writeErr)
+	}
+
+	_, err = service.Update(types.UpdateOptions{
+		ArchivePath: archivePath,
+		Password:    password,
+		Put:         []types.EnvFile{{Path: newFile, RelativePath: ".env.local", Size: 3, Checksum: checksumOf("L=1")}},
+	})
+	if !errors.Is(err, types.ErrIntegrity) {
+		t.Errorf("Update() of a damaged archive error = %v, want ErrIntegrity", err)
+	}
+
+	if _, err := service.Update(types.UpdateOptions{ArchivePath: archivePath, Password: "wrong-password"}); err == nil {
+		t.Error("Update() with the wrong password should fail")
+	}
+}
@@ -0,0 +1,161 @@
+package archive
+
+import (
+	"archive/tar"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"goingenv/pkg/types"
+)
+
+// Verify decrypts an archive and checks every entry against its metadata:
+// each file's SHA-256 and size must match the recorded values, and the
+// entry set must match the metadata exactly with no duplicate or unexpected
+// entries. Problems found are reported in the result; an error is returned
+// only when the archive cannot be decrypted.
+func (s *Service) Verify(archivePath, password string) (*types.VerifyResult, error) {
+	tarData, err := s.decryptArchive(archivePath, password)
+	if err != nil {
+		return nil, &types.ArchiveError{
+			Operation: "verify",
+			Path:      archivePath,
+			Err:       err,
+		}
+	}
+
+	result := &types.VerifyResult{}
+	tarReader := tar.NewReader(strings.NewReader(string(tarData)))
+
+	archive, err := readMetadata(tarReader)
+	if err != nil {
+		result.Problems = append(result.Problems, err.Error())
+		return result, nil
+	}
+	result.Archive = archive
+
+	expected := make(map[string]*types.EnvFile, len(archive.Files))
+	var totalSize int64
+	for i := range archive.Files {
+		file := &archive.Files[i]
+		if _, dup := expected[file.RelativePath]; dup {
+			result.Problems = append(result.Problems, fmt.Sprintf("%s: listed more than once in metadata", file.RelativePath))
+			continue
+		}
+		expected[file.RelativePath] = file
+		totalSize += file.Size
+	}
+	if totalSize != archive.TotalSize {
+		result.Problems = append(result.Problems, fmt.Sprintf("metadata total size %d does not match the file sizes (%d)", archive.TotalSize, totalSize))
+	}
+
+	seen := make(map[string]bool, len(expected))
+	for {
+		header, nextErr := tarReader.Next()
+		if nextErr == io.EOF {
+			break
+		}
+		if nextErr != nil {
+			result.Problems = append(result.Problems, fmt.Sprintf("corrupt tar stream: %v", nextErr))
+			break
+		}
+
+		result.Entries++
+		if problem := checkEntry(tarReader, header, expected, seen); problem != "" {
+			result.Problems = append(result.Problems, problem)
+		}
+	}
+
+	var missing []string
+	for name := range expected {
+		if !seen[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	for _, name := range missing {
+		result.Problems = append(result.Problems, fmt.Sprintf("%s: listed in metadata but missing from archive", name))
+	}
+
+	return result, nil
+}
+
+// checkEntry verifies one tar entry, returning a description of the
+// problem or "" when the entry matches its metadata
+func checkEntry(tarReader *tar.Reader, header *tar.Header, expected map[string]*types.EnvFile, seen map[string]bool) string {
+	name := header.Name
+	if _, err := safePath(name, "."); err != nil {
+		return fmt.Sprintf("%s: unsafe entry path", name)
+	}
+	if name == metadataName {
+		return fmt.Sprintf("%s: duplicate metadata entry", name)
+	}
+
+	isLink := func(dir string) bool {
+		f, listed := expected[dir]
+		return listed && f.LinkTarget != ""
+	}
+	if dir := parentLink(name, isLink); dir != "" {
+		return fmt.Sprintf("%s: path passes through link %s", name, dir)
+	}
+
+	file, ok := expected[name]
+	switch {
+	case !ok:
+		return fmt.Sprintf("%s: unexpected entry not listed in metadata", name)
+	case seen[name]:
+		return fmt.Sprintf("%s: duplicate entry", name)
+	}
+	seen[name] = true
+
+	if file.Checksum == "" {
+		return fmt.Sprintf("%s: no checksum recorded in metadata", name)
+	}
+
+	switch header.Typeflag {
+	case tar.TypeReg:
+		return checkFileEntry(tarReader, header, file)
+	case tar.TypeSymlink:
+		return checkLinkEntry(header, file)
+	default:
+		return fmt.Sprintf("%s: unexpected entry type %q", name, header.Typeflag)
+	}
+}
+
+// checkFileEntry compares a regular file entry's size and SHA-256
+// with the metadata
+func checkFileEntry(tarReader *tar.Reader, header *tar.Header, file *types.EnvFile) string {
+	if file.LinkTarget != "" {
+		return fmt.Sprintf("%s: stored as a file but recorded as a link", header.Name)
+	}
+
+	hash := sha256.New()
+	n, err := io.Copy(hash, tarReader)
+	if err != nil {
+		return fmt.Sprintf("%s: failed to read entry: %v", header.Name, err)
+	}
+	if n != file.Size {
+		return fmt.Sprintf("%s: size %d does not match metadata (%d)", header.Name, n, file.Size)
+	}
+	if sum := fmt.Sprintf("%x", hash.Sum(nil)); sum != file.Checksum {
+		return fmt.Sprintf("%s: checksum mismatch", header.Name)
+	}
+	return ""
+}
+
+// checkLinkEntry compares a preserved symlink entry's target with the
+// metadata; a link's checksum is the SHA-256 of its target
+func checkLinkEntry(header *tar.Header, file *types.EnvFile) string {
+	if err := safeLinkTarget(header.Name, header.Linkname, "."); err != nil {
+		return fmt.Sprintf("%s: link target %q leaves the archive", header.Name, header.Linkname)
+	}
+	if header.Linkname != file.LinkTarget {
+		return fmt.Sprintf("%s: link target %q does not match metadata (%q)", header.Name, header.Linkname, file.LinkTarget)
+	}
+	if sum := fmt.Sprintf("%x", sha256.Sum256([]byte(header.Linkname))); sum != file.Checksum {
+		return fmt.Sprintf("%s: checksum mismatch", header.Name)
+	}
+	return ""
+}
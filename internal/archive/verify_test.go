@@ -0,0 +1,246 @@
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"goingenv/internal/crypto"
+	"goingenv/pkg/types"
+)
+
+// tarEntry is a raw entry for building hand-crafted archives
+type tarEntry struct {
+	name     string
+	content  string
+	linkname string
+}
+
+// writeVerifyArchive encrypts a tar of metadata followed by entries
+func writeVerifyArchive(t *testing.T, path, password string, metadata *types.Archive, entries []tarEntry) {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+
+	metadataBytes, err := json.Marshal(metadata)
+	if err != nil {
+		t.Fatalf("Failed to marshal metadata: %v", err)
+	}
+	entries = append([]tarEntry{{name: metadataName, content: string(metadataBytes)}}, entries...)
+
+	for _, e := range entries {
+		header := &tar.Header{Name: e.name, Mode: 0o600, Size: int64(len(e.content))}
+		if e.linkname != "" {
+			header = &tar.Header{Typeflag: tar.TypeSymlink, Name: e.name, Linkname: e.linkname, Mode: 0o777}
+		}
+		if headerErr := tw.WriteHeader(header); headerErr != nil {
+			t.Fatalf("Failed to write tar header: %v", headerErr)
+		}
+		if _, writeErr := tw.Write([]byte(e.content)); writeErr != nil {
+			t.Fatalf("Failed to write tar content: %v", writeErr)
+		}
+	}
+	_ = tw.Close()
+
+	encryptedData, err := crypto.NewService().Encrypt(buf.Bytes(), password)
+	if err != nil {
+		t.Fatalf("Failed to encrypt: %v", err)
+	}
+	if writeErr := os.WriteFile(path, encryptedData, 0o600); writeErr != nil {
+		t.Fatalf("Failed to write archive: %v", writeErr)
+	}
+}
+
+func checksumOf(s string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))
+}
+
+func TestService_Verify(t *testing.T) {
+	service := NewService(crypto.NewService())
+
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	const password = "testpassword123"
+	env := types.EnvFile{RelativePath: ".env", Size: 5, Checksum: checksumOf("A=1\nB")}
+	link := types.EnvFile{RelativePath: ".env.local", LinkTarget: ".env", Checksum: checksumOf(".env")}
+	metadata := &types.Archive{Files: []types.EnvFile{env, link}, TotalSize: 5}
+
+	tests := []struct {
+		name     string
+		metadata *types.Archive
+		entries  []tarEntry
+		problem  string // substring of the expected problem; "" means intact
+	}{
+		{
+			name:     "Intact archive",
+			metadata: metadata,
+			entries:  []tarEntry{{name: ".env", content: "A=1\nB"}, {name: ".env.local", linkname: ".env"}},
+		},
+		{
+			name:     "Changed content",
+			metadata: metadata,
+			entries:  []tarEntry{{name: ".env", content: "A=2\nB"}, {name: ".env.local", linkname: ".env"}},
+			problem:  ".env: checksum mismatch",
+		},
+		{
+			name:     "Changed size",
+			metadata: metadata,
+			entries:  []tarEntry{{name: ".env", content: "A=1"}, {name: ".env.local", linkname: ".env"}},
+			problem:  "does not match metadata",
+		},
+		{
+			name:     "Changed link target",
+			metadata: metadata,
+			entries:  []tarEntry{{name: ".env", content: "A=1\nB"}, {name: ".env.local", linkname: ".env.prod"}},
+			problem:  "link target",
+		},
+		{
+			name:     "Missing entry",
+			metadata: metadata,
+			entries:  []tarEntry{{name: ".env", content: "A=1\nB"}},
+			problem:  ".env.local: listed in metadata but missing",
+		},
+		{
+			name:     "Unexpected entry",
+			metadata: metadata,
+			entries: []tarEntry{
+				{name: ".env", content: "A=1\nB"}, {name: ".env.local", linkname: ".env"}, {name: ".env.extra", content: "X"},
+			},
+			problem: ".env.extra: unexpected entry",
+		},
+		{
+			name:     "Duplicate entry",
+			metadata: metadata,
+			entries: []tarEntry{
+				{name: ".env", content: "A=1\nB"}, {name: ".env.local", linkname: ".env"}, {name: ".env", content: "EVIL"},
+			},
+			problem: ".env: duplicate entry",
+		},
+		{
+			name:     "Duplicate metadata",
+			metadata: metadata,
+			entries: []tarEntry{
+				{name: ".env", content: "A=1\nB"}, {name: ".env.local", linkname: ".env"}, {name: metadataName, content: "{}"},
+			},
+			problem: "duplicate metadata",
+		},
+		{
+			name:     "Unsafe entry path",
+			metadata: metadata,
+			entries: []tarEntry{
+				{name: ".env", content: "A=1\nB"}, {name: ".env.local", linkname: ".env"}, {name: "../.env", content: "X"},
+			},
+			problem: "unsafe entry path",
+		},
+		{
+			name: "Entry through link chain",
+			metadata: &types.Archive{Files: []types.EnvFile{
+				{RelativePath: "a/b/l1", LinkTarget: "..", Checksum: checksumOf("..")},
+				{RelativePath: "a/b/l1/l2", LinkTarget: "../..", Checksum: checksumOf("../..")},
+				{RelativePath: "a/b/l1/l2/escaped.env", Size: 1, Checksum: checksumOf("X")},
+			}, TotalSize: 1},
+			entries: []tarEntry{
+				{name: "a/b/l1", linkname: ".."}, {name: "a/b/l1/l2", linkname: "../.."}, {name: "a/b/l1/l2/escaped.env", content: "X"},
+			},
+			problem: "passes through link a/b/l1",
+		},
+		{
+			name:     "Escaping link target",
+			metadata: &types.Archive{Files: []types.EnvFile{{RelativePath: ".env", LinkTarget: "../.env", Checksum: checksumOf("../.env")}}},
+			entries:  []tarEntry{{name: ".env", linkname: "../.env"}},
+			problem:  "leaves the archive",
+		},
+		{
+			name:     "Missing checksum",
+			metadata: &types.Archive{Files: []types.EnvFile{{RelativePath: ".env", Size: 5}}, TotalSize: 5},
+			entries:  []tarEntry{{name: ".env", content: "A=1\nB"}},
+			problem:  "no checksum recorded",
+		},
+		{
+			name:     "Wrong total size",
+			metadata: &types.Archive{Files: []types.EnvFile{env}, TotalSize: 50},
+			entries:  []tarEntry{{name: ".env", content: "A=1\nB"}},
+			problem:  "total size",
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := filepath.Join(tmpDir, fmt.Sprintf("verify-%d.enc", i))
+			writeVerifyArchive(t, archivePath, password, tt.metadata, tt.entries)
+
+			result, verifyErr := service.Verify(archivePath, password)
+			if verifyErr != nil {
+				t.Fatalf("Verify() error = %v", verifyErr)
+			}
+
+			if tt.problem == "" {
+				if !result.OK() {
+					t.Errorf("Verify() problems = %v, want none", result.Problems)
+				}
+				if result.Entries != len(tt.entries) {
+					t.Errorf("Verify() entries = %d, want %d", result.Entries, len(tt.entries))
+				}
+				return
+			}
+
+			if result.OK() {
+				t.Fatalf("Verify() reported an intact archive, want problem %q", tt.problem)
+			}
+			if !strings.Contains(strings.Join(result.Problems, "\n"), tt.problem) {
+				t.Errorf("Verify() problems = %v, want one containing %q", result.Problems, tt.problem)
+			}
+		})
+	}
+}
+
+func TestService_Verify_PackedArchive(t *testing.T) {
+	service := NewService(crypto.NewService())
+
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	content := "DATABASE_URL=postgres://localhost/db"
+	envPath := filepath.Join(tmpDir, ".env")
+	if writeErr := os.WriteFile(envPath, []byte(content), 0o600); writeErr != nil {
+		t.Fatalf("Failed to create test file: %v", writeErr)
+	}
+
+	archivePath := filepath.Join(tmpDir, "packed.enc")
+	err = service.Pack(types.PackOptions{
+		Files: []types.EnvFile{
+			{Path: envPath, RelativePath: ".env", Size: int64(len(content)), Checksum: checksumOf(content)},
+		},
+		OutputPath: archivePath,
+		Password:   "testpassword123",
+	})
+	if err != nil {
+		t.Fatalf("Pack() error = %v", err)
+	}
+
+	result, err := service.Verify(archivePath, "testpassword123")
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if !result.OK() || result.Entries != 1 {
+		t.Errorf("Verify() = %d entries, problems %v, want 1 entry and none", result.Entries, result.Problems)
+	}
+
+	if _, err := service.Verify(archivePath, "wrongpassword"); err == nil {
+		t.Error("Verify() with the wrong password should fail")
+	}
+}
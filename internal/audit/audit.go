@@ -0,0 +1,279 @@
+// Package audit keeps a tamper-evident, append-only log of the operations
+// that read or write secrets. Each JSON line records who did what to which
+// archive, and carries the hash of the line before it, so editing or
+// deleting an entry breaks the chain. Entries name archives and files and
+// hash their contents as stored on disk; they never hold secret values.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"goingenv/pkg/types"
+	"goingenv/pkg/utils"
+)
+
+// FileName is the audit log's name in the .goingenv directory
+const FileName = "audit.log"
+
+// Operations recorded in the log
+const (
+	OpPack   = "pack"
+	OpUnpack = "unpack"
+	OpUpdate = "update" // add, rm, update and resolve
+	OpMerge  = "merge"  // the git merge driver
+	OpRekey  = "rekey"
+	OpPrune  = "prune"
+)
+
+// tailChunk is how much of the log is read at a time when looking for the
+// last entry
+const tailChunk = 64 * 1024
+
+// Entry is one line of the audit log
+type Entry struct {
+	Seq         int       `json:"seq"`
+	Time        time.Time `json:"time"`
+	Operation   string    `json:"op"`
+	Archive     string    `json:"archive"`
+	ArchiveHash string    `json:"archive_sha256,omitempty"` // of the encrypted archive file
+	Files       []string  `json:"files,omitempty"`          // relative paths, sorted
+	FilesHash   string    `json:"files_sha256,omitempty"`   // of the file list
+	User        string    `json:"user"`
+	Host        string    `json:"host"`
+	Project     string    `json:"project,omitempty"` // user log only: the project directory
+	Ref         string    `json:"ref,omitempty"`     // user log only: hash of the project log entry
+	Prev        string    `json:"prev"`
+	Hash        string    `json:"hash"`
+}
+
+// NewEntry describes an operation on an archive by the current user. The
+// archive file is hashed as it is now, so record removals before removing.
+func NewEntry(op, archivePath string, files []types.EnvFile) Entry {
+	e := Entry{
+		Time:      time.Now().UTC(),
+		Operation: op,
+		Archive:   archivePath,
+		User:      utils.CurrentUserName(),
+	}
+	e.Host, _ = os.Hostname() //nolint:errcheck // an unknown host is recorded as empty
+
+	if data, err := os.ReadFile(archivePath); err == nil {
+		sum := sha256.Sum256(data)
+		e.ArchiveHash = hex.EncodeToString(sum[:])
+	}
+	if len(files) > 0 {
+		e.Files = make([]string, len(files))
+		for i := range files {
+			e.Files[i] = filepath.ToSlash(files[i].RelativePath)
+		}
+		sort.Strings(e.Files)
+		sum := sha256.Sum256([]byte(strings.Join(e.Files, "\n")))
+		e.FilesHash = hex.EncodeToString(sum[:])
+	}
+	return e
+}
+
+// ProjectLog returns the audit log path in a .goingenv directory
+func ProjectLog(goingenvDir string) string {
+	return filepath.Join(goingenvDir, FileName)
+}
+
+// UserLog returns the user's audit log path: $XDG_STATE_HOME/goingenv,
+// else ~/.local/state/goingenv
+func UserLog() (string, error) {
+	base := os.Getenv("XDG_STATE_HOME")
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to locate home directory: %w", err)
+		}
+		base = filepath.Join(home, ".local", "state")
+	}
+	return filepath.Join(base, "goingenv", FileName), nil
+}
+
+// Record appends e to the project log in goingenvDir and, in
+// project-and-user mode, to the user log
+func Record(goingenvDir, mode string, e Entry) error {
+	recorded, err := Append(ProjectLog(goingenvDir), e)
+	if err != nil || mode != types.AuditProjectAndUser {
+		return err
+	}
+
+	userLog, err := UserLog()
+	if err != nil {
+		return err
+	}
+	project, err := filepath.Abs(filepath.Dir(goingenvDir))
+	if err != nil {
+		return fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+	e.Project, e.Ref = project, recorded.Hash
+	_, err = Append(userLog, e)
+	return err
+}
+
+// Append numbers e, chains it to the last entry of the log at path and
+// appends it, creating the log if needed. The log is locked while it is
+// read and written, so concurrent commands keep a single chain.
+func Append(path string, e Entry) (Entry, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return e, fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return e, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	unlock, err := lockFile(f)
+	if err != nil {
+		return e, fmt.Errorf("failed to lock audit log: %w", err)
+	}
+	defer unlock()
+
+	last, err := lastEntry(f)
+	if err != nil {
+		return e, err
+	}
+	e.Seq, e.Prev = 1, ""
+	if last != nil {
+		e.Seq, e.Prev = last.Seq+1, last.Hash
+	}
+	e.Hash = ""
+	e.Hash = e.digest()
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return e, fmt.Errorf("failed to encode audit entry: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return e, fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return e, nil
+}
+
+// digest returns the hash of the entry with its Hash field empty
+func (e Entry) digest() string {
+	e.Hash = ""
+	data, _ := json.Marshal(e) //nolint:errcheck // Entry always encodes
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// lastEntry returns the last entry of the log, or nil for an empty log
+func lastEntry(f *os.File) (*Entry, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat audit log: %w", err)
+	}
+
+	size := info.Size()
+	for chunk := int64(tailChunk); ; chunk *= 2 {
+		offset := max(size-chunk, 0)
+		buf := make([]byte, size-offset)
+		if _, err := f.ReadAt(buf, offset); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read audit log: %w", err)
+		}
+
+		buf = bytes.TrimRight(buf, "\n")
+		if len(buf) == 0 {
+			return nil, nil
+		}
+		start := bytes.LastIndexByte(buf, '\n')
+		if start < 0 && offset > 0 {
+			continue // the last line starts before this chunk
+		}
+
+		var e Entry
+		if err := json.Unmarshal(buf[start+1:], &e); err != nil {
+			return nil, fmt.Errorf("audit log is damaged; run 'goingenv audit verify': %w", err)
+		}
+		return &e, nil
+	}
+}
+
+// Problem is an inconsistency found by Verify or CrossCheck. Line is the
+// line of the checked log, or 0 for an entry that is missing from it.
+type Problem struct {
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+// Verify reads the log at path and checks that every entry is intact and
+// chained to the one before it, starting from the first entry. It returns
+// the entries that could be read and the problems found.
+func Verify(path string) ([]Entry, []Problem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	var problems []Problem
+	var prev *Entry
+
+	lines := bufio.NewScanner(f)
+	lines.Buffer(make([]byte, 0, tailChunk), 64*1024*1024)
+	for n := 1; lines.Scan(); n++ {
+		var e Entry
+		if err := json.Unmarshal(lines.Bytes(), &e); err != nil {
+			problems = append(problems, Problem{Line: n, Message: "not a valid entry"})
+			prev = nil
+			continue
+		}
+
+		if e.digest() != e.Hash {
+			problems = append(problems, Problem{Line: n, Message: "entry was modified (hash mismatch)"})
+		}
+		switch {
+		case prev == nil && n == 1 && (e.Seq != 1 || e.Prev != ""):
+			problems = append(problems, Problem{Line: n, Message: fmt.Sprintf("log starts at entry %d; earlier entries were deleted", e.Seq)})
+		case prev != nil && e.Prev != prev.Hash:
+			problems = append(problems, Problem{Line: n, Message: "chain broken: previous entry was modified or deleted"})
+		case prev != nil && e.Seq != prev.Seq+1:
+			problems = append(problems, Problem{Line: n, Message: fmt.Sprintf("entry %d follows entry %d; entries are missing", e.Seq, prev.Seq)})
+		}
+
+		entries = append(entries, e)
+		prev = &entries[len(entries)-1]
+	}
+	if err := lines.Err(); err != nil {
+		return entries, problems, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return entries, problems, nil
+}
+
+// CrossCheck compares a project log with the user log: every entry the user
+// log recorded for the project must still be in the project log. This
+// catches entries removed from the end of the project log, which its own
+// chain cannot show.
+func CrossCheck(projectEntries, userEntries []Entry, project string) []Problem {
+	hashes := make(map[string]bool, len(projectEntries))
+	for _, e := range projectEntries {
+		hashes[e.Hash] = true
+	}
+
+	var problems []Problem
+	for i, e := range userEntries {
+		if e.Project == project && !hashes[e.Ref] {
+			problems = append(problems, Problem{
+				Message: fmt.Sprintf("%s of %s at %s by %s (user log line %d) is missing", e.Operation, e.Archive, e.Time.Format(time.RFC3339), e.User, i+1),
+			})
+		}
+	}
+	return problems
+}
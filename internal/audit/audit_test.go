@@ -0,0 +1,190 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"goingenv/pkg/types"
+)
+
+// writeLog appends n pack entries to a new log in dir and returns its path
+func writeLog(t *testing.T, dir string, n int) string {
+	t.Helper()
+	path := filepath.Join(dir, FileName)
+	for i := 0; i < n; i++ {
+		if _, err := Append(path, NewEntry(OpPack, "archive.enc", []types.EnvFile{{RelativePath: ".env"}, {RelativePath: "api/.env"}})); err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+	}
+	return path
+}
+
+func TestAppend(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := writeLog(t, tmpDir, 3)
+	entries, problems, err := Verify(path)
+	if err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if len(problems) > 0 {
+		t.Fatalf("Verify() problems = %v, want none", problems)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("Verify() read %d entries, want 3", len(entries))
+	}
+	for i, e := range entries {
+		if e.Seq != i+1 {
+			t.Errorf("entry %d Seq = %d, want %d", i, e.Seq, i+1)
+		}
+		if i > 0 && e.Prev != entries[i-1].Hash {
+			t.Errorf("entry %d Prev = %s, want %s", i, e.Prev, entries[i-1].Hash)
+		}
+		if e.FilesHash == "" || len(e.Files) != 2 {
+			t.Errorf("entry %d files = %v (%s), want both files hashed", i, e.Files, e.FilesHash)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Failed to stat log: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("log mode = %o, want 600", perm)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	tests := []struct {
+		name   string
+		tamper func(lines []string) []string
+		want   string
+	}{
+		{
+			name:   "intact log",
+			tamper: func(lines []string) []string { return lines },
+		},
+		{
+			name: "edited entry",
+			tamper: func(lines []string) []string {
+				lines[1] = strings.Replace(lines[1], `"op":"pack"`, `"op":"unpack"`, 1)
+				return lines
+			},
+			want: "hash mismatch",
+		},
+		{
+			name: "deleted entry",
+			tamper: func(lines []string) []string {
+				return append(lines[:1], lines[2:]...)
+			},
+			want: "chain broken",
+		},
+		{
+			name:   "deleted first entries",
+			tamper: func(lines []string) []string { return lines[2:] },
+			want:   "earlier entries were deleted",
+		},
+		{
+			name: "garbled line",
+			tamper: func(lines []string) []string {
+				lines[2] = "{not json"
+				return lines
+			},
+			want: "not a valid entry",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(tmpDir)
+
+			path := writeLog(t, tmpDir, 4)
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("Failed to read log: %v", err)
+			}
+			lines := tt.tamper(strings.Split(strings.TrimSuffix(string(data), "\n"), "\n"))
+			if writeErr := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o600); writeErr != nil {
+				t.Fatalf("Failed to write log: %v", writeErr)
+			}
+
+			_, problems, err := Verify(path)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if tt.want == "" {
+				if len(problems) > 0 {
+					t.Errorf("Verify() problems = %v, want none", problems)
+				}
+				return
+			}
+			found := false
+			for _, p := range problems {
+				found = found || strings.Contains(p.Message, tt.want)
+			}
+			if !found {
+				t.Errorf("Verify() problems = %v, want one containing %q", problems, tt.want)
+			}
+		})
+	}
+}
+
+func TestRecordUserLog(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	t.Setenv("XDG_STATE_HOME", filepath.Join(tmpDir, "state"))
+
+	goingenvDir := filepath.Join(tmpDir, "project", ".goingenv")
+	for _, op := range []string{OpPack, OpUnpack, OpPrune} {
+		if recordErr := Record(goingenvDir, types.AuditProjectAndUser, NewEntry(op, "a.enc", nil)); recordErr != nil {
+			t.Fatalf("Record() error = %v", recordErr)
+		}
+	}
+
+	userLog, err := UserLog()
+	if err != nil {
+		t.Fatalf("UserLog() error = %v", err)
+	}
+	userEntries, problems, err := Verify(userLog)
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("Verify(user log) = %v, %v", problems, err)
+	}
+	project := filepath.Dir(goingenvDir)
+	if userEntries[0].Project != project {
+		t.Errorf("user entry Project = %s, want %s", userEntries[0].Project, project)
+	}
+
+	// Dropping the last project entry leaves an intact chain, but the user
+	// log still refers to it
+	projectLog := ProjectLog(goingenvDir)
+	data, err := os.ReadFile(projectLog)
+	if err != nil {
+		t.Fatalf("Failed to read log: %v", err)
+	}
+	lines := strings.SplitAfter(string(data), "\n")
+	if writeErr := os.WriteFile(projectLog, []byte(strings.Join(lines[:2], "")), 0o600); writeErr != nil {
+		t.Fatalf("Failed to write log: %v", writeErr)
+	}
+
+	projectEntries, problems, err := Verify(projectLog)
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("Verify(project log) = %v, %v", problems, err)
+	}
+	problems = CrossCheck(projectEntries, userEntries, project)
+	if len(problems) != 1 || !strings.Contains(problems[0].Message, "prune") {
+		t.Errorf("CrossCheck() = %v, want the missing prune entry", problems)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !unix
+
+package audit
+
+import "os"
+
+// lockFile has no file locking to use on this platform
+func lockFile(_ *os.File) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,208 @@
+// Package catalog keeps an unencrypted index of the archives in the
+// .goingenv directory, so archives can be named, tagged and described and
+// then picked without the password.
+//
+// The index only holds what the user chose to record plus non-secret facts
+// about each archive (creator, profile, file count, creation time). It is
+// meant to be committed alongside the archives; never put secrets in tags
+// or notes.
+package catalog
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"goingenv/pkg/types"
+	"goingenv/pkg/utils"
+)
+
+// FileName is the index file inside the .goingenv directory
+const FileName = "index.json"
+
+// currentVersion is the index format version written by Save
+const currentVersion = 1
+
+// validTag matches tags: letters, digits, '.', '_' and '-'
+var validTag = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Entry describes one archive
+type Entry struct {
+	Archive   string    `json:"archive"` // file name inside the .goingenv directory
+	Name      string    `json:"name,omitempty"`
+	Tags      []string  `json:"tags,omitempty"`
+	Note      string    `json:"note,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	Profile   string    `json:"profile,omitempty"`
+	Files     int       `json:"files"`
+	CreatedAt time.Time `json:"created_at"`
+	// Validity period copied from the archive metadata for display; unpack
+	// checks the encrypted metadata itself
+	NotBefore *time.Time `json:"not_before,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// NewEntry describes an archive packed now by the current user
+func NewEntry(archivePath string, files int) Entry {
+	return Entry{
+		Archive:   filepath.Base(archivePath),
+		CreatedBy: utils.CurrentUserName(),
+		Files:     files,
+		CreatedAt: time.Now().UTC(),
+	}
+}
+
+// HasTag reports whether the entry carries tag
+func (e *Entry) HasTag(tag string) bool {
+	for _, t := range e.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Validity reports whether now is outside the recorded validity period, as
+// types.Archive.Validity does
+func (e *Entry) Validity(now time.Time) string {
+	return (&types.Archive{NotBefore: e.NotBefore, ExpiresAt: e.ExpiresAt}).Validity(now)
+}
+
+// Label summarizes the entry as `"name" [tag, tag] (expired)`, or "" when
+// it has no name or tags and is within its validity period
+func (e *Entry) Label() string {
+	var parts []string
+	if e.Name != "" {
+		parts = append(parts, fmt.Sprintf("%q", e.Name))
+	}
+	if len(e.Tags) > 0 {
+		parts = append(parts, "["+strings.Join(e.Tags, ", ")+"]")
+	}
+	if validity := e.Validity(time.Now()); validity != "" {
+		parts = append(parts, "("+validity+")")
+	}
+	return strings.Join(parts, " ")
+}
+
+// Catalog is the archive index
+type Catalog struct {
+	Version  int     `json:"version"`
+	Archives []Entry `json:"archives"`
+}
+
+// Path returns the index path for an archive directory
+func Path(dir string) string {
+	return filepath.Join(dir, FileName)
+}
+
+// ValidateTag checks that a tag is safe to use on the command line and in
+// file listings, and is not a reserved archive reference
+func ValidateTag(tag string) error {
+	if !validTag.MatchString(tag) {
+		return fmt.Errorf("invalid tag %q: use letters, digits, '.', '_' and '-'", tag)
+	}
+	if tag == RefLatest {
+		return fmt.Errorf("invalid tag %q: reserved for the newest archive", tag)
+	}
+	return nil
+}
+
+// Load reads the index. A missing file yields an empty catalog.
+func Load(path string) (*Catalog, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Catalog{Version: currentVersion}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read archive index: %w", err)
+	}
+
+	var c Catalog
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse archive index %s: %w", path, err)
+	}
+	if c.Version > currentVersion {
+		return nil, fmt.Errorf("archive index %s has version %d; upgrade goingenv to read it", path, c.Version)
+	}
+	return &c, nil
+}
+
+// Save writes the index atomically, oldest archive first
+func Save(path string, c *Catalog) error {
+	c.Version = currentVersion
+	sort.SliceStable(c.Archives, func(i, j int) bool {
+		return c.Archives[i].CreatedAt.Before(c.Archives[j].CreatedAt)
+	})
+
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal archive index: %w", err)
+	}
+	if err := utils.WriteFileAtomic(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write archive index: %w", err)
+	}
+	return nil
+}
+
+// Record adds entries to the index at path
+func Record(path string, entries ...Entry) error {
+	c, err := Load(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		c.Put(entry)
+	}
+	return Save(path, c)
+}
+
+// Lookup returns the entry for an archive path or file name
+func (c *Catalog) Lookup(archivePath string) (*Entry, bool) {
+	name := filepath.Base(archivePath)
+	for i := range c.Archives {
+		if c.Archives[i].Archive == name {
+			return &c.Archives[i], true
+		}
+	}
+	return nil, false
+}
+
+// Put adds an entry, replacing any entry for the same archive
+func (c *Catalog) Put(entry Entry) {
+	if existing, ok := c.Lookup(entry.Archive); ok {
+		*existing = entry
+		return
+	}
+	c.Archives = append(c.Archives, entry)
+}
+
+// Remove drops the entry for an archive, reporting whether there was one
+func (c *Catalog) Remove(archivePath string) bool {
+	name := filepath.Base(archivePath)
+	for i := range c.Archives {
+		if c.Archives[i].Archive == name {
+			c.Archives = append(c.Archives[:i], c.Archives[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// Tagged returns the entries carrying tag, newest first
+func (c *Catalog) Tagged(tag string) []Entry {
+	var entries []Entry
+	for _, e := range c.Archives {
+		if e.HasTag(tag) {
+			entries = append(entries, e)
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].CreatedAt.After(entries[j].CreatedAt)
+	})
+	return entries
+}
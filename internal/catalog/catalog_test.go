@@ -0,0 +1,89 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCatalogRoundTrip(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-catalog-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := Path(tmpDir)
+	c, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() of a missing index error = %v", err)
+	}
+	if len(c.Archives) != 0 {
+		t.Fatalf("Load() of a missing index = %d entries, want 0", len(c.Archives))
+	}
+
+	now := time.Now().UTC().Truncate(time.Second)
+	c.Put(Entry{Archive: "new.enc", Tags: []string{"release-42"}, CreatedAt: now})
+	c.Put(Entry{Archive: "old.enc", Tags: []string{"release-42", "stable"}, CreatedAt: now.Add(-time.Hour)})
+	c.Put(Entry{Archive: "new.enc", Name: "renamed", Tags: []string{"release-42"}, CreatedAt: now})
+	if saveErr := Save(path, c); saveErr != nil {
+		t.Fatalf("Save() error = %v", saveErr)
+	}
+
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if len(loaded.Archives) != 2 || loaded.Archives[0].Archive != "old.enc" {
+		t.Fatalf("Load() = %+v, want old.enc then new.enc", loaded.Archives)
+	}
+	if e, ok := loaded.Lookup(filepath.Join(".goingenv", "new.enc")); !ok || e.Name != "renamed" {
+		t.Errorf("Lookup() = %+v, %v, want the replaced entry", e, ok)
+	}
+
+	tagged := loaded.Tagged("release-42")
+	if len(tagged) != 2 || tagged[0].Archive != "new.enc" {
+		t.Errorf("Tagged() = %+v, want new.enc first", tagged)
+	}
+	if got := loaded.Tagged("missing"); len(got) != 0 {
+		t.Errorf("Tagged() of an unknown tag = %+v, want none", got)
+	}
+
+	if e, _ := loaded.Lookup("new.enc"); e.Label() != `"renamed" [release-42]` {
+		t.Errorf("Label() = %s, want %s", e.Label(), `"renamed" [release-42]`)
+	}
+
+	if !loaded.Remove("old.enc") || loaded.Remove("old.enc") {
+		t.Error("Remove() should remove an entry exactly once")
+	}
+
+	if writeErr := os.WriteFile(path, []byte(`{"version": 99}`), 0o644); writeErr != nil {
+		t.Fatalf("Failed to write index: %v", writeErr)
+	}
+	if _, loadErr := Load(path); loadErr == nil {
+		t.Error("Load() of a newer index version should fail")
+	}
+}
+
+func TestValidateTag(t *testing.T) {
+	tests := []struct {
+		tag     string
+		wantErr bool
+	}{
+		{tag: "release-42"},
+		{tag: "v1.2.3"},
+		{tag: "prod_eu"},
+		{tag: "", wantErr: true},
+		{tag: "-rf", wantErr: true},
+		{tag: "two words", wantErr: true},
+		{tag: "a,b", wantErr: true},
+		{tag: "latest", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidateTag(tt.tag); (err != nil) != tt.wantErr {
+			t.Errorf("ValidateTag(%q) error = %v, wantErr %v", tt.tag, err, tt.wantErr)
+		}
+	}
+}
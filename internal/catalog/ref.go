@@ -0,0 +1,172 @@
+package catalog
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"goingenv/internal/config"
+	"goingenv/internal/constants"
+	"goingenv/pkg/types"
+	"goingenv/pkg/utils"
+)
+
+// RefLatest names the newest archive; "latest~N" names the Nth before it
+const RefLatest = "latest"
+
+// MinHashPrefix is the shortest hash prefix accepted as a reference
+const MinHashPrefix = 4
+
+// ShortHashLen is the hash prefix length shown next to archives
+const ShortHashLen = 8
+
+// hashPrefix matches a lowercase hex SHA-256 prefix
+var hashPrefix = regexp.MustCompile(`^[0-9a-f]+$`)
+
+// Hash returns the hex SHA-256 of an archive file, as used by hash references
+func Hash(archivePath string) (string, error) {
+	sum, err := utils.CalculateFileChecksum(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", archivePath, err)
+	}
+	return sum, nil
+}
+
+// CreatedAt returns when an archive was created: the time in its index
+// entry, else the timestamp in its file name, else its modification time
+func (c *Catalog) CreatedAt(archivePath string) time.Time {
+	if entry, ok := c.Lookup(archivePath); ok && !entry.CreatedAt.IsZero() {
+		return entry.CreatedAt
+	}
+	if ts, ok := config.ArchiveTimestamp(archivePath); ok {
+		return ts
+	}
+	if info, err := os.Stat(archivePath); err == nil {
+		return info.ModTime()
+	}
+	return time.Time{}
+}
+
+// Newest orders archives newest first by CreatedAt. Ties are broken by file
+// name so the order never depends on the directory listing.
+func (c *Catalog) Newest(archives []string) []string {
+	created := make(map[string]time.Time, len(archives))
+	for _, archivePath := range archives {
+		created[archivePath] = c.CreatedAt(archivePath)
+	}
+
+	sorted := append([]string(nil), archives...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		ti, tj := created[sorted[i]], created[sorted[j]]
+		if !ti.Equal(tj) {
+			return ti.After(tj)
+		}
+		return filepath.Base(sorted[i]) > filepath.Base(sorted[j])
+	})
+	return sorted
+}
+
+// Resolve returns the archive a reference names, trying in order:
+//   - "" or "latest": the newest archive; "latest~N": the Nth before it
+//   - an archive file name
+//   - "@2026-09-01": the newest archive created on or before that day
+//   - a tag: the newest archive carrying it
+//   - a hash prefix of at least MinHashPrefix hex digits
+func (c *Catalog) Resolve(ref string, archives []string) (string, error) {
+	if len(archives) == 0 {
+		return "", fmt.Errorf("%w: no archives in %s directory", types.ErrArchiveNotFound, config.GetGoingEnvDir())
+	}
+	newest := c.Newest(archives)
+
+	if ref == "" || ref == RefLatest || strings.HasPrefix(ref, RefLatest+"~") {
+		return resolveLatest(ref, newest)
+	}
+	for _, archivePath := range newest {
+		if filepath.Base(archivePath) == ref {
+			return archivePath, nil
+		}
+	}
+	if strings.HasPrefix(ref, "@") {
+		return c.resolveDate(ref, newest)
+	}
+	for _, entry := range c.Tagged(ref) {
+		for _, archivePath := range newest {
+			if filepath.Base(archivePath) == entry.Archive {
+				return archivePath, nil
+			}
+		}
+	}
+	if len(ref) >= MinHashPrefix && hashPrefix.MatchString(ref) {
+		return resolveHash(ref, newest)
+	}
+	return "", fmt.Errorf("%w: %q is not an archive, tag, date or hash", types.ErrArchiveNotFound, ref)
+}
+
+// resolveLatest resolves "latest" and "latest~N" against archives sorted
+// newest first
+func resolveLatest(ref string, newest []string) (string, error) {
+	n := 0
+	if offset, ok := strings.CutPrefix(ref, RefLatest+"~"); ok {
+		var err error
+		if n, err = strconv.Atoi(offset); err != nil || n < 0 {
+			return "", fmt.Errorf("invalid archive reference %q: want latest~N with N >= 0", ref)
+		}
+	}
+	if n >= len(newest) {
+		return "", fmt.Errorf("%w: %s needs %d archives, found %d", types.ErrArchiveNotFound, ref, n+1, len(newest))
+	}
+	return newest[n], nil
+}
+
+// resolveDate resolves "@<date>" to the newest archive created by the end
+// of that local day, or by that exact time when one is given
+func (c *Catalog) resolveDate(ref string, newest []string) (string, error) {
+	value := strings.TrimPrefix(ref, "@")
+	cutoff, err := time.ParseInLocation(constants.DateTimeFormat, value, time.Local)
+	if err != nil {
+		day, dayErr := time.ParseInLocation(constants.DateFormat, value, time.Local)
+		if dayErr != nil {
+			return "", fmt.Errorf("invalid archive reference %q: want @YYYY-MM-DD or @\"YYYY-MM-DD HH:MM:SS\"", ref)
+		}
+		cutoff = day.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	for _, archivePath := range newest {
+		if !c.CreatedAt(archivePath).After(cutoff) {
+			return archivePath, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no archive created by %s", types.ErrArchiveNotFound, value)
+}
+
+// resolveHash resolves a hash prefix, which must match exactly one archive
+func resolveHash(prefix string, archives []string) (string, error) {
+	var matches []string
+	for _, archivePath := range archives {
+		sum, err := Hash(archivePath)
+		if err != nil {
+			return "", err
+		}
+		if strings.HasPrefix(sum, prefix) {
+			matches = append(matches, archivePath)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return "", fmt.Errorf("%w: no archive hash starts with %s", types.ErrArchiveNotFound, prefix)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, len(matches))
+		for i, m := range matches {
+			names[i] = filepath.Base(m)
+		}
+		return "", fmt.Errorf("archive hash %s is ambiguous: %s", prefix, strings.Join(names, ", "))
+	}
+}
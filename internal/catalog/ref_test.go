@@ -0,0 +1,91 @@
+package catalog
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"goingenv/pkg/types"
+)
+
+func TestResolve(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-ref-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Listed out of order on purpose: zeta.enc sorts last by name but is
+	// the oldest, and the indexed notes.enc is the newest
+	write := func(name, content string, modTime time.Time) string {
+		path := filepath.Join(tmpDir, name)
+		if writeErr := os.WriteFile(path, []byte(content), 0o600); writeErr != nil {
+			t.Fatalf("Failed to write %s: %v", name, writeErr)
+		}
+		if chtimesErr := os.Chtimes(path, modTime, modTime); chtimesErr != nil {
+			t.Fatalf("Failed to set times of %s: %v", name, chtimesErr)
+		}
+		return path
+	}
+	zeta := write("zeta.enc", "zeta", time.Date(2026, 8, 1, 12, 0, 0, 0, time.Local))
+	sept := write("archive-20260901-093000.enc", "sept", time.Now())
+	oct := write("archive-20261002-080000.enc", "oct", time.Now())
+	notes := write("notes.enc", "notes", time.Date(2026, 1, 1, 0, 0, 0, 0, time.Local))
+	archives := []string{notes, oct, sept, zeta}
+
+	c := &Catalog{}
+	c.Put(Entry{Archive: "notes.enc", Tags: []string{"release-42"}, CreatedAt: time.Date(2026, 10, 5, 0, 0, 0, 0, time.Local)})
+	c.Put(Entry{Archive: "zeta.enc", Tags: []string{"old"}})
+
+	zetaHash, err := Hash(zeta)
+	if err != nil {
+		t.Fatalf("Hash() error = %v", err)
+	}
+
+	tests := []struct {
+		ref      string
+		want     string
+		notFound bool
+		wantErr  bool
+	}{
+		{ref: "", want: notes},
+		{ref: "latest", want: notes},
+		{ref: "latest~0", want: notes},
+		{ref: "latest~1", want: oct},
+		{ref: "latest~3", want: zeta},
+		{ref: "latest~4", notFound: true},
+		{ref: "latest~x", wantErr: true},
+		{ref: "zeta.enc", want: zeta},
+		{ref: "@2026-09-01", want: sept},
+		{ref: "@2026-10-04", want: oct},
+		{ref: "@2026-09-01 09:00:00", want: zeta},
+		{ref: "@2026-07-01", notFound: true},
+		{ref: "@yesterday", wantErr: true},
+		{ref: "release-42", want: notes},
+		{ref: "old", want: zeta},
+		{ref: zetaHash[:MinHashPrefix], want: zeta},
+		{ref: zetaHash, want: zeta},
+		{ref: zetaHash[:MinHashPrefix-1], notFound: true},
+		{ref: "missing", notFound: true},
+	}
+
+	for _, tt := range tests {
+		got, err := c.Resolve(tt.ref, archives)
+		if (err != nil) != (tt.wantErr || tt.notFound) {
+			t.Errorf("Resolve(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr || tt.notFound)
+			continue
+		}
+		if tt.notFound && !errors.Is(err, types.ErrArchiveNotFound) {
+			t.Errorf("Resolve(%q) error = %v, want ErrArchiveNotFound", tt.ref, err)
+		}
+		if got != tt.want {
+			t.Errorf("Resolve(%q) = %s, want %s", tt.ref, got, tt.want)
+		}
+	}
+
+	if _, err := c.Resolve("latest", nil); !errors.Is(err, types.ErrArchiveNotFound) {
+		t.Errorf("Resolve() without archives error = %v, want ErrArchiveNotFound", err)
+	}
+}
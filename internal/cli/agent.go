@@ -0,0 +1,382 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"goingenv/internal/agent"
+	"goingenv/internal/crypto"
+	"goingenv/pkg/password"
+	"goingenv/pkg/types"
+)
+
+// agentStartTimeout is how long agent start waits for the daemon's socket
+const agentStartTimeout = 3 * time.Second
+
+// agentStatusResult is the JSON result of the agent status command
+type agentStatusResult struct {
+	Socket  string              `json:"socket"`
+	Running bool                `json:"running"`
+	PID     int                 `json:"pid,omitempty"`
+	Started *time.Time          `json:"started,omitempty"`
+	Entries []agent.EntryStatus `json:"entries"`
+}
+
+// newAgentCommand creates the agent command
+func newAgentCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Cache unlocked passwords for a session",
+		Long: `Run a local key agent that keeps unlocked passwords and derived keys in
+memory, so list and unpack don't prompt or re-derive keys every time.
+
+The agent listens on a Unix socket only the current user can open, and
+checks the peer's user ID on every connection. Passwords expire after their
+TTL, are wiped by 'agent lock', and are never written to disk.
+
+Commands without a password flag ask the agent for the current project's
+password before prompting. Set GOINGENV_AGENT_SOCK to use another socket.
+
+Examples:
+  goingenv agent start                # Start the agent in the background
+  goingenv agent add --ttl 1h         # Unlock this project for an hour
+  goingenv agent status               # Show unlocked projects
+  goingenv agent lock --all           # Forget every password
+  goingenv agent stop`,
+	}
+
+	cmd.AddCommand(newAgentStartCommand())
+	cmd.AddCommand(newAgentStopCommand())
+	cmd.AddCommand(newAgentAddCommand())
+	cmd.AddCommand(newAgentLockCommand())
+	cmd.AddCommand(newAgentStatusCommand())
+
+	return cmd
+}
+
+// newAgentStartCommand creates the agent start subcommand
+func newAgentStartCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "start",
+		Short: "Start the key agent",
+		Args:  cobra.NoArgs,
+		RunE:  runAgentStartCommand,
+	}
+	cmd.Flags().Bool("foreground", false, "Run in the foreground instead of detaching")
+	return cmd
+}
+
+// newAgentStopCommand creates the agent stop subcommand
+func newAgentStopCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stop",
+		Short: "Stop the key agent, wiping every password",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := NewOutput(appVersion)
+			out.Header()
+			out.Blank()
+
+			if err := agent.NewClient(agent.SocketPath()).Stop(); err != nil {
+				out.Error(fmt.Sprintf("Failed to stop agent: %v", err))
+				return err
+			}
+			out.Success("Agent stopped")
+			return nil
+		},
+	}
+}
+
+// newAgentAddCommand creates the agent add subcommand
+func newAgentAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add",
+		Short: "Unlock the current project's password in the agent",
+		Long: `Read the archive password and hand it to the agent for --ttl.
+
+The password must open at least one existing archive, so a typo
+isn't cached.`,
+		Args: cobra.NoArgs,
+		RunE: runAgentAddCommand,
+	}
+	addPasswordFlags(cmd)
+	cmd.Flags().Duration("ttl", agent.DefaultTTL, "How long the password stays unlocked")
+	return cmd
+}
+
+// newAgentLockCommand creates the agent lock subcommand
+func newAgentLockCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lock",
+		Short: "Forget the current project's password",
+		Args:  cobra.NoArgs,
+		RunE:  runAgentLockCommand,
+	}
+	cmd.Flags().Bool("all", false, "Forget the passwords of every project")
+	return cmd
+}
+
+// newAgentStatusCommand creates the agent status subcommand
+func newAgentStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the agent runs and which projects are unlocked",
+		Args:  cobra.NoArgs,
+		RunE:  runAgentStatusCommand,
+	}
+}
+
+// runAgentStartCommand starts the agent, detached unless --foreground
+func runAgentStartCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+	socket := agent.SocketPath()
+
+	foreground, err := cmd.Flags().GetBool("foreground")
+	if err != nil {
+		return fmt.Errorf("failed to get foreground flag: %w", err)
+	}
+	if foreground {
+		return serveAgent(out, socket)
+	}
+
+	out.Header()
+	out.Blank()
+
+	client := agent.NewClient(socket)
+	if _, statusErr := client.Status(); statusErr == nil {
+		out.Success(fmt.Sprintf("Agent already running on %s", socket))
+		return nil
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		out.Error(fmt.Sprintf("Failed to locate goingenv: %v", err))
+		return err
+	}
+	daemon := exec.Command(exe, "agent", "start", "--foreground")
+	daemon.Dir = os.TempDir()
+	daemon.SysProcAttr = agent.DetachedProcAttr()
+	if startErr := daemon.Start(); startErr != nil {
+		out.Error(fmt.Sprintf("Failed to start agent: %v", startErr))
+		return startErr
+	}
+	pid := daemon.Process.Pid
+	_ = daemon.Process.Release() //nolint:errcheck // the agent runs on its own
+
+	deadline := time.Now().Add(agentStartTimeout)
+	for time.Now().Before(deadline) {
+		if _, statusErr := client.Status(); statusErr == nil {
+			out.Success(fmt.Sprintf("Agent started (pid %d)", pid))
+			out.Indent(fmt.Sprintf("Socket: %s", socket))
+			out.Blank()
+			out.Hint("Unlock this project with 'goingenv agent add'")
+			return nil
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	out.Error("Agent did not start; run 'goingenv agent start --foreground' to see why")
+	return fmt.Errorf("%w: no response on %s", agent.ErrNotRunning, socket)
+}
+
+// serveAgent runs the agent in this process until stopped or signalled
+func serveAgent(out *Output, socket string) error {
+	if err := agent.DisableCoreDumps(); err != nil {
+		return fmt.Errorf("failed to disable core dumps: %w", err)
+	}
+
+	l, err := agent.Listen(socket)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(socket)
+	out.Success(fmt.Sprintf("Agent listening on %s (pid %d)", socket, os.Getpid()))
+
+	srv := agent.NewServer()
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+	go func() {
+		select {
+		case <-signals:
+			srv.Stop()
+		case <-srv.Done():
+		}
+	}()
+
+	return srv.Serve(l)
+}
+
+// runAgentAddCommand reads, verifies and unlocks the project password
+func runAgentAddCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+
+	app, err := initApp()
+	if err != nil {
+		out.Header()
+		out.Blank()
+		out.Error(err.Error())
+		return err
+	}
+
+	passwordOpts, err := parsePasswordOpts(cmd)
+	if err != nil {
+		return err
+	}
+	ttl, err := cmd.Flags().GetDuration("ttl")
+	if err != nil {
+		return fmt.Errorf("failed to get ttl flag: %w", err)
+	}
+	if ttl < time.Second {
+		return newUsageError("--ttl must be at least 1s")
+	}
+
+	scope, err := agentScope()
+	if err != nil {
+		return err
+	}
+
+	client := agent.NewClient(agent.SocketPath())
+	if _, statusErr := client.Status(); statusErr != nil {
+		out.Header()
+		out.Blank()
+		out.Error("Agent is not running")
+		out.Hint("Start it with 'goingenv agent start'")
+		return statusErr
+	}
+
+	if validateErr := password.ValidatePasswordOptions(passwordOpts); validateErr != nil {
+		return newUsageError("invalid password options: %v", validateErr)
+	}
+	key, err := password.GetPassword(passwordOpts)
+	if err != nil {
+		out.Header()
+		out.Blank()
+		out.Error(fmt.Sprintf("Failed to get password: %v", err))
+		return fmt.Errorf("failed to get password: %w", err)
+	}
+	defer password.ClearPassword(&key)
+
+	out.Header()
+	out.Blank()
+
+	if verifyErr := verifyArchivePass(out, app, key); verifyErr != nil {
+		return verifyErr
+	}
+
+	if addErr := client.Add(scope, key, ttl); addErr != nil {
+		out.Error(fmt.Sprintf("Failed to add password: %v", addErr))
+		return addErr
+	}
+
+	out.Success(fmt.Sprintf("Unlocked %s for %s", scope, ttl))
+	return nil
+}
+
+// runAgentLockCommand forgets the project password, or all with --all
+func runAgentLockCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+	out.Header()
+	out.Blank()
+
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return fmt.Errorf("failed to get all flag: %w", err)
+	}
+
+	scope := ""
+	if !all {
+		if scope, err = agentScope(); err != nil {
+			return err
+		}
+	}
+
+	if lockErr := agent.NewClient(agent.SocketPath()).Lock(scope); lockErr != nil {
+		out.Error(fmt.Sprintf("Failed to lock: %v", lockErr))
+		return lockErr
+	}
+
+	if all {
+		out.Success("Locked all projects")
+	} else {
+		out.Success(fmt.Sprintf("Locked %s", scope))
+	}
+	return nil
+}
+
+// runAgentStatusCommand shows the agent's unlocked projects
+func runAgentStatusCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+	out.Header()
+	out.Blank()
+
+	socket := agent.SocketPath()
+	res := &agentStatusResult{Socket: socket, Entries: []agent.EntryStatus{}}
+	setResultData(res)
+
+	status, err := agent.NewClient(socket).Status()
+	if err != nil {
+		out.Warning("Agent is not running")
+		out.Hint("Start it with 'goingenv agent start'")
+		return nil
+	}
+	res.Running = true
+	res.PID = status.PID
+	res.Started = &status.Started
+	res.Entries = status.Entries
+
+	out.Section("Agent")
+	out.Indent(fmt.Sprintf("PID: %d", status.PID))
+	out.Indent(fmt.Sprintf("Socket: %s", socket))
+	out.Indent(fmt.Sprintf("Uptime: %s", time.Since(status.Started).Round(time.Second)))
+	out.Blank()
+
+	if len(status.Entries) == 0 {
+		out.MutedPrint("  No unlocked projects")
+		return nil
+	}
+
+	out.Section(fmt.Sprintf("Unlocked (%d)", len(status.Entries)))
+	for _, e := range status.Entries {
+		out.ListItem(fmt.Sprintf("%s (expires in %s, %d cached keys)",
+			e.Scope, time.Until(e.Expires).Round(time.Second), e.CachedKeys))
+	}
+	return nil
+}
+
+// agentScope identifies the current project to the agent
+func agentScope() (string, error) {
+	scope, err := filepath.Abs(".")
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+	return scope, nil
+}
+
+// agentPassword returns the project password unlocked in the agent, if any.
+// On success the app's crypto service also takes derived keys from the
+// agent, so repeated commands skip PBKDF2.
+func agentPassword(app *types.App) (string, bool) {
+	scope, err := agentScope()
+	if err != nil {
+		return "", false
+	}
+
+	client := agent.NewClient(agent.SocketPath())
+	key, err := client.Get(scope)
+	if err != nil {
+		return "", false
+	}
+
+	if svc, ok := app.Crypto.(*crypto.Service); ok {
+		svc.SetKeyDeriver(&agent.KeyDeriver{Client: client, Scope: scope})
+	}
+	return key, true
+}
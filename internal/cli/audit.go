@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"goingenv/internal/audit"
+	"goingenv/internal/config"
+	"goingenv/pkg/types"
+)
+
+// newAuditCommand creates the audit command
+func newAuditCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Check the audit log of archive operations",
+		Long: `pack, unpack, add, rm, update, resolve, git-merge, recover --rekey and
+prune append an entry to .goingenv/audit.log for every archive they write,
+read or remove: the operation, the archive and the SHA-256 of its encrypted
+file, the names of the files involved and a hash of that list, the user,
+host and time. Secret values are never logged.
+
+Each entry holds the hash of the entry before it, so editing or deleting an
+entry in the middle of the log breaks the chain. In the default project
+mode the log vouches only for itself: entries cut from the end of the log,
+or a log whose entries were all rewritten and hashed again, cannot be
+detected. With audit_log set to project-and-user, entries are also appended
+to the user's own log in $XDG_STATE_HOME/goingenv (default
+~/.local/state/goingenv), which shows project entries that were removed or
+replaced.
+
+Examples:
+  goingenv audit verify
+  goingenv audit verify --json`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "verify",
+		Short: "Check the audit log for edited or deleted entries",
+		Args:  cobra.NoArgs,
+		RunE:  runAuditVerifyCommand,
+	})
+
+	return cmd
+}
+
+// auditVerifyResult is the JSON result of the audit verify command
+type auditVerifyResult struct {
+	Logs []auditedLog `json:"logs"`
+	OK   bool         `json:"ok"`
+}
+
+// auditedLog is the verification outcome of one audit log
+type auditedLog struct {
+	Path     string          `json:"path"`
+	Entries  int             `json:"entries"`
+	OK       bool            `json:"ok"`
+	Problems []audit.Problem `json:"problems,omitempty"`
+}
+
+// runAuditVerifyCommand executes the audit verify command
+func runAuditVerifyCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+	out.Header()
+	out.Blank()
+
+	if _, err := initApp(); err != nil {
+		out.Error(err.Error())
+		return err
+	}
+
+	res := auditVerifyResult{Logs: []auditedLog{}, OK: true}
+	setResultData(&res)
+
+	goingenvDir := config.GetGoingEnvDir()
+	projectLog := audit.ProjectLog(goingenvDir)
+	if _, err := os.Stat(projectLog); os.IsNotExist(err) {
+		out.Success("No audit log yet")
+		return nil
+	}
+
+	projectEntries, problems, err := audit.Verify(projectLog)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	res.Logs = append(res.Logs, auditedLog{Path: projectLog, Entries: len(projectEntries), Problems: problems})
+
+	// The user log, when there is one, vouches for this project's entries
+	userLog, err := audit.UserLog()
+	if err == nil {
+		if _, statErr := os.Stat(userLog); statErr == nil {
+			userEntries, userProblems, verifyErr := audit.Verify(userLog)
+			if verifyErr != nil {
+				out.Error(verifyErr.Error())
+				return verifyErr
+			}
+			project, absErr := filepath.Abs(filepath.Dir(goingenvDir))
+			if absErr != nil {
+				return fmt.Errorf("failed to resolve project directory: %w", absErr)
+			}
+			res.Logs[0].Problems = append(res.Logs[0].Problems, audit.CrossCheck(projectEntries, userEntries, project)...)
+			res.Logs = append(res.Logs, auditedLog{Path: userLog, Entries: len(userEntries), Problems: userProblems})
+		}
+	}
+
+	for i := range res.Logs {
+		log := &res.Logs[i]
+		log.OK = len(log.Problems) == 0
+		res.OK = res.OK && log.OK
+		if log.OK {
+			out.Success(fmt.Sprintf("PASS %s (%d entries)", log.Path, log.Entries))
+			continue
+		}
+		out.Error(fmt.Sprintf("FAIL %s (%d entries)", log.Path, log.Entries))
+		for _, p := range log.Problems {
+			if p.Line > 0 {
+				out.ListItem(fmt.Sprintf("line %d: %s", p.Line, p.Message))
+			} else {
+				out.ListItem(p.Message)
+			}
+		}
+	}
+
+	if !res.OK {
+		out.Blank()
+		return fmt.Errorf("%w: audit log was modified", types.ErrIntegrity)
+	}
+	return nil
+}
+
+// auditArchive records an operation on an archive in the audit log. A log
+// that cannot be written is reported but does not fail the operation.
+func auditArchive(out *Output, app *types.App, op, archivePath string, files []types.EnvFile) {
+	entry := audit.NewEntry(op, archivePath, files)
+	if err := audit.Record(config.GetGoingEnvDir(), app.Config.AuditLog, entry); err != nil {
+		out.Warning(fmt.Sprintf("Audit log not updated: %v", err))
+	}
+}
@@ -0,0 +1,106 @@
+package cli
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"goingenv/internal/catalog"
+	"goingenv/internal/config"
+	"goingenv/pkg/types"
+)
+
+// catalogPath returns the archive index path of the current project
+func catalogPath() string {
+	return catalog.Path(config.GetGoingEnvDir())
+}
+
+// newCatalogEntry describes a freshly packed archive for the index
+func newCatalogEntry(archivePath, name string, opts *PackOpts, files int) catalog.Entry {
+	entry := catalog.NewEntry(archivePath, files)
+	entry.Name, entry.Tags, entry.Note, entry.Profile = name, opts.Tags, opts.Note, opts.Profile
+	entry.NotBefore, entry.ExpiresAt = opts.NotBefore, opts.ExpiresAt
+	return entry
+}
+
+// recordArchives adds entries to the archive index. The archives exist
+// either way, so a failure only warns.
+func recordArchives(out *Output, entries ...catalog.Entry) {
+	if err := catalog.Record(catalogPath(), entries...); err != nil {
+		out.Warning(fmt.Sprintf("Archive index not updated: %v", err))
+	}
+}
+
+// forgetArchives drops removed archives from the archive index
+func forgetArchives(out *Output, archivePaths []string) {
+	path := catalogPath()
+	c, err := catalog.Load(path)
+	if err != nil {
+		out.Warning(fmt.Sprintf("Archive index not updated: %v", err))
+		return
+	}
+
+	changed := false
+	for _, archivePath := range archivePaths {
+		if c.Remove(archivePath) {
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	if saveErr := catalog.Save(path, c); saveErr != nil {
+		out.Warning(fmt.Sprintf("Archive index not updated: %v", saveErr))
+	}
+}
+
+// updateCatalogFiles records the new file count of an archive changed in
+// place; archives missing from the index are left out of it
+func updateCatalogFiles(out *Output, archivePath string, files int) {
+	path := catalogPath()
+	c, err := catalog.Load(path)
+	if err != nil {
+		out.Warning(fmt.Sprintf("Archive index not updated: %v", err))
+		return
+	}
+
+	entry, ok := c.Lookup(archivePath)
+	if !ok || entry.Files == files {
+		return
+	}
+	entry.Files = files
+	if saveErr := catalog.Save(path, c); saveErr != nil {
+		out.Warning(fmt.Sprintf("Archive index not updated: %v", saveErr))
+	}
+}
+
+// loadCatalog reads the archive index for display, warning and returning
+// an empty index when it cannot be read
+func loadCatalog(out *Output) *catalog.Catalog {
+	c, err := catalog.Load(catalogPath())
+	if err != nil {
+		out.Warning(err.Error())
+		return &catalog.Catalog{}
+	}
+	return c
+}
+
+// taggedArchive resolves the newest existing archive carrying tag
+func taggedArchive(app *types.App, tag string) (string, error) {
+	c, err := catalog.Load(catalogPath())
+	if err != nil {
+		return "", err
+	}
+
+	archives, err := app.Archiver.GetAvailableArchives("")
+	if err != nil {
+		return "", fmt.Errorf("failed to find archives: %w", err)
+	}
+	for _, entry := range c.Tagged(tag) {
+		for _, archivePath := range archives {
+			if filepath.Base(archivePath) == entry.Archive {
+				return archivePath, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("%w: no archive tagged %q. Tag archives with 'goingenv pack --tag %s'", types.ErrArchiveNotFound, tag, tag)
+}
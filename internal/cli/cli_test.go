@@ -1,11 +1,23 @@
 package cli
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
+	"goingenv/internal/audit"
+	"goingenv/internal/catalog"
+	"goingenv/internal/config"
+	"goingenv/internal/crypto"
+	"goingenv/internal/git"
+	"goingenv/internal/signing"
+	"goingenv/pkg/password"
 	"goingenv/pkg/types"
 )
 
@@ -239,7 +251,7 @@ func TestNewRootCommand(t *testing.T) {
 	}
 
 	// Check that subcommands are registered
-	subcommands := []string{"init", "pack", "unpack", "list", "status"}
+	subcommands := []string{"init", "pack", "unpack", "list", "status", "config", "passgen", "agent", "split-key", "recover", "keygen", "signers", "sign", "verify", "prune", "add", "rm", "update", "git", "git-textconv", "guard", "git-merge", "resolve", "audit"}
 	for _, name := range subcommands {
 		found := false
 		for _, subcmd := range cmd.Commands() {
@@ -285,7 +297,7 @@ func TestNewPackCommand(t *testing.T) {
 	}
 
 	// Check for required flags
-	expectedFlags := []string{"password-env", "directory", "output", "depth", "include", "exclude", "dry-run", "verbose"}
+	expectedFlags := []string{"password-env", "password-file", "password-fd", "password-stdin", "password-cmd", "directory", "output", "depth", "include", "exclude", "profile", "generate-password", "sign", "name", "tag", "note", "dry-run", "verbose"}
 	for _, flag := range expectedFlags {
 		if cmd.Flags().Lookup(flag) == nil {
 			t.Errorf("Pack command missing --%s flag", flag)
@@ -305,7 +317,7 @@ func TestNewUnpackCommand(t *testing.T) {
 	}
 
 	// Check for required flags
-	expectedFlags := []string{"password-env", "file", "target", "overwrite", "backup", "verify", "verbose", "dry-run", "include", "exclude"}
+	expectedFlags := []string{"password-env", "password-file", "password-fd", "password-stdin", "password-cmd", "file", "target", "overwrite", "backup", "verify", "verbose", "dry-run", "include", "exclude", "tag"}
 	for _, flag := range expectedFlags {
 		if cmd.Flags().Lookup(flag) == nil {
 			t.Errorf("Unpack command missing --%s flag", flag)
@@ -325,7 +337,7 @@ func TestNewListCommand(t *testing.T) {
 	}
 
 	// Check for required flags
-	expectedFlags := []string{"password-env", "file", "all", "verbose", "sizes", "dates", "checksums", "pattern", "sort", "reverse", "format", "limit"}
+	expectedFlags := []string{"password-env", "password-file", "password-fd", "password-stdin", "password-cmd", "file", "all", "verbose", "sizes", "dates", "checksums", "pattern", "sort", "reverse", "format", "limit"}
 	for _, flag := range expectedFlags {
 		if cmd.Flags().Lookup(flag) == nil {
 			t.Errorf("List command missing --%s flag", flag)
@@ -353,6 +365,223 @@ func TestNewStatusCommand(t *testing.T) {
 	}
 }
 
+func TestParsePassgenOpts(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{"defaults", nil, false},
+		{"charset", []string{"--mode", "charset", "--length", "32"}, false},
+		{"unknown mode", []string{"--mode", "emoji"}, true},
+		{"too few words", []string{"--words", "2"}, true},
+		{"too short", []string{"--mode", "charset", "--length", "4"}, true},
+		{"short length ignored in diceware mode", []string{"--length", "4"}, false},
+		{"zero count", []string{"--count", "0"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newPassgenCommand()
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags() error = %v", err)
+			}
+			opts, err := parsePassgenOpts(cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parsePassgenOpts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if exitCode(err) != ExitUsage {
+					t.Errorf("parsePassgenOpts() exit code = %d, want %d", exitCode(err), ExitUsage)
+				}
+				return
+			}
+			if _, _, genErr := generatePassword(opts); genErr != nil {
+				t.Errorf("generatePassword() error = %v", genErr)
+			}
+		})
+	}
+}
+
+func TestAgentPasswordWithoutAgent(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-agent-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	t.Setenv("GOINGENV_AGENT_SOCK", filepath.Join(tmpDir, "agent.sock"))
+
+	app, err := NewApp()
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	if key, ok := agentPassword(app); ok || key != "" {
+		t.Errorf("agentPassword() = %q, %v; want no password without an agent", key, ok)
+	}
+}
+
+func TestCollectShares(t *testing.T) {
+	split, err := crypto.SplitSecret([]byte("correct horse battery staple"), 3, 2)
+	if err != nil {
+		t.Fatalf("SplitSecret() error = %v", err)
+	}
+	one, two := split[0].Encode(), split[1].Encode()
+
+	tests := []struct {
+		name    string
+		args    []string
+		input   string
+		want    int
+		wantErr bool
+	}{
+		{"arguments", []string{one, two}, "", 2, false},
+		{"bad argument", []string{one, "GE1-nope"}, "", 0, true},
+		{"stdin with share file headers", nil, "goingenv recovery share 1 of 3\n" + one + "\n\n" + two + "\n", 2, false},
+		{"stdin stops at threshold", nil, one + "\n" + two + "\n" + split[2].Encode() + "\n", 2, false},
+		{"empty stdin", nil, "", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := collectShares(tt.args, strings.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("collectShares() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(shares) != tt.want {
+				t.Errorf("collectShares() returned %d shares, want %d", len(shares), tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRecoverOpts(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr bool
+	}{
+		{"print", []string{"--print"}, false},
+		{"rekey with new password source", []string{"--rekey", "--password-env", "NEW"}, false},
+		{"no action", nil, true},
+		{"two actions", []string{"--print", "--unlock"}, true},
+		{"password flag without rekey", []string{"--print", "--password-env", "NEW"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newRecoverCommand()
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags() error = %v", err)
+			}
+			_, err := parseRecoverOpts(cmd, []string{"GE1-share"})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("parseRecoverOpts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckArchiveSignature(t *testing.T) {
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	tmpDir, err := os.MkdirTemp("", "goingenv-cli-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer func() {
+		_ = os.Chdir(originalDir) //nolint:errcheck // cleanup in defer
+		_ = os.RemoveAll(tmpDir)
+	}()
+	if chdirErr := os.Chdir(tmpDir); chdirErr != nil {
+		t.Fatalf("Failed to change directory: %v", chdirErr)
+	}
+	if mkdirErr := os.Mkdir(".goingenv", 0o700); mkdirErr != nil {
+		t.Fatalf("Failed to create .goingenv: %v", mkdirErr)
+	}
+
+	alice, err := signing.GenerateKey("alice")
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	stranger, err := signing.GenerateKey("alice")
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		policy      string
+		key         *signing.Key
+		trustAlice  bool
+		wantSigner  string
+		wantErr     error
+		wantWarning bool
+	}{
+		{name: "no signers, unsigned", policy: types.SignatureWarn},
+		{name: "trusted signer", policy: types.SignatureRequire, key: alice, trustAlice: true, wantSigner: "alice"},
+		{name: "unsigned warns once signers exist", policy: types.SignatureWarn, trustAlice: true, wantWarning: true},
+		{name: "unknown signer warns", policy: types.SignatureWarn, key: stranger, trustAlice: true, wantWarning: true},
+		{name: "unknown signer refused", policy: types.SignatureRequire, key: stranger, trustAlice: true, wantErr: types.ErrUntrustedSigner},
+		{name: "unsigned refused", policy: types.SignatureRequire, wantErr: types.ErrUntrustedSigner},
+		{name: "off skips checks", policy: types.SignatureOff, key: stranger, trustAlice: true},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			archivePath := filepath.Join(".goingenv", fmt.Sprintf("archive-%d.enc", i))
+			if writeErr := os.WriteFile(archivePath, []byte("ciphertext"), 0o600); writeErr != nil {
+				t.Fatalf("Failed to write archive: %v", writeErr)
+			}
+			if tt.key != nil {
+				if _, signErr := signing.SignArchive(archivePath, tt.key); signErr != nil {
+					t.Fatalf("SignArchive() error = %v", signErr)
+				}
+			}
+			var signers []signing.Signer
+			if tt.trustAlice {
+				signers = []signing.Signer{alice.Signer()}
+			}
+			if saveErr := signing.SaveSigners(signersPath(), signers); saveErr != nil {
+				t.Fatalf("SaveSigners() error = %v", saveErr)
+			}
+
+			var stdout, stderr bytes.Buffer
+			out := NewOutputWithWriter(&stdout, &stderr, false, "test")
+			app := &types.App{Config: &types.Config{SignaturePolicy: tt.policy}}
+
+			signer, err := checkArchiveSignature(out, app, archivePath)
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("checkArchiveSignature() error = %v, want %v", err, tt.wantErr)
+			}
+			if signer != tt.wantSigner {
+				t.Errorf("checkArchiveSignature() signer = %q, want %q", signer, tt.wantSigner)
+			}
+			if got := strings.Contains(stdout.String(), "[!]"); got != tt.wantWarning {
+				t.Errorf("warning printed = %v, want %v; output %q", got, tt.wantWarning, stdout.String())
+			}
+		})
+	}
+
+	// Any change to a signed archive is refused regardless of trust
+	archivePath := filepath.Join(".goingenv", "tampered.enc")
+	if writeErr := os.WriteFile(archivePath, []byte("ciphertext"), 0o600); writeErr != nil {
+		t.Fatalf("Failed to write archive: %v", writeErr)
+	}
+	if _, signErr := signing.SignArchive(archivePath, alice); signErr != nil {
+		t.Fatalf("SignArchive() error = %v", signErr)
+	}
+	if writeErr := os.WriteFile(archivePath, []byte("ciphertexT"), 0o600); writeErr != nil {
+		t.Fatalf("Failed to write archive: %v", writeErr)
+	}
+	out := NewOutputWithWriter(&bytes.Buffer{}, &bytes.Buffer{}, false, "test")
+	app := &types.App{Config: &types.Config{SignaturePolicy: types.SignatureWarn}}
+	if _, err := checkArchiveSignature(out, app, archivePath); !errors.Is(err, types.ErrBadSignature) {
+		t.Errorf("checkArchiveSignature() of a modified archive error = %v, want ErrBadSignature", err)
+	}
+}
+
 func TestNewApp(t *testing.T) {
 	// Save and change to temp directory
 	originalDir, err := os.Getwd()
@@ -433,3 +662,595 @@ func TestDisplayFilesCSV(t *testing.T) {
 	// Ensure no panic
 	displayFilesCSV(files)
 }
+
+func TestApplyPackProfile(t *testing.T) {
+	cfg := &types.Config{
+		Profiles: map[string]types.Profile{
+			"prod": {
+				EnvPatterns:     []string{`\.env\.prod$`},
+				ExcludePatterns: []string{`fixtures/`},
+				ArchivePrefix:   "production",
+				PasswordEnv:     "PROD_PASSWORD",
+			},
+		},
+	}
+
+	opts := &PackOpts{Profile: "prod", Exclude: []string{`tmp/`}, Output: "custom.enc"}
+	if err := applyPackProfile(opts, cfg, false); err != nil {
+		t.Fatalf("applyPackProfile() error = %v", err)
+	}
+	if len(opts.Include) != 1 || opts.Include[0] != `\.env\.prod$` {
+		t.Errorf("Include = %v, want profile patterns", opts.Include)
+	}
+	if len(opts.Exclude) != 2 {
+		t.Errorf("Exclude = %v, want flag and profile patterns", opts.Exclude)
+	}
+	if opts.Password.PasswordEnv != "PROD_PASSWORD" {
+		t.Errorf("PasswordEnv = %s, want PROD_PASSWORD", opts.Password.PasswordEnv)
+	}
+	if !strings.HasPrefix(filepath.Base(opts.Output), "production-") {
+		t.Errorf("Output = %s, want production-<timestamp>.enc", opts.Output)
+	}
+
+	// Explicit flags win over the profile
+	opts = &PackOpts{Profile: "prod", Include: []string{`\.env$`}, Password: password.Options{PasswordEnv: "OTHER"}, Output: "custom.enc"}
+	if err := applyPackProfile(opts, cfg, true); err != nil {
+		t.Fatalf("applyPackProfile() error = %v", err)
+	}
+	if opts.Include[0] != `\.env$` || opts.Password.PasswordEnv != "OTHER" || opts.Output != "custom.enc" {
+		t.Errorf("flags were overridden by profile: %+v", opts)
+	}
+
+	if err := applyPackProfile(&PackOpts{Profile: "staging"}, cfg, false); err == nil {
+		t.Error("applyPackProfile() should fail for an unknown profile")
+	}
+
+	// Any password flag replaces all of the profile's password sources
+	cfg.Profiles["ci"] = types.Profile{PasswordFile: "/run/secrets/goingenv", PasswordEnv: "GOINGENV_PASSWORD"}
+	opts = &PackOpts{Profile: "ci"}
+	if err := applyPackProfile(opts, cfg, false); err != nil {
+		t.Fatalf("applyPackProfile() error = %v", err)
+	}
+	if opts.Password.PasswordFile != "/run/secrets/goingenv" || opts.Password.PasswordEnv != "GOINGENV_PASSWORD" {
+		t.Errorf("Password = %+v, want the profile's file and env fallback", opts.Password)
+	}
+	opts = &PackOpts{Profile: "ci", Password: password.Options{PasswordStdin: true}}
+	if err := applyPackProfile(opts, cfg, false); err != nil {
+		t.Fatalf("applyPackProfile() error = %v", err)
+	}
+	if opts.Password.PasswordFile != "" || opts.Password.PasswordEnv != "" {
+		t.Errorf("Password = %+v, want only --password-stdin", opts.Password)
+	}
+}
+
+func TestGroupArchivesByProfile(t *testing.T) {
+	cfg := &types.Config{
+		Profiles: map[string]types.Profile{
+			"prod":    {},
+			"prod-eu": {},
+			"dev":     {ArchivePrefix: "development"},
+		},
+	}
+	archives := []string{
+		"/p/.goingenv/prod-20260101-120000.enc",
+		"/p/.goingenv/prod-eu-20260101-120000.enc",
+		"/p/.goingenv/development-20260101-120000.enc",
+		"/p/.goingenv/dev-20260101-120000.enc",
+		"/p/.goingenv/archive-20260101-120000.enc",
+		"/p/.goingenv/prod-backup.enc",
+	}
+
+	groups := groupArchivesByProfile(cfg, archives)
+	want := map[string]int{"prod": 1, "prod-eu": 1, "dev": 1, "": 3}
+	for name, count := range want {
+		if len(groups[name]) != count {
+			t.Errorf("group %q = %v, want %d archives", name, groups[name], count)
+		}
+	}
+}
+
+func TestVerifyArchive(t *testing.T) {
+	app := &types.App{Config: &types.Config{SignaturePolicy: types.SignatureOff}}
+
+	tests := []struct {
+		name    string
+		result  *types.VerifyResult
+		err     error
+		wantOK  bool
+		wantErr error
+	}{
+		{name: "intact", result: &types.VerifyResult{Archive: &types.Archive{}, Entries: 2}, wantOK: true},
+		{name: "problems", result: &types.VerifyResult{Archive: &types.Archive{}, Problems: []string{".env: checksum mismatch"}}, wantErr: types.ErrIntegrity},
+		{name: "wrong password", err: fmt.Errorf("decrypt: %w", types.ErrWrongPassword), wantErr: types.ErrWrongPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app.Archiver = &types.MockArchiver{
+				VerifyFunc: func(string, string) (*types.VerifyResult, error) { return tt.result, tt.err },
+			}
+
+			verified, err := verifyArchive(app, "backup.enc", "password", nil)
+			if verified.OK != tt.wantOK {
+				t.Errorf("verifyArchive() ok = %v, want %v", verified.OK, tt.wantOK)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("verifyArchive() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPruneSeries(t *testing.T) {
+	archives := []string{
+		".goingenv/archive-20240101-120000.enc",
+		".goingenv/archive-20240102-120000.enc",
+		".goingenv/prod-20240101-120000.enc",
+		".goingenv/backup.enc",
+	}
+	cfg := &types.Config{
+		Profiles: map[string]types.Profile{"prod": {Retention: &types.Retention{KeepLast: 5}}},
+	}
+	app := &types.App{
+		Config: cfg,
+		Archiver: &types.MockArchiver{
+			GetAvailableArchivesFunc: func(string) ([]string, error) { return archives, nil },
+		},
+	}
+
+	tests := []struct {
+		name       string
+		profile    string
+		flags      *types.Retention
+		defaults   *types.Retention
+		wantSeries map[string]int // series name -> keep_last of its policy
+	}{
+		{name: "profile rules only", wantSeries: map[string]int{"prod": 5}},
+		{name: "defaults fill in", defaults: &types.Retention{KeepLast: 2},
+			wantSeries: map[string]int{"archive": 2, "backup.enc": 2, "prod": 5}},
+		{name: "flags override", flags: &types.Retention{KeepLast: 1},
+			wantSeries: map[string]int{"archive": 1, "backup.enc": 1, "prod": 1}},
+		{name: "profile filter", profile: "prod", defaults: &types.Retention{KeepLast: 2}, wantSeries: map[string]int{"prod": 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg.Retention = tt.defaults
+			series, err := pruneSeries(app, tt.profile, tt.flags)
+			if err != nil {
+				t.Fatalf("pruneSeries() error = %v", err)
+			}
+			got := make(map[string]int)
+			for _, s := range series {
+				got[s.Name] = s.Policy.KeepLast
+			}
+			if len(got) != len(tt.wantSeries) {
+				t.Fatalf("pruneSeries() = %v, want %v", got, tt.wantSeries)
+			}
+			for name, keep := range tt.wantSeries {
+				if got[name] != keep {
+					t.Errorf("pruneSeries() series %s keep_last = %d, want %d", name, got[name], keep)
+				}
+			}
+		})
+	}
+
+	cfg.Retention, cfg.Profiles = nil, nil
+	if _, err := pruneSeries(app, "", nil); !isType[*usageError](err) {
+		t.Errorf("pruneSeries() without rules error = %v, want a usage error", err)
+	}
+}
+
+func TestTaggedArchive(t *testing.T) {
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+
+	tmpDir, err := os.MkdirTemp("", "goingenv-cli-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer func() {
+		_ = os.Chdir(originalDir) //nolint:errcheck // cleanup in defer
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	if chdirErr := os.Chdir(tmpDir); chdirErr != nil {
+		t.Fatalf("Failed to change directory: %v", chdirErr)
+	}
+	if mkdirErr := os.Mkdir(".goingenv", 0o700); mkdirErr != nil {
+		t.Fatalf("Failed to create .goingenv: %v", mkdirErr)
+	}
+
+	now := time.Now().UTC()
+	err = catalog.Record(catalogPath(),
+		catalog.Entry{Archive: "old.enc", Tags: []string{"release-42"}, CreatedAt: now.Add(-time.Hour)},
+		catalog.Entry{Archive: "new.enc", Tags: []string{"release-42"}, CreatedAt: now},
+		catalog.Entry{Archive: "gone.enc", Tags: []string{"stale"}, CreatedAt: now},
+	)
+	if err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	archives := []string{filepath.Join(".goingenv", "old.enc"), filepath.Join(".goingenv", "new.enc")}
+	app := &types.App{
+		Archiver: &types.MockArchiver{
+			GetAvailableArchivesFunc: func(string) ([]string, error) { return archives, nil },
+		},
+	}
+
+	tests := []struct {
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		{tag: "release-42", want: archives[1]},
+		{tag: "stale", wantErr: true},
+		{tag: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := taggedArchive(app, tt.tag)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("taggedArchive(%q) error = %v, wantErr %v", tt.tag, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr && !errors.Is(err, types.ErrArchiveNotFound) {
+			t.Errorf("taggedArchive(%q) error = %v, want ErrArchiveNotFound", tt.tag, err)
+		}
+		if got != tt.want {
+			t.Errorf("taggedArchive(%q) = %s, want %s", tt.tag, got, tt.want)
+		}
+	}
+}
+
+func TestPickArchive(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-cli-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// The directory listing order is not the creation order
+	archives := []string{
+		filepath.Join(tmpDir, "prod-20240301-120000.enc"),
+		filepath.Join(tmpDir, "archive-20240101-120000.enc"),
+		filepath.Join(tmpDir, "archive-20240201-120000.enc"),
+	}
+	for _, archivePath := range archives {
+		if writeErr := os.WriteFile(archivePath, []byte(archivePath), 0o600); writeErr != nil {
+			t.Fatalf("Failed to write archive: %v", writeErr)
+		}
+	}
+	app := &types.App{
+		Archiver: &types.MockArchiver{
+			GetAvailableArchivesFunc: func(string) ([]string, error) { return archives, nil },
+		},
+	}
+
+	tests := []struct {
+		ref     string
+		want    string
+		wantErr bool
+	}{
+		{ref: "", want: archives[0]},
+		{ref: "latest~1", want: archives[2]},
+		{ref: "@2024-01-31", want: archives[1]},
+		{ref: archives[1], want: archives[1]},
+		{ref: "archive-20240201-120000.enc", want: archives[2]},
+		{ref: "missing.enc", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := pickArchive(app, tt.ref)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("pickArchive(%q) error = %v, wantErr %v", tt.ref, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("pickArchive(%q) = %s, want %s", tt.ref, got, tt.want)
+		}
+	}
+}
+
+func TestLatestProfileArchive(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-cli-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// The directory listing order is not the creation order
+	archives := []string{
+		filepath.Join(tmpDir, "prod-20240301-120000.enc"),
+		filepath.Join(tmpDir, "prod-20240401-120000.enc"),
+		filepath.Join(tmpDir, "prod-20240201-120000.enc"),
+		filepath.Join(tmpDir, "archive-20240501-120000.enc"),
+	}
+	app := &types.App{
+		Config: &types.Config{Profiles: map[string]types.Profile{"prod": {}}},
+		Archiver: &types.MockArchiver{
+			GetAvailableArchivesFunc: func(string) ([]string, error) { return archives, nil },
+		},
+	}
+
+	got, err := latestProfileArchive(app, "prod")
+	if err != nil {
+		t.Fatalf("latestProfileArchive() error = %v", err)
+	}
+	if got != archives[1] {
+		t.Errorf("latestProfileArchive() = %s, want %s", got, archives[1])
+	}
+	if _, err := latestProfileArchive(app, "staging"); !errors.Is(err, types.ErrArchiveNotFound) {
+		t.Errorf("latestProfileArchive() of a profile without archives error = %v, want ErrArchiveNotFound", err)
+	}
+}
+
+func TestArchiveRelPath(t *testing.T) {
+	tests := []struct {
+		dir     string
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{dir: ".", path: ".env", want: ".env"},
+		{dir: ".", path: "api/.env.local", want: filepath.Join("api", ".env.local")},
+		{dir: "api", path: "api/.env", want: ".env"},
+		{dir: "api", path: ".env", wantErr: true},
+		{dir: ".", path: "../.env", wantErr: true},
+		{dir: ".", path: ".", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := archiveRelPath(tt.dir, tt.path)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("archiveRelPath(%q, %q) error = %v, wantErr %v", tt.dir, tt.path, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("archiveRelPath(%q, %q) = %q, want %q", tt.dir, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestPlanUpdate(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-cli-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	write := func(name, content string) {
+		if writeErr := os.WriteFile(filepath.Join(tmpDir, name), []byte(content), 0o600); writeErr != nil {
+			t.Fatalf("Failed to write %s: %v", name, writeErr)
+		}
+	}
+	write(".env", "A=1")
+	write(".env.test", "T=2")
+	if linkErr := os.Symlink(".env", filepath.Join(tmpDir, ".env.local")); linkErr != nil {
+		t.Fatalf("Failed to create link: %v", linkErr)
+	}
+
+	checksum := func(s string) string {
+		return fmt.Sprintf("%x", sha256.Sum256([]byte(s)))
+	}
+	archive := &types.Archive{
+		Files: []types.EnvFile{
+			{RelativePath: ".env", Size: 3, Checksum: checksum("A=1")},
+			{RelativePath: ".env.test", Size: 3, Checksum: checksum("T=1")},
+			{RelativePath: ".env.local", LinkTarget: ".env", Checksum: checksum(".env")},
+			{RelativePath: ".env.gone", Size: 3, Checksum: checksum("G=1")},
+		},
+		TotalSize: 9,
+	}
+
+	cmd := newUpdateCommand()
+	if setErr := cmd.Flags().Set("directory", tmpDir); setErr != nil {
+		t.Fatalf("Failed to set directory: %v", setErr)
+	}
+
+	out := NewOutput("test")
+	opts, err := planUpdate(out, nil, cmd, nil, archive)
+	if err != nil {
+		t.Fatalf("planUpdate() error = %v", err)
+	}
+	if len(opts.Put) != 1 || opts.Put[0].RelativePath != ".env.test" || opts.Put[0].Checksum != checksum("T=2") {
+		t.Fatalf("planUpdate() put = %+v, want only the changed .env.test", opts.Put)
+	}
+
+	res := newEditResult("test.enc", archive, opts)
+	if len(res.Replaced) != 1 || len(res.Added) != 0 || res.Files != 4 || res.TotalSize != 9 {
+		t.Errorf("newEditResult() = %+v, want one replaced file and unchanged totals", res)
+	}
+}
+
+func TestTextconvCommand(t *testing.T) {
+	tests := []struct {
+		rel         string
+		passwordEnv string
+		want        string
+	}{
+		{rel: ".", want: "goingenv git-textconv"},
+		{rel: "services/api", passwordEnv: "API_PASSWORD", want: "goingenv git-textconv --project services/api --password-env API_PASSWORD"},
+		{rel: "my project", want: "goingenv git-textconv --project 'my project'"},
+		{rel: "it's", want: `goingenv git-textconv --project 'it'\''s'`},
+	}
+
+	for _, tt := range tests {
+		if got := textconvCommand(tt.rel, tt.passwordEnv); got != tt.want {
+			t.Errorf("textconvCommand(%q, %q) = %q, want %q", tt.rel, tt.passwordEnv, got, tt.want)
+		}
+	}
+}
+
+func TestParseValidityFlags(t *testing.T) {
+	now := time.Date(2026, 6, 15, 12, 0, 0, 0, time.Local)
+	endOfYear := time.Date(2027, 1, 1, 0, 0, 0, 0, time.Local)
+	startOfJuly := time.Date(2026, 7, 1, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name          string
+		args          []string
+		wantNotBefore *time.Time
+		wantExpires   *time.Time
+		wantErr       bool
+	}{
+		{name: "no validity period"},
+		{name: "date expires at the end of the day", args: []string{"--expires", "2026-12-31"}, wantExpires: &endOfYear},
+		{name: "date is valid from the start of the day", args: []string{"--not-before", "2026-07-01"}, wantNotBefore: &startOfJuly},
+		{name: "age counts from now", args: []string{"--expires", "30d"}, wantExpires: ptrTime(now.AddDate(0, 0, 30))},
+		{name: "exact time", args: []string{"--expires", "2026-06-15 18:30:00"}, wantExpires: ptrTime(time.Date(2026, 6, 15, 18, 30, 0, 0, time.Local))},
+		{name: "expires in the past", args: []string{"--expires", "2026-01-01"}, wantErr: true},
+		{name: "expires before not-before", args: []string{"--not-before", "2026-12-31", "--expires", "2026-07-01"}, wantErr: true},
+		{name: "unparsable", args: []string{"--expires", "next tuesday"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := newPackCommand()
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags() error = %v", err)
+			}
+			notBefore, expiresAt, err := parseValidityFlags(cmd, now)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseValidityFlags() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if exitCode(err) != ExitUsage {
+					t.Errorf("parseValidityFlags() exit code = %d, want %d", exitCode(err), ExitUsage)
+				}
+				return
+			}
+			if !sameTime(notBefore, tt.wantNotBefore) {
+				t.Errorf("parseValidityFlags() notBefore = %v, want %v", notBefore, tt.wantNotBefore)
+			}
+			if !sameTime(expiresAt, tt.wantExpires) {
+				t.Errorf("parseValidityFlags() expiresAt = %v, want %v", expiresAt, tt.wantExpires)
+			}
+		})
+	}
+}
+
+func ptrTime(t time.Time) *time.Time {
+	return &t
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func TestMergeSignedArchive(t *testing.T) {
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("Failed to get current directory: %v", err)
+	}
+	tmpDir, err := os.MkdirTemp("", "goingenv-cli-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer func() {
+		_ = os.Chdir(originalDir) //nolint:errcheck // cleanup in defer
+		_ = os.RemoveAll(tmpDir)
+	}()
+	tmpDir, err = filepath.EvalSymlinks(tmpDir)
+	if err != nil {
+		t.Fatalf("Failed to resolve temp dir: %v", err)
+	}
+	if chdirErr := os.Chdir(tmpDir); chdirErr != nil {
+		t.Fatalf("Failed to change directory: %v", chdirErr)
+	}
+	t.Setenv("HOME", tmpDir)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmpDir, "config"))
+	t.Setenv("XDG_STATE_HOME", filepath.Join(tmpDir, "state"))
+	t.Setenv("GOINGENV_MERGE_TEST_PASSWORD", "testpassword123")
+	if initErr := config.InitializeProject(); initErr != nil {
+		t.Fatalf("InitializeProject() error = %v", initErr)
+	}
+
+	app, err := NewApp()
+	if err != nil {
+		t.Fatalf("NewApp() error = %v", err)
+	}
+	// .env conflicts; api.env changes on their side only, so the merge
+	// rewrites our archive
+	pack := func(name, env, api string) string {
+		dir := filepath.Join(tmpDir, "src-"+name)
+		var files []types.EnvFile
+		for rel, content := range map[string]string{".env": env, "api.env": api} {
+			path := filepath.Join(dir, rel)
+			if writeErr := os.MkdirAll(dir, 0o700); writeErr != nil {
+				t.Fatalf("Failed to create %s: %v", dir, writeErr)
+			}
+			if writeErr := os.WriteFile(path, []byte(content), 0o600); writeErr != nil {
+				t.Fatalf("Failed to write %s: %v", path, writeErr)
+			}
+			files = append(files, types.EnvFile{
+				Path: path, RelativePath: rel, Size: int64(len(content)),
+				Checksum: fmt.Sprintf("%x", sha256.Sum256([]byte(content))),
+			})
+		}
+		archivePath := filepath.Join(tmpDir, ".goingenv", name+".enc")
+		if packErr := app.Archiver.Pack(types.PackOptions{Files: files, OutputPath: archivePath, Password: "testpassword123"}); packErr != nil {
+			t.Fatalf("Pack() error = %v", packErr)
+		}
+		return archivePath
+	}
+	base := pack("base", "A=1\n", "K=1\n")
+	theirs := pack("theirs", "A=3\n", "K=2\n")
+	ours := pack("prod", "A=2\n", "K=1\n")
+
+	key, err := signing.GenerateKey("alice")
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	if _, signErr := signing.SignArchive(ours, key); signErr != nil {
+		t.Fatalf("SignArchive() error = %v", signErr)
+	}
+	if saveErr := signing.SaveSigners(signersPath(), []signing.Signer{key.Signer()}); saveErr != nil {
+		t.Fatalf("SaveSigners() error = %v", saveErr)
+	}
+
+	run := func(args ...string) error {
+		cmd := NewRootCommand("test")
+		cmd.SetArgs(append(args, "--password-env", "GOINGENV_MERGE_TEST_PASSWORD"))
+		return cmd.Execute()
+	}
+	if err := run("git-merge", base, ours, theirs, ours); !errors.Is(err, types.ErrConflict) {
+		t.Fatalf("git-merge error = %v, want ErrConflict", err)
+	}
+	if _, statErr := os.Stat(signing.SignaturePath(ours)); !os.IsNotExist(statErr) {
+		t.Fatal("git-merge kept the signature of the rewritten archive")
+	}
+
+	side := filepath.Join(git.ConflictDir(ours), ".env")
+	if writeErr := os.WriteFile(side, []byte("A=4\n"), 0o600); writeErr != nil {
+		t.Fatalf("Failed to resolve %s: %v", side, writeErr)
+	}
+	if err := run("resolve", "-f", ours); err != nil {
+		t.Fatalf("resolve error = %v", err)
+	}
+
+	_, contents, err := app.Archiver.Read(ours, "testpassword123")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if got := string(contents[".env"]) + string(contents["api.env"]); got != "A=4\nK=2\n" {
+		t.Errorf("merged archive holds %q, want %q", got, "A=4\nK=2\n")
+	}
+
+	entries, problems, err := audit.Verify(audit.ProjectLog(".goingenv"))
+	if err != nil || len(problems) > 0 {
+		t.Fatalf("audit.Verify() = %v, %v", problems, err)
+	}
+	var ops []string
+	for _, e := range entries {
+		ops = append(ops, e.Operation+" "+e.Archive)
+	}
+	if want := []string{"merge .goingenv/prod.enc", "update " + ours}; strings.Join(ops, ", ") != strings.Join(want, ", ") {
+		t.Errorf("audit log = %q, want %q", ops, want)
+	}
+}
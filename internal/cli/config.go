@@ -0,0 +1,481 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"goingenv/internal/config"
+	"goingenv/pkg/types"
+)
+
+// newConfigCommand creates the config command
+func newConfigCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect and edit goingenv configuration",
+		Long: `Inspect and edit goingenv configuration.
+
+Settings are layered, later layers overriding earlier ones:
+  1. Built-in defaults
+  2. User config (~/.goingenv.json)
+  3. Project config (.goingenv/config.json, safe to commit)
+  4. Environment variables (GOINGENV_DEPTH, GOINGENV_MAX_FILE_SIZE, ...)
+  5. Command-line flags such as --depth
+
+Editing commands write the user config unless --project is given.
+
+Examples:
+  goingenv config show --origin
+  goingenv config get default_depth
+  goingenv config set max_file_size 5242880
+  goingenv config add env_patterns '\.secrets$'
+  goingenv config remove exclude_patterns 'dist/'
+  goingenv config reset default_depth --project
+  goingenv config validate
+  goingenv config migrate --dry-run
+  goingenv config schema > .goingenv/config.schema.json`,
+	}
+
+	cmd.AddCommand(newConfigShowCommand())
+	cmd.AddCommand(newConfigGetCommand())
+	cmd.AddCommand(newConfigEditCommand("set <key> <value>...", "Set a setting",
+		"Replace a setting's value. List settings take one argument per element.",
+		cobra.MinimumNArgs(1), (*config.Editor).Set))
+	cmd.AddCommand(newConfigEditCommand("add <key> <value>...", "Add values to a list setting",
+		"Append values to a list setting such as env_patterns. Values already present are skipped.",
+		cobra.MinimumNArgs(2), (*config.Editor).Add))
+	cmd.AddCommand(newConfigEditCommand("remove <key> <value>...", "Remove values from a list setting",
+		"Remove values from a list setting such as exclude_patterns.",
+		cobra.MinimumNArgs(2), (*config.Editor).Remove))
+	cmd.AddCommand(newConfigResetCommand())
+	cmd.AddCommand(newConfigValidateCommand())
+	cmd.AddCommand(newConfigMigrateCommand())
+	cmd.AddCommand(newConfigSchemaCommand())
+
+	return cmd
+}
+
+// configValueResult is one setting in a config result document
+type configValueResult struct {
+	Key    string      `json:"key"`
+	Value  interface{} `json:"value"`
+	Origin string      `json:"origin,omitempty"`
+}
+
+// configProblemResult is one validation problem in a config result document
+type configProblemResult struct {
+	Key     string `json:"key"`
+	Message string `json:"message"`
+	Origin  string `json:"origin,omitempty"`
+}
+
+// configMigrateResult is one file in the config migrate result document
+type configMigrateResult struct {
+	Path    string   `json:"path"`
+	From    int      `json:"from"`
+	To      int      `json:"to"`
+	Changed bool     `json:"changed"`
+	Steps   []string `json:"steps,omitempty"`
+	Removed []string `json:"removed,omitempty"`
+}
+
+// newConfigShowCommand creates the config show subcommand
+func newConfigShowCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show effective configuration values",
+		Long: `Show the effective value of every setting.
+
+Examples:
+  goingenv config show
+  goingenv config show --origin`,
+		Args: cobra.NoArgs,
+		RunE: runConfigShowCommand,
+	}
+
+	cmd.Flags().Bool("origin", false, "Show which layer each value came from")
+
+	return cmd
+}
+
+// runConfigShowCommand executes the config show subcommand
+func runConfigShowCommand(cmd *cobra.Command, _ []string) error {
+	out := NewOutput(appVersion)
+	out.Header()
+	out.Blank()
+
+	showOrigin, err := cmd.Flags().GetBool("origin")
+	if err != nil {
+		return fmt.Errorf("failed to get origin flag: %w", err)
+	}
+
+	mgr := config.NewManager()
+	layered, err := mgr.LoadLayered()
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+
+	out.Section("Configuration")
+	rows := make([][]string, 0, len(config.Settings))
+	values := make([]configValueResult, 0, len(config.Settings))
+	for _, s := range config.Settings {
+		row := []string{s.Key, s.Format(layered.Config)}
+		if showOrigin {
+			row = append(row, layered.Origins[s.Key].String())
+		}
+		rows = append(rows, row)
+		values = append(values, configValueResult{
+			Key:    s.Key,
+			Value:  s.Get(layered.Config),
+			Origin: layered.Origins[s.Key].String(),
+		})
+	}
+	setResultData(map[string]interface{}{"settings": values})
+	out.Table(rows)
+	out.Blank()
+
+	if showOrigin {
+		out.Hint(fmt.Sprintf("User config: %s", mgr.UserConfigPath()))
+		out.Hint(fmt.Sprintf("Project config: %s", mgr.ProjectConfigPath()))
+	}
+
+	return nil
+}
+
+// newConfigGetCommand creates the config get subcommand
+func newConfigGetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print the effective value of a setting",
+		Long: `Print the effective value of a setting. List settings print one
+element per line.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := NewOutput(appVersion)
+
+			setting, err := lookupSetting(args[0])
+			if err != nil {
+				out.Error(err.Error())
+				return err
+			}
+
+			layered, err := config.NewManager().LoadLayered()
+			if err != nil {
+				out.Error(err.Error())
+				return err
+			}
+
+			setResultData(configValueResult{
+				Key:    setting.Key,
+				Value:  setting.Get(layered.Config),
+				Origin: layered.Origins[setting.Key].String(),
+			})
+
+			if setting.IsObject() {
+				data, marshalErr := json.MarshalIndent(setting.Get(layered.Config), "", "  ")
+				if marshalErr != nil {
+					return fmt.Errorf("failed to marshal %s: %w", setting.Key, marshalErr)
+				}
+				out.Print(string(data))
+				return nil
+			}
+			if list, ok := setting.Get(layered.Config).([]string); ok {
+				for _, item := range list {
+					out.Print(item)
+				}
+				return nil
+			}
+			out.Print(fmt.Sprint(setting.Get(layered.Config)))
+			return nil
+		},
+	}
+}
+
+// newConfigEditCommand creates an editing subcommand that applies edit to the
+// target config file
+func newConfigEditCommand(use, short, long string, args cobra.PositionalArgs,
+	edit func(*config.Editor, config.Setting, []string) error) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Long:  long,
+		Args:  args,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := NewOutput(appVersion)
+
+			editor, err := openConfigEditor(cmd)
+			if err != nil {
+				out.Error(err.Error())
+				return err
+			}
+
+			setting, err := lookupSetting(args[0])
+			if err != nil {
+				out.Error(err.Error())
+				return err
+			}
+
+			if err := edit(editor, setting, args[1:]); err != nil {
+				out.Error(configErrorLine(err))
+				return err
+			}
+
+			return saveConfigEditor(out, editor)
+		},
+	}
+
+	cmd.Flags().Bool("project", false, "Edit the project config (.goingenv/config.json)")
+
+	return cmd
+}
+
+// newConfigResetCommand creates the config reset subcommand
+func newConfigResetCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reset [key]...",
+		Short: "Remove settings from a config file",
+		Long: `Remove settings from the target config file so they fall back to the
+layers below (project -> user -> defaults).
+
+Examples:
+  goingenv config reset default_depth
+  goingenv config reset --all --project`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			out := NewOutput(appVersion)
+
+			all, err := cmd.Flags().GetBool("all")
+			if err != nil {
+				return fmt.Errorf("failed to get all flag: %w", err)
+			}
+			if all == (len(args) > 0) {
+				err = newUsageError("specify either setting keys or --all")
+				out.Error(err.Error())
+				return err
+			}
+
+			editor, err := openConfigEditor(cmd)
+			if err != nil {
+				out.Error(err.Error())
+				return err
+			}
+
+			if all {
+				editor.ResetAll()
+			}
+			for _, key := range args {
+				setting, lookupErr := lookupSetting(key)
+				if lookupErr != nil {
+					out.Error(lookupErr.Error())
+					return lookupErr
+				}
+				editor.Reset(setting)
+			}
+
+			return saveConfigEditor(out, editor)
+		},
+	}
+
+	cmd.Flags().Bool("project", false, "Reset settings in the project config (.goingenv/config.json)")
+	cmd.Flags().Bool("all", false, "Remove every setting from the file")
+
+	return cmd
+}
+
+// newConfigValidateCommand creates the config validate subcommand
+func newConfigValidateCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "validate",
+		Short: "Check the effective configuration for errors",
+		Long: `Check every config layer and report all problems, including regular
+expressions that do not compile.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := NewOutput(appVersion)
+			out.Header()
+			out.Blank()
+
+			mgr := config.NewManager()
+			layered, err := mgr.LoadUnvalidated()
+			if err != nil {
+				out.Error(configErrorLine(err))
+				return err
+			}
+
+			problems := mgr.ValidateAll(layered.Config)
+			results := make([]configProblemResult, 0, len(problems))
+			setResultData(map[string]interface{}{"valid": len(problems) == 0, "problems": &results})
+			if len(problems) == 0 {
+				out.Success("Configuration is valid")
+				return nil
+			}
+
+			for _, problem := range problems {
+				out.Error(configErrorLine(problem))
+				res := configProblemResult{Key: problem.Field, Message: problem.Message}
+				if setting, ok := config.LookupSetting(problem.Field); ok {
+					res.Key, res.Origin = setting.Key, layered.Origins[setting.Key].String()
+					out.Indent(fmt.Sprintf("set by %s", layered.Origins[setting.Key]))
+				}
+				results = append(results, res)
+			}
+			out.Blank()
+			out.Hint("Fix with 'goingenv config set|remove|reset <key>'")
+
+			return &types.ValidationError{
+				Field:   "configuration",
+				Value:   len(problems),
+				Message: fmt.Sprintf("%d problems found", len(problems)),
+			}
+		},
+	}
+}
+
+// newConfigMigrateCommand creates the config migrate subcommand
+func newConfigMigrateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Upgrade config files to the current schema version",
+		Long: `Upgrade the user and project config files to the current schema version.
+
+Older files are already upgraded in memory whenever they are loaded; this
+command rewrites them on disk. Unknown keys are preserved.
+
+Examples:
+  goingenv config migrate --dry-run
+  goingenv config migrate`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			out := NewOutput(appVersion)
+			out.Header()
+			out.Blank()
+
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return fmt.Errorf("failed to get dry-run flag: %w", err)
+			}
+
+			reports, err := config.NewManager().Migrate(dryRun)
+			results := make([]configMigrateResult, 0, len(reports))
+			for _, report := range reports {
+				results = append(results, configMigrateResult{
+					Path:    report.Path,
+					From:    report.From,
+					To:      report.To,
+					Changed: report.Changed(),
+					Steps:   report.Steps,
+					Removed: report.Removed,
+				})
+			}
+			setResultData(map[string]interface{}{"dry_run": dryRun, "files": results})
+
+			for _, report := range reports {
+				if !report.Changed() {
+					out.Skipped(fmt.Sprintf("%s is up to date (version %d)", report.Path, report.To))
+					continue
+				}
+				verb := "Migrated"
+				if dryRun {
+					verb = "Would migrate"
+				}
+				out.Success(fmt.Sprintf("%s %s from version %d to %d", verb, report.Path, report.From, report.To))
+				for _, step := range report.Steps {
+					out.Indent(step)
+				}
+				if len(report.Removed) > 0 {
+					out.Indent("removes: " + strings.Join(report.Removed, ", "))
+				}
+			}
+			if err != nil {
+				out.Error(err.Error())
+				return err
+			}
+			if len(reports) == 0 {
+				out.Skipped("No config files found")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().Bool("dry-run", false, "Show what would change without writing")
+
+	return cmd
+}
+
+// newConfigSchemaCommand creates the config schema subcommand
+func newConfigSchemaCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "schema",
+		Short: "Print the JSON Schema for config files",
+		Long: `Print the JSON Schema for config files. Save it next to your config and
+reference it with a "$schema" key for editor completion and validation.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if jsonOutput() {
+				setResultData(json.RawMessage(config.Schema))
+				return nil
+			}
+			_, err := cmd.OutOrStdout().Write(config.Schema)
+			return err
+		},
+	}
+}
+
+// openConfigEditor opens the user config, or the project config with --project
+func openConfigEditor(cmd *cobra.Command) (*config.Editor, error) {
+	project, err := cmd.Flags().GetBool("project")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project flag: %w", err)
+	}
+
+	layer := config.LayerUser
+	if project {
+		if !config.IsInitialized() {
+			return nil, fmt.Errorf("%w. Run 'goingenv init' first", types.ErrNotInitialized)
+		}
+		layer = config.LayerProject
+	}
+
+	return config.NewManager().Edit(layer)
+}
+
+// saveConfigEditor writes pending edits and reports the result
+func saveConfigEditor(out *Output, editor *config.Editor) error {
+	if err := editor.Save(); err != nil {
+		out.Error(configErrorLine(err))
+		return err
+	}
+	setResultData(map[string]string{"path": editor.Path()})
+	out.Success(fmt.Sprintf("Updated %s", editor.Path()))
+	return nil
+}
+
+// lookupSetting resolves a setting key, listing valid keys on failure
+func lookupSetting(key string) (config.Setting, error) {
+	setting, ok := config.LookupSetting(key)
+	if !ok {
+		keys := make([]string, 0, len(config.Settings))
+		for _, s := range config.Settings {
+			keys = append(keys, s.Key)
+		}
+		return setting, newUsageError("unknown setting %q (valid: %s)", key, strings.Join(keys, ", "))
+	}
+	return setting, nil
+}
+
+// configErrorLine renders validation errors as "key: message"
+func configErrorLine(err error) string {
+	var validationErr *types.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err.Error()
+	}
+	if setting, ok := config.LookupSetting(validationErr.Field); ok {
+		return fmt.Sprintf("%s: %s", setting.Key, validationErr.Message)
+	}
+	return fmt.Sprintf("%s: %s", validationErr.Field, validationErr.Message)
+}
@@ -0,0 +1,388 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"goingenv/internal/audit"
+	"goingenv/internal/scanner"
+	"goingenv/pkg/types"
+	"goingenv/pkg/utils"
+)
+
+// newAddCommand creates the add command
+func newAddCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "add <path>...",
+		Short: "Add or replace files in an existing archive",
+		Long: `Add files to an existing archive, replacing entries with the same path.
+
+Paths are stored relative to --directory (default: current directory), the
+same way pack stores them. The archive keeps its description, profile and
+creation time; its file list, sizes and checksums are rewritten and the file
+is replaced atomically.
+
+Examples:
+  goingenv add -f archive.enc api/.env.local         # Add one file
+  goingenv add -f latest .env .env.test --dry-run    # Preview adding to the newest archive`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEditCommand(cmd, args, planAdd)
+		},
+	}
+
+	addEditFlags(cmd, "Archive file or reference to add to (default: latest)")
+	cmd.Flags().StringP("directory", "d", ".", "Directory that paths in the archive are relative to")
+
+	return cmd
+}
+
+// newRmCommand creates the rm command
+func newRmCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rm <path>...",
+		Short: "Remove files from an existing archive",
+		Long: `Remove entries from an existing archive. Paths are the ones shown by
+'goingenv list'. An archive cannot be left empty.
+
+Examples:
+  goingenv rm -f archive.enc api/.env.local
+  goingenv rm -f latest .env.test --dry-run`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEditCommand(cmd, args, planRemove)
+		},
+	}
+
+	addEditFlags(cmd, "Archive file or reference to remove from (default: latest)")
+
+	return cmd
+}
+
+// newUpdateCommand creates the update command
+func newUpdateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Refresh changed files in an existing archive",
+		Long: `Refresh an archive from the working tree. Only files whose checksum
+changed are replaced; files missing from the working tree are kept as they
+are, and new files are not added (use 'goingenv add').
+
+Examples:
+  goingenv update -f archive.enc --dry-run           # Show which files changed
+  goingenv update -f latest -d ./api                 # Refresh from another directory`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runEditCommand(cmd, args, planUpdate)
+		},
+	}
+
+	addEditFlags(cmd, "Archive file or reference to update (default: latest)")
+	cmd.Flags().StringP("directory", "d", ".", "Directory that paths in the archive are relative to")
+
+	return cmd
+}
+
+// addEditFlags adds the flags shared by add, rm and update
+func addEditFlags(cmd *cobra.Command, fileUsage string) {
+	addPasswordFlags(cmd)
+	cmd.Flags().StringP("file", "f", "", fileUsage)
+	cmd.Flags().Bool("dry-run", false, "Show what would change without changing the archive")
+}
+
+// editResult is the JSON result of the add, rm and update commands
+type editResult struct {
+	Archive   string   `json:"archive"`
+	DryRun    bool     `json:"dry_run"`
+	Added     []string `json:"added"`
+	Replaced  []string `json:"replaced"`
+	Removed   []string `json:"removed"`
+	Files     int      `json:"files"`
+	TotalSize int64    `json:"total_size"`
+}
+
+// editPlanner turns command arguments into the changes to an archive whose
+// current metadata is given
+type editPlanner func(out *Output, app *types.App, cmd *cobra.Command, args []string, archive *types.Archive) (*types.UpdateOptions, error)
+
+// runEditCommand resolves and opens an archive, plans the changes and
+// rewrites the archive in place
+func runEditCommand(cmd *cobra.Command, args []string, plan editPlanner) error {
+	out := NewOutput(appVersion)
+
+	app, err := initApp()
+	if err != nil {
+		out.Header()
+		out.Blank()
+		out.Error(err.Error())
+		return err
+	}
+
+	archiveRef, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return fmt.Errorf("failed to get file flag: %w", err)
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("failed to get dry-run flag: %w", err)
+	}
+	passwordOpts, err := parsePasswordOpts(cmd)
+	if err != nil {
+		return err
+	}
+
+	out.Header()
+	out.Blank()
+
+	archivePath, err := pickArchive(app, archiveRef)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	echoArchive(out, archiveRef, archivePath)
+
+	if _, sigErr := checkArchiveSignature(out, app, archivePath); sigErr != nil {
+		return sigErr
+	}
+
+	key, cleanup, err := getPass(app, passwordOpts)
+	if err != nil {
+		out.Error(fmt.Sprintf("Failed to get password: %v", err))
+		return err
+	}
+	defer cleanup()
+
+	archive, err := app.Archiver.List(archivePath, key)
+	if err != nil {
+		out.Error("Failed to read archive (check password)")
+		return fmt.Errorf("failed to read archive: %w", err)
+	}
+
+	updateOpts, err := plan(out, app, cmd, args, archive)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	updateOpts.ArchivePath, updateOpts.Password = archivePath, key
+
+	res := newEditResult(archivePath, archive, updateOpts)
+	res.DryRun = dryRun
+	setResultData(res)
+	out.Blank()
+	displayEdit(out, res)
+
+	switch {
+	case len(updateOpts.Put) == 0 && len(updateOpts.Remove) == 0:
+		out.Success("Archive is up to date")
+		return nil
+	case dryRun:
+		out.Success(fmt.Sprintf("Dry run: would rewrite %s", archivePath))
+		return nil
+	}
+
+	updated, err := app.Archiver.Update(*updateOpts)
+	if err != nil {
+		out.Error(fmt.Sprintf("Error updating archive: %v", err))
+		return err
+	}
+	res.Files, res.TotalSize = len(updated.Files), updated.TotalSize
+
+	out.Success(fmt.Sprintf("Updated %s (%d files, %s)", archivePath, res.Files, utils.FormatSize(res.TotalSize)))
+	removeStaleSignature(out, archivePath)
+	updateCatalogFiles(out, archivePath, res.Files)
+	auditArchive(out, app, audit.OpUpdate, archivePath, changedFiles(updateOpts))
+	return nil
+}
+
+// changedFiles lists the entries an update puts or removes
+func changedFiles(opts *types.UpdateOptions) []types.EnvFile {
+	files := append([]types.EnvFile(nil), opts.Put...)
+	for _, name := range opts.Remove {
+		files = append(files, types.EnvFile{RelativePath: name})
+	}
+	return files
+}
+
+// newEditResult describes the planned changes to an archive
+func newEditResult(archivePath string, archive *types.Archive, opts *types.UpdateOptions) *editResult {
+	res := &editResult{Archive: archivePath, Added: []string{}, Replaced: []string{}, Removed: opts.Remove}
+	if res.Removed == nil {
+		res.Removed = []string{}
+	}
+
+	sizes := make(map[string]int64, len(archive.Files))
+	for _, file := range archive.Files {
+		sizes[file.RelativePath] = file.Size
+	}
+	res.TotalSize = archive.TotalSize
+	for _, file := range opts.Put {
+		if size, ok := sizes[file.RelativePath]; ok {
+			res.Replaced = append(res.Replaced, file.RelativePath)
+			res.TotalSize -= size
+		} else {
+			res.Added = append(res.Added, file.RelativePath)
+		}
+		res.TotalSize += file.Size
+	}
+	for _, name := range res.Removed {
+		res.TotalSize -= sizes[name]
+	}
+
+	res.Files = len(archive.Files) + len(res.Added) - len(res.Removed)
+	return res
+}
+
+// displayEdit lists the planned changes
+func displayEdit(out *Output, res *editResult) {
+	for _, name := range res.Added {
+		out.ListItem(fmt.Sprintf("add      %s", name))
+	}
+	for _, name := range res.Replaced {
+		out.ListItem(fmt.Sprintf("replace  %s", name))
+	}
+	for _, name := range res.Removed {
+		out.ListItem(fmt.Sprintf("remove   %s", name))
+	}
+	if len(res.Added)+len(res.Replaced)+len(res.Removed) > 0 {
+		out.Blank()
+	}
+}
+
+// planAdd adds or replaces the files named on the command line
+func planAdd(_ *Output, app *types.App, cmd *cobra.Command, args []string, _ *types.Archive) (*types.UpdateOptions, error) {
+	dir, err := cmd.Flags().GetString("directory")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get directory flag: %w", err)
+	}
+
+	opts := &types.UpdateOptions{}
+	for _, path := range args {
+		relPath, relErr := archiveRelPath(dir, path)
+		if relErr != nil {
+			return nil, relErr
+		}
+		if validateErr := app.Scanner.ValidateFile(path); validateErr != nil {
+			return nil, validateErr
+		}
+		file, fileErr := envFileAt(path, relPath)
+		if fileErr != nil {
+			return nil, fileErr
+		}
+		opts.Put = append(opts.Put, file)
+	}
+	return opts, nil
+}
+
+// planRemove removes the archive entries named on the command line
+func planRemove(_ *Output, _ *types.App, _ *cobra.Command, args []string, archive *types.Archive) (*types.UpdateOptions, error) {
+	existing := make(map[string]bool, len(archive.Files))
+	for _, file := range archive.Files {
+		existing[file.RelativePath] = true
+	}
+
+	opts := &types.UpdateOptions{}
+	for _, name := range args {
+		name = filepath.Clean(name)
+		if !existing[name] {
+			return nil, newUsageError("%s is not in the archive; see 'goingenv list'", name)
+		}
+		opts.Remove = append(opts.Remove, name)
+	}
+	return opts, nil
+}
+
+// planUpdate replaces the archive files whose working tree copy changed
+func planUpdate(out *Output, _ *types.App, cmd *cobra.Command, _ []string, archive *types.Archive) (*types.UpdateOptions, error) {
+	dir, err := cmd.Flags().GetString("directory")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get directory flag: %w", err)
+	}
+
+	opts := &types.UpdateOptions{}
+	for _, file := range archive.Files {
+		path := filepath.Join(dir, file.RelativePath)
+		if _, statErr := os.Lstat(path); os.IsNotExist(statErr) {
+			out.Skipped(fmt.Sprintf("%s is not in the working tree; kept", file.RelativePath))
+			continue
+		}
+
+		current, fileErr := envFileAt(path, file.RelativePath)
+		if file.LinkTarget != "" {
+			current, fileErr = linkFileAt(path, file.RelativePath)
+		}
+		if fileErr != nil {
+			return nil, fileErr
+		}
+		if current.Checksum != file.Checksum {
+			opts.Put = append(opts.Put, current)
+		}
+	}
+	return opts, nil
+}
+
+// archiveRelPath returns path relative to dir, rejecting paths outside it
+func archiveRelPath(dir, path string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", dir, err)
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve %s: %w", path, err)
+	}
+
+	relPath, err := filepath.Rel(absDir, absPath)
+	if err != nil || relPath == "." || relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
+		return "", newUsageError("%s is not inside %s", path, dir)
+	}
+	return relPath, nil
+}
+
+// envFileAt describes a regular file for the archive, following symlinks
+func envFileAt(path, relPath string) (types.EnvFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return types.EnvFile{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if info.IsDir() {
+		return types.EnvFile{}, newUsageError("%s is a directory", path)
+	}
+	checksum, err := utils.CalculateFileChecksum(path)
+	if err != nil {
+		return types.EnvFile{}, fmt.Errorf("failed to calculate checksum of %s: %w", path, err)
+	}
+	return types.EnvFile{
+		Path:         path,
+		RelativePath: relPath,
+		Size:         info.Size(),
+		ModTime:      info.ModTime(),
+		Checksum:     checksum,
+	}, nil
+}
+
+// linkFileAt describes a symlink stored as a link, like pack does with the
+// preserve-as-link policy
+func linkFileAt(path, relPath string) (types.EnvFile, error) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return types.EnvFile{}, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return envFileAt(path, relPath)
+	}
+	target, err := os.Readlink(path)
+	if err != nil {
+		return types.EnvFile{}, fmt.Errorf("failed to read link %s: %w", path, err)
+	}
+	return types.EnvFile{
+		Path:         path,
+		RelativePath: relPath,
+		ModTime:      info.ModTime(),
+		Checksum:     scanner.LinkChecksum(target),
+		LinkTarget:   target,
+	}, nil
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"goingenv/pkg/types"
+)
+
+// Process exit codes. These are part of the CLI contract: scripts rely on
+// them, so existing values must never change.
+const (
+	ExitOK              = 0
+	ExitError           = 1
+	ExitUsage           = 2
+	ExitValidation      = 3
+	ExitNotInitialized  = 4
+	ExitWrongPassword   = 5
+	ExitArchiveNotFound = 6
+	ExitConflict        = 7
+	ExitCryptoError     = 8
+	ExitArchiveError    = 9
+	ExitScanError       = 10
+	ExitBadSignature    = 11
+	ExitUntrustedSigner = 12
+	ExitIntegrity       = 13
+	ExitPlaintextStaged = 14
+	ExitArchiveExpired  = 15
+)
+
+// exitClass maps a class of errors to its exit code and JSON error code
+type exitClass struct {
+	Exit  int
+	Code  string
+	match func(error) bool
+}
+
+// exitClasses is checked in order; sentinels come before the error types that
+// wrap them so "wrong password" wins over the generic crypto failure.
+var exitClasses = []exitClass{
+	{ExitUsage, "usage_error", isType[*usageError]},
+	{ExitNotInitialized, "not_initialized", isErr(types.ErrNotInitialized)},
+	{ExitWrongPassword, "wrong_password", isErr(types.ErrWrongPassword)},
+	{ExitArchiveNotFound, "archive_not_found", isErr(types.ErrArchiveNotFound)},
+	{ExitConflict, "conflict", isErr(types.ErrConflict)},
+	{ExitBadSignature, "bad_signature", isErr(types.ErrBadSignature)},
+	{ExitUntrustedSigner, "untrusted_signer", isErr(types.ErrUntrustedSigner)},
+	{ExitIntegrity, "integrity_error", isErr(types.ErrIntegrity)},
+	{ExitPlaintextStaged, "plaintext_staged", isErr(types.ErrPlaintextStaged)},
+	{ExitArchiveExpired, "archive_expired", isErr(types.ErrArchiveExpired)},
+	{ExitValidation, "validation_error", isType[*types.ValidationError]},
+	{ExitCryptoError, "crypto_error", isType[*types.CryptoError]},
+	{ExitArchiveError, "archive_error", isType[*types.ArchiveError]},
+	{ExitScanError, "scan_error", isType[*types.ScanError]},
+}
+
+// isErr returns a matcher for a sentinel error
+func isErr(target error) func(error) bool {
+	return func(err error) bool { return errors.Is(err, target) }
+}
+
+// isType matches errors wrapping an error of type T
+func isType[T error](err error) bool {
+	var target T
+	return errors.As(err, &target)
+}
+
+// classifyError finds the exit class for err
+func classifyError(err error) exitClass {
+	for _, class := range exitClasses {
+		if class.match(err) {
+			return class
+		}
+	}
+	return exitClass{Exit: ExitError, Code: "error"}
+}
+
+// exitCode returns the process exit code for err
+func exitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+	return classifyError(err).Exit
+}
+
+// errorCode classifies an error for machine consumption
+func errorCode(err error) string {
+	return classifyError(err).Code
+}
+
+// usageError marks a command line mistake (bad flag, argument or combination)
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string {
+	return e.err.Error()
+}
+
+func (e *usageError) Unwrap() error {
+	return e.err
+}
+
+// newUsageError creates a usage error from a format string
+func newUsageError(format string, args ...interface{}) error {
+	return &usageError{err: fmt.Errorf(format, args...)}
+}
+
+// markUsageErrors makes flag and argument validation failures of cmd and its
+// subcommands exit with ExitUsage
+func markUsageErrors(cmd *cobra.Command) {
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return &usageError{err: err}
+	})
+	if validate := cmd.Args; validate != nil {
+		cmd.Args = func(c *cobra.Command, args []string) error {
+			if err := validate(c, args); err != nil {
+				return &usageError{err: err}
+			}
+			return nil
+		}
+	}
+	for _, sub := range cmd.Commands() {
+		markUsageErrors(sub)
+	}
+}
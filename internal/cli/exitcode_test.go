@@ -0,0 +1,109 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"goingenv/pkg/types"
+)
+
+// TestExitCodes locks in the documented exit code table. Changing a value
+// here breaks scripts; add new codes instead.
+func TestExitCodes(t *testing.T) {
+	wrongPassword := &types.ArchiveError{
+		Operation: "unpack",
+		Path:      "backup.enc",
+		Err: fmt.Errorf("failed to decrypt archive: %w", &types.CryptoError{
+			Operation: "decrypt",
+			Err:       fmt.Errorf("decryption failed: %w", types.ErrWrongPassword),
+		}),
+	}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantExit int
+		wantCode string
+	}{
+		{"success", nil, 0, ""},
+		{"plain error", errors.New("boom"), 1, "error"},
+		{"usage", newUsageError("archive file is required"), 2, "usage_error"},
+		{"validation", &types.ValidationError{Field: "DefaultDepth", Message: "too deep"}, 3, "validation_error"},
+		{"not initialized", fmt.Errorf("%w. Run 'goingenv init' first", types.ErrNotInitialized), 4, "not_initialized"},
+		{"wrong password", wrongPassword, 5, "wrong_password"},
+		{"wrong password wrapped by cli", fmt.Errorf("decryption failed: %w", wrongPassword), 5, "wrong_password"},
+		{"archive not found", fmt.Errorf("%w: backup.enc", types.ErrArchiveNotFound), 6, "archive_not_found"},
+		{"conflict", fmt.Errorf("%w, use --overwrite to proceed", types.ErrConflict), 7, "conflict"},
+		{"crypto", &types.CryptoError{Operation: "encrypt", Err: errors.New("no entropy")}, 8, "crypto_error"},
+		{"archive", &types.ArchiveError{Operation: "pack", Path: "x.enc", Err: errors.New("disk full")}, 9, "archive_error"},
+		{"scan", &types.ScanError{Path: ".", Err: errors.New("permission denied")}, 10, "scan_error"},
+		{"bad signature", fmt.Errorf("%w: backup.enc", types.ErrBadSignature), 11, "bad_signature"},
+		{"untrusted signer", fmt.Errorf("%w: backup.enc", types.ErrUntrustedSigner), 12, "untrusted_signer"},
+		{"integrity error", fmt.Errorf("%w: backup.enc", types.ErrIntegrity), 13, "integrity_error"},
+		{"plaintext staged", fmt.Errorf("%w: .env", types.ErrPlaintextStaged), 14, "plaintext_staged"},
+		{"archive expired", &types.ArchiveError{Operation: "unpack", Path: "x.enc", Err: fmt.Errorf("%w: expired", types.ErrArchiveExpired)}, 15, "archive_expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exitCode(tt.err); got != tt.wantExit {
+				t.Errorf("exitCode() = %d, want %d", got, tt.wantExit)
+			}
+			if tt.err == nil {
+				return
+			}
+			if got := errorCode(tt.err); got != tt.wantCode {
+				t.Errorf("errorCode() = %q, want %q", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestErrorTypesUnwrap(t *testing.T) {
+	cause := errors.New("cause")
+
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"ScanError", &types.ScanError{Path: ".", Err: cause}},
+		{"ArchiveError", &types.ArchiveError{Operation: "pack", Path: "x.enc", Err: cause}},
+		{"CryptoError", &types.CryptoError{Operation: "encrypt", Err: cause}},
+	}
+
+	for _, tt := range tests {
+		if !errors.Is(tt.err, cause) {
+			t.Errorf("errors.Is(%s, cause) = false, want true", tt.name)
+		}
+	}
+}
+
+func TestUsageErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"unknown flag", []string{"status", "--bogus"}},
+		{"missing argument", []string{"config", "get"}},
+		{"too many arguments", []string{"config", "show", "extra"}},
+		{"bad output format", []string{"--output", "yaml", "status"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() { outputFormat = FormatText }()
+
+			root := NewRootCommand("1.0.0")
+			root.SetArgs(tt.args)
+			root.SetOut(io.Discard)
+			root.SetErr(io.Discard)
+
+			err := root.Execute()
+			if got := exitCode(err); got != ExitUsage {
+				t.Errorf("exitCode(%v) = %d, want %d", err, got, ExitUsage)
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"goingenv/internal/catalog"
+	"goingenv/internal/config"
+	"goingenv/internal/git"
+	"goingenv/pkg/password"
+	"goingenv/pkg/types"
+)
+
+// newGitCommand creates the git command
+func newGitCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "git",
+		Short: "Integrate archives with git",
+		Long: `Set up git to work with the archives in this project.
+
+Examples:
+  goingenv git setup                            # Show archive diffs in git diff and git log -p
+  goingenv git setup --password-env MY_PASSWORD # Read the password from the environment
+  goingenv git install-hooks                    # Run 'goingenv guard' before every commit`,
+	}
+
+	cmd.AddCommand(newGitSetupCommand())
+	cmd.AddCommand(newGitInstallHooksCommand())
+
+	return cmd
+}
+
+// newGitSetupCommand creates the git setup subcommand
+func newGitSetupCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "setup",
+		Short: "Register the archive diff and merge drivers",
+		Long: `Register goingenv as the git diff and merge driver for this project's
+archives.
+
+The setup command will:
+- Add "diff=goingenv merge=goingenv" attributes for .goingenv/*.enc to
+  .gitattributes at the root of the work tree
+- Set diff.goingenv.textconv in the repository's local git config to run
+  'goingenv git-textconv', and merge.goingenv.driver to run
+  'goingenv git-merge'
+- Keep merge conflict side files out of git via .goingenv/.gitignore
+
+git diff, git log -p and git show then list archive entries and keys with
+value fingerprints instead of reporting a binary change, and merges combine
+archive changes key by key. The password comes from the key agent unless
+--password-env names a variable to read it from. .gitattributes is meant to
+be committed; the git config stays local, so each clone runs setup once.`,
+		Args: cobra.NoArgs,
+		RunE: runGitSetupCommand,
+	}
+
+	cmd.Flags().String("password-env", "", "Have the git drivers read the password from this environment variable")
+
+	return cmd
+}
+
+// newGitInstallHooksCommand creates the git install-hooks subcommand
+func newGitInstallHooksCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install-hooks",
+		Short: "Install a pre-commit hook that runs goingenv guard",
+		Long: `Install a pre-commit hook that runs 'goingenv guard', so commits that
+stage plaintext env files fail. The hook goes into the repository's hooks
+directory (core.hooksPath if set); an existing pre-commit hook that goingenv
+did not write is kept unless --force is given.
+
+The work tree is also scanned for env files git does not ignore, and their
+names are offered for .gitignore (added without asking with --gitignore).
+
+Examples:
+  goingenv git install-hooks
+  goingenv git install-hooks --gitignore   # Also ignore unignored env files`,
+		Args: cobra.NoArgs,
+		RunE: runGitInstallHooksCommand,
+	}
+
+	cmd.Flags().Bool("force", false, "Replace an existing pre-commit hook")
+	cmd.Flags().Bool("gitignore", false, "Add unignored env file names to .gitignore without asking")
+
+	return cmd
+}
+
+// newGitTextconvCommand creates the git-textconv command
+func newGitTextconvCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "git-textconv <file>",
+		Short: "Print a redacted listing of an archive for git diff",
+		Long: `Print a redacted, stable listing of an archive: every entry, sorted, with
+the keys each env file sets and a short fingerprint of each value. Values
+are never printed. Fingerprints are keyed by the archive password, so equal
+values in two revisions match while the values themselves cannot be guessed
+from the diff.
+
+The fingerprint key is derived from the password with the same PBKDF2 work
+as the archive key, but with a fixed salt so that fingerprints stay stable
+across revisions. A diff therefore lets an attacker test password guesses
+offline at the same cost as the archive itself, and one run of guesses
+covers every archive sharing the password. Use a strong password, or skip
+'goingenv git setup' where diffs are published.
+
+git runs this command as the textconv filter registered by 'goingenv git
+setup'. It never prompts: the password comes from a password flag or the
+key agent. An archive it cannot decrypt is listed by its SHA-256 instead,
+so git diff keeps working.
+
+Examples:
+  goingenv git-textconv .goingenv/prod.enc
+  git diff HEAD~1 -- .goingenv/`,
+		Args: cobra.ExactArgs(1),
+		RunE: runGitTextconvCommand,
+	}
+
+	addPasswordFlags(cmd)
+	cmd.Flags().String("project", "", "Project directory holding .goingenv, relative to the working directory")
+
+	return cmd
+}
+
+// gitSetupResult is the JSON result of the git setup command
+type gitSetupResult struct {
+	Attributes string `json:"attributes"`
+	Pattern    string `json:"pattern"`
+	Added      bool   `json:"added"`
+	Textconv   string `json:"textconv"`
+	Merge      string `json:"merge"`
+}
+
+// runGitSetupCommand executes the git setup command
+func runGitSetupCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+	out.Header()
+	out.Blank()
+
+	if !config.IsInitialized() {
+		err := fmt.Errorf("%w. Run 'goingenv init' first", types.ErrNotInitialized)
+		out.Error(err.Error())
+		return err
+	}
+
+	passwordEnv, err := cmd.Flags().GetString("password-env")
+	if err != nil {
+		return fmt.Errorf("failed to get password-env flag: %w", err)
+	}
+
+	projectDir, err := filepath.Abs(".")
+	if err != nil {
+		return fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+	root, err := git.TopLevel(projectDir)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	// git reports the resolved root; resolve symlinks on our side too so the
+	// relative path doesn't climb out of the work tree
+	if resolved, evalErr := filepath.EvalSymlinks(projectDir); evalErr == nil {
+		projectDir = resolved
+	}
+	rel, err := filepath.Rel(root, projectDir)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return fmt.Errorf("project directory %s is outside the git work tree %s", projectDir, root)
+	}
+	rel = filepath.ToSlash(rel)
+
+	res := gitSetupResult{
+		Attributes: filepath.Join(root, git.AttributesFile),
+		Pattern:    path.Join(rel, config.GetGoingEnvDir(), "*.enc") + " diff=" + git.DriverName + " merge=" + git.DriverName,
+		Textconv:   textconvCommand(rel, passwordEnv),
+		Merge:      mergeCommand(passwordEnv),
+	}
+	setResultData(&res)
+
+	if res.Added, err = git.EnsureLine(res.Attributes, res.Pattern); err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	if res.Added {
+		out.Success(fmt.Sprintf("Added '%s' to %s", res.Pattern, git.AttributesFile))
+	} else {
+		out.Skipped(fmt.Sprintf("%s already has '%s'", git.AttributesFile, res.Pattern))
+	}
+
+	if _, err := git.EnsureLine(filepath.Join(config.GetGoingEnvDir(), ".gitignore"), git.ConflictsDir+"/"); err != nil {
+		out.Error(err.Error())
+		return err
+	}
+
+	settings := [][2]string{
+		{"diff." + git.DriverName + ".textconv", res.Textconv},
+		{"merge." + git.DriverName + ".name", "goingenv archive merge"},
+		{"merge." + git.DriverName + ".driver", res.Merge},
+	}
+	for _, setting := range settings {
+		if _, err := git.Run(root, "config", "--local", setting[0], setting[1]); err != nil {
+			out.Error(err.Error())
+			return err
+		}
+		out.Success(fmt.Sprintf("Set %s to '%s'", setting[0], setting[1]))
+	}
+
+	out.Blank()
+	if passwordEnv == "" {
+		out.Hint("The git drivers read the password from the key agent; run 'goingenv agent add' to unlock it")
+	}
+	out.Hint(fmt.Sprintf("Commit %s so other clones get the attribute, then run 'goingenv git setup' there", git.AttributesFile))
+	return nil
+}
+
+// gitHooksResult is the JSON result of the git install-hooks command
+type gitHooksResult struct {
+	Hook      string   `json:"hook"`
+	Unignored []string `json:"unignored"`
+	Gitignore []string `json:"gitignore_added,omitempty"`
+}
+
+// runGitInstallHooksCommand executes the git install-hooks command
+func runGitInstallHooksCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+	out.Header()
+	out.Blank()
+
+	force, err := cmd.Flags().GetBool("force")
+	if err != nil {
+		return fmt.Errorf("failed to get force flag: %w", err)
+	}
+	addIgnores, err := cmd.Flags().GetBool("gitignore")
+	if err != nil {
+		return fmt.Errorf("failed to get gitignore flag: %w", err)
+	}
+
+	app, err := NewApp()
+	if err != nil {
+		return err
+	}
+	root, err := git.TopLevel(".")
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	hooksDir, err := git.HooksDir(root)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+
+	res := gitHooksResult{}
+	setResultData(&res)
+
+	if res.Hook, err = git.InstallHook(hooksDir, force); err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	out.Success(fmt.Sprintf("Installed pre-commit hook %s", res.Hook))
+
+	if res.Unignored, err = unignoredEnvFiles(app, root); err != nil {
+		out.Warning(fmt.Sprintf("Could not check for unignored env files: %v", err))
+		return nil
+	}
+	if len(res.Unignored) == 0 {
+		out.Success("Every env file in the work tree is ignored by git")
+		return nil
+	}
+
+	out.WarningList("Env files not ignored by git:", res.Unignored, 10)
+	entries := git.IgnoreEntries(res.Unignored)
+	if !addIgnores && !offerIgnores(entries) {
+		out.Blank()
+		out.Hint("Run 'goingenv git install-hooks --gitignore' to add them to .gitignore")
+		return nil
+	}
+	if res.Gitignore, err = appendIgnores(root, entries); err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	for _, entry := range res.Gitignore {
+		out.Success(fmt.Sprintf("Added '%s' to .gitignore", entry))
+	}
+	return nil
+}
+
+// mergeCommand returns the merge driver command; git substitutes the base,
+// ours, theirs and archive paths
+func mergeCommand(passwordEnv string) string {
+	command := "goingenv git-merge"
+	if passwordEnv != "" {
+		command += " --password-env " + shellQuote(passwordEnv)
+	}
+	return command + " %O %A %B %P"
+}
+
+// textconvCommand returns the textconv command git runs from the root of
+// the work tree for a project at rel
+func textconvCommand(rel, passwordEnv string) string {
+	command := "goingenv git-textconv"
+	if rel != "." {
+		command += " --project " + shellQuote(rel)
+	}
+	if passwordEnv != "" {
+		command += " --password-env " + shellQuote(passwordEnv)
+	}
+	return command
+}
+
+// shellQuote quotes s for the shell git runs textconv commands with
+func shellQuote(s string) string {
+	if s != "" && strings.Trim(s, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_-./") == "" {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// runGitTextconvCommand executes the git-textconv command. Its standard
+// output is the listing alone; problems go to standard error.
+func runGitTextconvCommand(cmd *cobra.Command, args []string) error {
+	archivePath, err := filepath.Abs(args[0])
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s: %w", args[0], err)
+	}
+
+	project, err := cmd.Flags().GetString("project")
+	if err != nil {
+		return fmt.Errorf("failed to get project flag: %w", err)
+	}
+	if project != "" {
+		if chdirErr := os.Chdir(project); chdirErr != nil {
+			return fmt.Errorf("failed to enter project directory: %w", chdirErr)
+		}
+	}
+
+	passwordOpts, err := parsePasswordOpts(cmd)
+	if err != nil {
+		return err
+	}
+	if validateErr := password.ValidatePasswordOptions(passwordOpts); validateErr != nil {
+		return newUsageError("invalid password options: %v", validateErr)
+	}
+
+	app, err := NewApp()
+	if err != nil {
+		return err
+	}
+
+	key, err := driverPass(app, passwordOpts)
+	if err == nil {
+		defer password.ClearPassword(&key)
+		archive, contents, readErr := app.Archiver.Read(archivePath, key)
+		if readErr == nil {
+			return git.Textconv(os.Stdout, archive, contents, key)
+		}
+		err = readErr
+	}
+
+	fmt.Fprintf(os.Stderr, "goingenv: %s: %v\n", args[0], err)
+	sum, hashErr := catalog.Hash(archivePath)
+	if hashErr != nil {
+		return hashErr
+	}
+	return git.TextconvUnreadable(os.Stdout, sum)
+}
+
+// driverPass returns the password for the git drivers, which must never
+// prompt: from an explicit password source, else from the key agent
+func driverPass(app *types.App, opts password.Options) (string, error) {
+	if opts.NonInteractive() {
+		return password.GetPassword(opts)
+	}
+	if key, ok := agentPassword(app); ok {
+		return key, nil
+	}
+	return "", fmt.Errorf("no password: unlock the project with 'goingenv agent add' or pass --password-env")
+}
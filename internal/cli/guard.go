@@ -0,0 +1,201 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"goingenv/internal/git"
+	"goingenv/internal/scanner"
+	"goingenv/pkg/types"
+)
+
+// newGuardCommand creates the guard command
+func newGuardCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "guard",
+		Short: "Refuse commits that stage plaintext env files",
+		Long: `Check the paths staged for commit against the env patterns the scanner
+uses (env_patterns minus env_exclude_patterns, matched on file names), and
+fail if any plaintext env file would be committed. Archives (*.enc) and
+their signatures always pass.
+
+Paths come from 'git diff --cached --name-only', or one per line from
+standard input with --stdin. 'goingenv git install-hooks' runs this command
+as a pre-commit hook; 'git commit --no-verify' skips it.
+
+Examples:
+  goingenv guard                                 # Check the index
+  goingenv guard --gitignore                     # Also add blocked names to .gitignore
+  git ls-files | goingenv guard --stdin          # Check files already tracked`,
+		Args: cobra.NoArgs,
+		RunE: runGuardCommand,
+	}
+
+	cmd.Flags().Bool("stdin", false, "Read paths from standard input instead of the git index")
+	cmd.Flags().Bool("gitignore", false, "Append the names of blocked files to the repository's .gitignore")
+
+	return cmd
+}
+
+// guardResult is the JSON result of the guard command
+type guardResult struct {
+	Checked   int      `json:"checked"`
+	Blocked   []string `json:"blocked"`
+	Gitignore []string `json:"gitignore_added,omitempty"`
+}
+
+// runGuardCommand executes the guard command
+func runGuardCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+	out.Header()
+	out.Blank()
+
+	fromStdin, err := cmd.Flags().GetBool("stdin")
+	if err != nil {
+		return fmt.Errorf("failed to get stdin flag: %w", err)
+	}
+	addIgnores, err := cmd.Flags().GetBool("gitignore")
+	if err != nil {
+		return fmt.Errorf("failed to get gitignore flag: %w", err)
+	}
+
+	app, err := NewApp()
+	if err != nil {
+		return err
+	}
+	matcher, err := scanner.NewEnvMatcher(app.Config.EnvPatterns, app.Config.EnvExcludePatterns)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+
+	var paths []string
+	if fromStdin {
+		paths, err = readPathLines(os.Stdin)
+	} else {
+		paths, err = git.StagedPaths(".")
+	}
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+
+	res := guardResult{Checked: len(paths), Blocked: git.Guard(paths, matcher.Match)}
+	if res.Blocked == nil {
+		res.Blocked = []string{}
+	}
+	setResultData(&res)
+
+	if len(res.Blocked) == 0 {
+		out.Success(fmt.Sprintf("No plaintext env files among %d paths", res.Checked))
+		return nil
+	}
+
+	out.Error(fmt.Sprintf("%d plaintext env files would be committed:", len(res.Blocked)))
+	for _, path := range res.Blocked {
+		out.ListItem(path)
+	}
+
+	if addIgnores {
+		if res.Gitignore, err = appendIgnores(".", git.IgnoreEntries(res.Blocked)); err != nil {
+			out.Error(err.Error())
+			return err
+		}
+		for _, entry := range res.Gitignore {
+			out.Success(fmt.Sprintf("Added '%s' to .gitignore", entry))
+		}
+	}
+
+	out.Blank()
+	out.Hint("Unstage them with 'git rm --cached <file>', then commit an archive from 'goingenv pack' instead")
+	if !addIgnores {
+		out.Hint("Run 'goingenv guard --gitignore' to ignore them from now on")
+	}
+	return fmt.Errorf("%w: %s", types.ErrPlaintextStaged, strings.Join(res.Blocked, ", "))
+}
+
+// readPathLines reads one path per line, skipping blank lines
+func readPathLines(f *os.File) ([]string, error) {
+	var paths []string
+	lines := bufio.NewScanner(f)
+	for lines.Scan() {
+		if path := strings.TrimSpace(lines.Text()); path != "" {
+			paths = append(paths, path)
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read paths: %w", err)
+	}
+	return paths, nil
+}
+
+// appendIgnores adds entries to the .gitignore at the root of the work tree
+// containing dir and returns the entries that were not there yet
+func appendIgnores(dir string, entries []string) ([]string, error) {
+	root, err := git.TopLevel(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var added []string
+	for _, entry := range entries {
+		changed, ensureErr := git.EnsureLine(filepath.Join(root, ".gitignore"), entry)
+		if ensureErr != nil {
+			return added, ensureErr
+		}
+		if changed {
+			added = append(added, entry)
+		}
+	}
+	return added, nil
+}
+
+// unignoredEnvFiles scans the work tree at root for env files git does not
+// ignore, returning their paths relative to root
+func unignoredEnvFiles(app *types.App, root string) ([]string, error) {
+	files, err := app.Scanner.ScanFiles(&types.ScanOptions{RootPath: root})
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []string
+	for i := range files {
+		rel := filepath.ToSlash(files[i].RelativePath)
+		if !git.IsEncrypted(rel) {
+			candidates = append(candidates, rel)
+		}
+	}
+
+	ignored, err := git.IgnoredPaths(root, candidates)
+	if err != nil {
+		return nil, err
+	}
+	isIgnored := make(map[string]bool, len(ignored))
+	for _, path := range ignored {
+		isIgnored[path] = true
+	}
+
+	var unignored []string
+	for _, path := range candidates {
+		if !isIgnored[path] {
+			unignored = append(unignored, path)
+		}
+	}
+	return unignored, nil
+}
+
+// offerIgnores asks whether to add entries to .gitignore. Without a
+// terminal the answer is no, so hooks and CI never edit files unasked.
+func offerIgnores(entries []string) bool {
+	if !term.IsTerminal(syscall.Stdin) {
+		return false
+	}
+	return confirm(fmt.Sprintf("Add %s to .gitignore?", strings.Join(entries, ", ")))
+}
@@ -2,13 +2,18 @@ package cli
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	"github.com/spf13/cobra"
 	"golang.org/x/term"
 
+	"goingenv/internal/catalog"
 	"goingenv/internal/config"
+	"goingenv/internal/constants"
+	"goingenv/internal/retention"
 	"goingenv/pkg/password"
 	"goingenv/pkg/types"
 )
@@ -16,8 +21,11 @@ import (
 // UnpackOpts holds parsed unpack command flags
 type UnpackOpts struct {
 	Archive   string
+	Tag       string
+	Workspace string
+	Profile   string
 	Target    string
-	PassEnv   string
+	Password  password.Options
 	Overwrite bool
 	Backup    bool
 	Verify    bool
@@ -25,24 +33,36 @@ type UnpackOpts struct {
 	DryRun    bool
 	Include   []string
 	Exclude   []string
+	// IgnoreExpiry unpacks archives outside their validity period
+	IgnoreExpiry bool
 }
 
 // PackOpts holds parsed pack command flags
 type PackOpts struct {
-	Dir     string
-	Output  string
-	PassEnv string
-	Depth   int
-	Include []string
-	Exclude []string
-	Verbose bool
-	DryRun  bool
+	Dir        string
+	Output     string
+	Password   password.Options
+	Depth      int
+	Include    []string
+	Exclude    []string
+	Symlinks   string
+	Workspaces bool
+	Profile    string
+	Generate   bool
+	SignKey    string
+	Name       string
+	Tags       []string
+	Note       string
+	NotBefore  *time.Time
+	ExpiresAt  *time.Time
+	Verbose    bool
+	DryRun     bool
 }
 
 // ListOpts holds parsed list command flags
 type ListOpts struct {
 	Archive   string
-	PassEnv   string
+	Password  password.Options
 	All       bool
 	Verbose   bool
 	Sizes     bool
@@ -58,19 +78,46 @@ type ListOpts struct {
 // initApp checks initialization and creates app
 func initApp() (*types.App, error) {
 	if !config.IsInitialized() {
-		return nil, fmt.Errorf("goingenv is not initialized in this directory. Run 'goingenv init' first")
+		return nil, fmt.Errorf("%w. Run 'goingenv init' first", types.ErrNotInitialized)
 	}
 	return NewApp()
 }
 
-// getPass retrieves password with cleanup function
-func getPass(envVar string) (key string, cleanup func(), err error) {
-	opts := password.Options{PasswordEnv: envVar}
+// getPass retrieves the password for an existing archive. Without an
+// explicit password source the key agent is consulted before prompting.
+func getPass(app *types.App, opts password.Options) (key string, cleanup func(), err error) {
 	if validateErr := password.ValidatePasswordOptions(opts); validateErr != nil {
-		return "", nil, fmt.Errorf("invalid password options: %w", validateErr)
+		return "", nil, newUsageError("invalid password options: %v", validateErr)
 	}
 
-	key, err = password.GetPassword(opts)
+	key, err = readArchivePass(app, opts)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get password: %w", err)
+	}
+
+	cleanup = func() { password.ClearPassword(&key) }
+	return key, cleanup, nil
+}
+
+// readArchivePass returns the password unlocked in the key agent when no
+// password source is given, falling back to password.GetPassword
+func readArchivePass(app *types.App, opts password.Options) (string, error) {
+	if !opts.NonInteractive() {
+		if key, ok := agentPassword(app); ok {
+			return key, nil
+		}
+	}
+	return password.GetPassword(opts)
+}
+
+// getNewPass retrieves the password for a new archive: it must meet the
+// configured strength policy, and prompted passwords are entered twice
+func getNewPass(opts password.Options, cfg *types.Config) (key string, cleanup func(), err error) {
+	if validateErr := password.ValidatePasswordOptions(opts); validateErr != nil {
+		return "", nil, newUsageError("invalid password options: %v", validateErr)
+	}
+
+	key, err = password.GetNewPassword(opts, cfg.MinPasswordScore)
 	if err != nil {
 		return "", nil, fmt.Errorf("failed to get password: %w", err)
 	}
@@ -84,16 +131,25 @@ func confirm(prompt string) bool {
 	if !term.IsTerminal(syscall.Stdin) {
 		return true // non-interactive mode
 	}
-	fmt.Printf("%s [y/N]: ", prompt)
+	prompt = fmt.Sprintf("%s [y/N]: ", prompt)
+	if jsonOutput() {
+		fmt.Fprint(os.Stderr, prompt)
+	} else {
+		fmt.Print(prompt)
+	}
 	var response string
 	_, _ = fmt.Scanln(&response) //nolint:errcheck // user input may be empty
 	return response == "y" || response == "Y" || response == "yes"
 }
 
-// pickArchive selects archive file or returns most recent
-func pickArchive(app *types.App, specified string) (string, error) {
-	if specified != "" {
-		return specified, nil
+// pickArchive resolves an archive reference to a file. Existing paths are
+// used as given; anything else is resolved against the archives in the
+// .goingenv directory (see catalog.Resolve), defaulting to the newest.
+func pickArchive(app *types.App, ref string) (string, error) {
+	if ref != "" {
+		if _, err := os.Stat(ref); err == nil {
+			return ref, nil
+		}
 	}
 
 	archives, err := app.Archiver.GetAvailableArchives("")
@@ -101,9 +157,86 @@ func pickArchive(app *types.App, specified string) (string, error) {
 		return "", fmt.Errorf("failed to find archives: %w", err)
 	}
 	if len(archives) == 0 {
-		return "", fmt.Errorf("no archives found in %s directory. Use -f flag to specify an archive", config.GetGoingEnvDir())
+		if ref != "" {
+			return "", fmt.Errorf("%w: %s", types.ErrArchiveNotFound, ref)
+		}
+		return "", fmt.Errorf("%w: no archives in %s directory. Use -f flag to specify an archive", types.ErrArchiveNotFound, config.GetGoingEnvDir())
+	}
+
+	index, err := catalog.Load(catalogPath())
+	if err != nil {
+		return "", err
+	}
+	return index.Resolve(ref, archives)
+}
+
+// echoArchive reports which archive a reference resolved to
+func echoArchive(out *Output, ref, archivePath string) {
+	switch ref {
+	case archivePath:
+		out.Action(fmt.Sprintf("Using archive %s", archivePath))
+	case "":
+		out.Action(fmt.Sprintf("Using most recent archive: %s", archivePath))
+	default:
+		out.Action(fmt.Sprintf("Using archive %s (%s)", archivePath, ref))
+	}
+}
+
+// verifyArchivePass checks that key opens at least one archive, newest
+// first, so a mistyped password isn't cached or split. Projects without
+// archives pass.
+func verifyArchivePass(out *Output, app *types.App, key string) error {
+	archives, err := app.Archiver.GetAvailableArchives("")
+	if err != nil || len(archives) == 0 {
+		return nil
+	}
+
+	if index, loadErr := catalog.Load(catalogPath()); loadErr == nil {
+		archives = index.Newest(archives)
 	}
-	return archives[len(archives)-1], nil
+
+	var lastErr error
+	for _, archivePath := range archives {
+		if _, lastErr = app.Archiver.List(archivePath, key); lastErr == nil {
+			return nil
+		}
+	}
+	out.Error("Password does not open any archive")
+	return fmt.Errorf("failed to verify password: %w", lastErr)
+}
+
+// addPasswordFlags adds the password source flags shared by commands that
+// read archives
+func addPasswordFlags(cmd *cobra.Command) {
+	cmd.Flags().String("password-env", "", "Read password from environment variable")
+	cmd.Flags().String("password-file", "", "Read password from a file (e.g. a Docker or CI secret mount)")
+	cmd.Flags().Int("password-fd", 0, "Read password from an open file descriptor (e.g. --password-fd 3 3<secret)")
+	cmd.Flags().Bool("password-stdin", false, "Read password from standard input")
+	cmd.Flags().String("password-cmd", "", "Run a command and use its output as the password (e.g. \"op read ...\")")
+}
+
+// parsePasswordOpts parses the password source flags
+func parsePasswordOpts(cmd *cobra.Command) (password.Options, error) {
+	o := password.Options{CmdTimeout: password.DefaultCmdTimeout}
+	var err error
+
+	if o.PasswordEnv, err = cmd.Flags().GetString("password-env"); err != nil {
+		return o, fmt.Errorf("failed to get password-env flag: %w", err)
+	}
+	if o.PasswordFile, err = cmd.Flags().GetString("password-file"); err != nil {
+		return o, fmt.Errorf("failed to get password-file flag: %w", err)
+	}
+	if o.PasswordFD, err = cmd.Flags().GetInt("password-fd"); err != nil {
+		return o, fmt.Errorf("failed to get password-fd flag: %w", err)
+	}
+	if o.PasswordStdin, err = cmd.Flags().GetBool("password-stdin"); err != nil {
+		return o, fmt.Errorf("failed to get password-stdin flag: %w", err)
+	}
+	if o.PasswordCmd, err = cmd.Flags().GetString("password-cmd"); err != nil {
+		return o, fmt.Errorf("failed to get password-cmd flag: %w", err)
+	}
+
+	return o, nil
 }
 
 // parseUnpackOpts parses unpack command flags
@@ -114,14 +247,23 @@ func parseUnpackOpts(cmd *cobra.Command) (*UnpackOpts, error) {
 	if o.Archive, err = cmd.Flags().GetString("file"); err != nil {
 		return nil, fmt.Errorf("failed to get file flag: %w", err)
 	}
+	if o.Tag, err = cmd.Flags().GetString("tag"); err != nil {
+		return nil, fmt.Errorf("failed to get tag flag: %w", err)
+	}
+	if o.Workspace, err = cmd.Flags().GetString("workspace"); err != nil {
+		return nil, fmt.Errorf("failed to get workspace flag: %w", err)
+	}
+	if o.Profile, err = cmd.Flags().GetString("profile"); err != nil {
+		return nil, fmt.Errorf("failed to get profile flag: %w", err)
+	}
 	if o.Target, err = cmd.Flags().GetString("target"); err != nil {
 		return nil, fmt.Errorf("failed to get target flag: %w", err)
 	}
 	if o.Target == "" {
 		o.Target = "."
 	}
-	if o.PassEnv, err = cmd.Flags().GetString("password-env"); err != nil {
-		return nil, fmt.Errorf("failed to get password-env flag: %w", err)
+	if o.Password, err = parsePasswordOpts(cmd); err != nil {
+		return nil, err
 	}
 	if o.Overwrite, err = cmd.Flags().GetBool("overwrite"); err != nil {
 		return nil, fmt.Errorf("failed to get overwrite flag: %w", err)
@@ -144,6 +286,9 @@ func parseUnpackOpts(cmd *cobra.Command) (*UnpackOpts, error) {
 	if o.Exclude, err = cmd.Flags().GetStringSlice("exclude"); err != nil {
 		return nil, fmt.Errorf("failed to get exclude flag: %w", err)
 	}
+	if o.IgnoreExpiry, err = cmd.Flags().GetBool("ignore-expiry"); err != nil {
+		return nil, fmt.Errorf("failed to get ignore-expiry flag: %w", err)
+	}
 
 	return o, nil
 }
@@ -167,8 +312,8 @@ func parsePackOpts(cmd *cobra.Command) (*PackOpts, error) {
 	} else if !filepath.IsAbs(o.Output) {
 		o.Output = filepath.Join(config.GetGoingEnvDir(), o.Output)
 	}
-	if o.PassEnv, err = cmd.Flags().GetString("password-env"); err != nil {
-		return nil, fmt.Errorf("failed to get password-env flag: %w", err)
+	if o.Password, err = parsePasswordOpts(cmd); err != nil {
+		return nil, err
 	}
 	if o.Depth, err = cmd.Flags().GetInt("depth"); err != nil {
 		return nil, fmt.Errorf("failed to get depth flag: %w", err)
@@ -179,6 +324,38 @@ func parsePackOpts(cmd *cobra.Command) (*PackOpts, error) {
 	if o.Exclude, err = cmd.Flags().GetStringSlice("exclude"); err != nil {
 		return nil, fmt.Errorf("failed to get exclude flag: %w", err)
 	}
+	if o.Symlinks, err = cmd.Flags().GetString("symlinks"); err != nil {
+		return nil, fmt.Errorf("failed to get symlinks flag: %w", err)
+	}
+	if o.Workspaces, err = cmd.Flags().GetBool("workspaces"); err != nil {
+		return nil, fmt.Errorf("failed to get workspaces flag: %w", err)
+	}
+	if o.Profile, err = cmd.Flags().GetString("profile"); err != nil {
+		return nil, fmt.Errorf("failed to get profile flag: %w", err)
+	}
+	if o.Generate, err = cmd.Flags().GetBool("generate-password"); err != nil {
+		return nil, fmt.Errorf("failed to get generate-password flag: %w", err)
+	}
+	if o.SignKey, err = cmd.Flags().GetString("sign"); err != nil {
+		return nil, fmt.Errorf("failed to get sign flag: %w", err)
+	}
+	if o.Name, err = cmd.Flags().GetString("name"); err != nil {
+		return nil, fmt.Errorf("failed to get name flag: %w", err)
+	}
+	if o.Tags, err = cmd.Flags().GetStringSlice("tag"); err != nil {
+		return nil, fmt.Errorf("failed to get tag flag: %w", err)
+	}
+	for _, tag := range o.Tags {
+		if tagErr := catalog.ValidateTag(tag); tagErr != nil {
+			return nil, newUsageError("%v", tagErr)
+		}
+	}
+	if o.Note, err = cmd.Flags().GetString("note"); err != nil {
+		return nil, fmt.Errorf("failed to get note flag: %w", err)
+	}
+	if o.NotBefore, o.ExpiresAt, err = parseValidityFlags(cmd, time.Now()); err != nil {
+		return nil, err
+	}
 	if o.Verbose, err = cmd.Flags().GetBool("verbose"); err != nil {
 		return nil, fmt.Errorf("failed to get verbose flag: %w", err)
 	}
@@ -189,6 +366,58 @@ func parsePackOpts(cmd *cobra.Command) (*PackOpts, error) {
 	return o, nil
 }
 
+// parseValidityFlags parses the --not-before and --expires flags of pack
+func parseValidityFlags(cmd *cobra.Command, now time.Time) (notBefore, expiresAt *time.Time, err error) {
+	for _, f := range []struct {
+		name     string
+		endOfDay bool
+		dest     **time.Time
+	}{
+		{"not-before", false, &notBefore},
+		{"expires", true, &expiresAt},
+	} {
+		value, flagErr := cmd.Flags().GetString(f.name)
+		if flagErr != nil {
+			return nil, nil, fmt.Errorf("failed to get %s flag: %w", f.name, flagErr)
+		}
+		if value == "" {
+			continue
+		}
+		t, parseErr := parseValidityTime(value, now, f.endOfDay)
+		if parseErr != nil {
+			return nil, nil, newUsageError("invalid --%s: %v", f.name, parseErr)
+		}
+		*f.dest = &t
+	}
+
+	switch {
+	case expiresAt != nil && !expiresAt.After(now):
+		return nil, nil, newUsageError("--expires %s is in the past", expiresAt.Local().Format(constants.DateTimeFormat))
+	case expiresAt != nil && notBefore != nil && !expiresAt.After(*notBefore):
+		return nil, nil, newUsageError("--expires must be later than --not-before")
+	}
+	return notBefore, expiresAt, nil
+}
+
+// parseValidityTime parses a local date, a local "YYYY-MM-DD HH:MM:SS"
+// time or an age from now such as 30d. A date alone means the end of that
+// day when endOfDay is set, else its start. The result is in UTC.
+func parseValidityTime(value string, now time.Time, endOfDay bool) (time.Time, error) {
+	if t, err := time.ParseInLocation(constants.DateTimeFormat, value, time.Local); err == nil {
+		return t.UTC(), nil
+	}
+	if day, err := time.ParseInLocation(constants.DateFormat, value, time.Local); err == nil {
+		if endOfDay {
+			day = day.AddDate(0, 0, 1)
+		}
+		return day.UTC(), nil
+	}
+	if age, err := retention.ParseAge(value); err == nil {
+		return now.Add(age).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("%q: want YYYY-MM-DD, \"YYYY-MM-DD HH:MM:SS\" or an age such as 30d", value)
+}
+
 // parseListOpts parses list command flags
 func parseListOpts(cmd *cobra.Command) (*ListOpts, error) {
 	o := &ListOpts{}
@@ -197,8 +426,8 @@ func parseListOpts(cmd *cobra.Command) (*ListOpts, error) {
 	if o.Archive, err = cmd.Flags().GetString("file"); err != nil {
 		return nil, fmt.Errorf("failed to get file flag: %w", err)
 	}
-	if o.PassEnv, err = cmd.Flags().GetString("password-env"); err != nil {
-		return nil, fmt.Errorf("failed to get password-env flag: %w", err)
+	if o.Password, err = parsePasswordOpts(cmd); err != nil {
+		return nil, err
 	}
 	if o.All, err = cmd.Flags().GetBool("all"); err != nil {
 		return nil, fmt.Errorf("failed to get all flag: %w", err)
@@ -241,6 +470,7 @@ func buildScanOpts(p *PackOpts, cfg *types.Config) *types.ScanOptions {
 		MaxDepth:        p.Depth,
 		Patterns:        p.Include,
 		ExcludePatterns: p.Exclude,
+		SymlinkPolicy:   p.Symlinks,
 	}
 
 	if opts.MaxDepth == 0 {
@@ -254,6 +484,9 @@ func buildScanOpts(p *PackOpts, cfg *types.Config) *types.ScanOptions {
 	} else {
 		opts.ExcludePatterns = append(opts.ExcludePatterns, cfg.ExcludePatterns...)
 	}
+	if opts.SymlinkPolicy == "" {
+		opts.SymlinkPolicy = cfg.SymlinkPolicy
+	}
 
 	return opts
 }
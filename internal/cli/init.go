@@ -2,6 +2,7 @@ package cli
 
 import (
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 
@@ -36,6 +37,14 @@ Examples:
 	return cmd
 }
 
+// initResult is the JSON result of the init command
+type initResult struct {
+	Directory          string `json:"directory"`
+	ArchiveDir         string `json:"archive_dir"`
+	ConfigPath         string `json:"config_path"`
+	AlreadyInitialized bool   `json:"already_initialized"`
+}
+
 // runInitCommand executes the init command
 func runInitCommand(cmd *cobra.Command, args []string) error {
 	out := NewOutput(appVersion)
@@ -55,6 +64,7 @@ func runInitCommand(cmd *cobra.Command, args []string) error {
 
 	// Check if already initialized
 	if config.IsInitialized() && !force {
+		setResultData(newInitResult(config.NewManager(), true))
 		out.Warning("goingenv is already initialized in this directory")
 		out.Hint("Use 'goingenv init --force' to reinitialize")
 		return nil
@@ -70,9 +80,10 @@ func runInitCommand(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("initialization failed: %w", initErr)
 	}
 
-	// Ensure configuration exists in home directory
+	// Ensure configuration exists in home directory. Only the user layer is
+	// loaded so project and env overrides are never written back to it.
 	configMgr := config.NewManager()
-	cfg, err := configMgr.Load()
+	cfg, err := configMgr.LoadUser()
 	if err != nil {
 		out.Error("Failed to load configuration")
 		return fmt.Errorf("configuration failed: %w", err)
@@ -84,6 +95,8 @@ func runInitCommand(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("save failed: %w", err)
 	}
 
+	setResultData(newInitResult(configMgr, false))
+
 	if verbose {
 		out.Success("Created .goingenv/")
 		out.Blank()
@@ -98,3 +111,14 @@ func runInitCommand(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// newInitResult describes the initialized project
+func newInitResult(mgr *config.Manager, already bool) initResult {
+	cwd, _ := os.Getwd() //nolint:errcheck // best effort
+	return initResult{
+		Directory:          cwd,
+		ArchiveDir:         config.GetGoingEnvDir(),
+		ConfigPath:         mgr.UserConfigPath(),
+		AlreadyInitialized: already,
+	}
+}
@@ -7,11 +7,13 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 
 	"goingenv/internal/config"
 	"goingenv/internal/constants"
+	"goingenv/internal/signing"
 	"goingenv/pkg/password"
 	"goingenv/pkg/types"
 	"goingenv/pkg/utils"
@@ -33,12 +35,15 @@ The list command will:
 Examples:
   goingenv list -f backup.enc                           # Interactive password prompt
   goingenv list --password-env MY_PASSWORD --all        # List all archives with env password
-  goingenv list -f archive.enc --pattern "*.env.prod*"  # Filter files by pattern`,
+  goingenv list -f archive.enc --pattern "*.env.prod*"  # Filter files by pattern
+  goingenv list -f latest                               # List the newest archive
+
+-f also takes the archive references described in 'goingenv unpack --help'.`,
 		RunE: runListCommand,
 	}
 
-	cmd.Flags().String("password-env", "", "Read password from environment variable")
-	cmd.Flags().StringP("file", "f", "", "Archive file to list (required unless --all is used)")
+	addPasswordFlags(cmd)
+	cmd.Flags().StringP("file", "f", "", "Archive file or reference to list (required unless --all is used)")
 	cmd.Flags().Bool("all", false, "List contents of all available archives")
 	cmd.Flags().BoolP("verbose", "v", false, "Show detailed file information")
 	cmd.Flags().Bool("sizes", false, "Show file sizes in detailed format")
@@ -70,7 +75,7 @@ func runListCommand(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	passwordOpts := password.Options{PasswordEnv: opts.PassEnv}
+	passwordOpts := opts.Password
 
 	if opts.All {
 		return listAllArchives(out, app, passwordOpts, opts.Verbose)
@@ -81,43 +86,66 @@ func runListCommand(cmd *cobra.Command, args []string) error {
 		out.Blank()
 		out.Error("Archive file is required")
 		out.Hint("Use -f flag or --all to list all archives")
-		return fmt.Errorf("archive file is required")
+		return newUsageError("archive file is required")
 	}
 
-	if _, statErr := os.Stat(opts.Archive); os.IsNotExist(statErr) {
+	archiveRef := opts.Archive
+	if opts.Archive, err = pickArchive(app, archiveRef); err != nil {
 		out.Header()
 		out.Blank()
-		out.Error(fmt.Sprintf("Archive not found: %s", opts.Archive))
-		return fmt.Errorf("archive not found: %s", opts.Archive)
+		out.Error(err.Error())
+		return err
 	}
 
 	if validateErr := password.ValidatePasswordOptions(passwordOpts); validateErr != nil {
-		return fmt.Errorf("invalid password options: %w", validateErr)
+		return newUsageError("invalid password options: %v", validateErr)
 	}
 
-	key, err := password.GetPassword(passwordOpts)
+	out.Header()
+	out.Blank()
+	echoArchive(out, archiveRef, opts.Archive)
+
+	signer, err := checkArchiveSignature(out, app, opts.Archive)
+	if err != nil {
+		return err
+	}
+
+	key, err := readArchivePass(app, passwordOpts)
 	if err != nil {
-		out.Header()
-		out.Blank()
 		out.Error(fmt.Sprintf("Failed to get password: %v", err))
 		return fmt.Errorf("failed to get password: %w", err)
 	}
 	defer password.ClearPassword(&key)
 
-	out.Header()
-	out.Blank()
-
 	archive, err := app.Archiver.List(opts.Archive, key)
 	if err != nil {
 		out.Error("Failed to read archive (check password)")
 		out.Hint("Check your password and try again")
-		return fmt.Errorf("failed to read archive")
+		return fmt.Errorf("failed to read archive: %w", err)
 	}
 
 	// Archive info
 	out.Section(filepath.Base(opts.Archive))
 	out.Indent(fmt.Sprintf("Created: %s", archive.CreatedAt.Format(constants.DateTimeFormat)))
+	if archive.UpdatedAt != nil {
+		out.Indent(fmt.Sprintf("Updated: %s", archive.UpdatedAt.Format(constants.DateTimeFormat)))
+	}
+	if archive.NotBefore != nil {
+		out.Indent(fmt.Sprintf("Not before: %s", archive.NotBefore.Local().Format(constants.DateTimeFormat)))
+	}
+	if archive.ExpiresAt != nil {
+		out.Indent(fmt.Sprintf("Expires: %s", archive.ExpiresAt.Local().Format(constants.DateTimeFormat)))
+	}
+	if validityErr := archive.CheckValidity(time.Now()); validityErr != nil {
+		out.Warning(validityErr.Error())
+	}
 	out.Indent(fmt.Sprintf("Version: %s", archive.Version))
+	if archive.Profile != "" {
+		out.Indent(fmt.Sprintf("Profile: %s", archive.Profile))
+	}
+	if signer != "" {
+		out.Indent(fmt.Sprintf("Signer: %s", signer))
+	}
 	out.Blank()
 
 	filesToShow := archive.Files
@@ -134,6 +162,24 @@ func runListCommand(cmd *cobra.Command, args []string) error {
 		filesToShow = filesToShow[:opts.Limit]
 	}
 
+	if jsonOutput() {
+		setResultData(listResult{
+			Archive:   opts.Archive,
+			CreatedAt: archive.CreatedAt,
+			UpdatedAt: archive.UpdatedAt,
+			NotBefore: archive.NotBefore,
+			ExpiresAt: archive.ExpiresAt,
+			Validity:  archive.Validity(time.Now()),
+			Version:   archive.Version,
+			Profile:   archive.Profile,
+			Signer:    signer,
+			Files:     fileResults(filesToShow),
+			Count:     len(filesToShow),
+			TotalSize: sumFileSizes(filesToShow),
+		})
+		return nil
+	}
+
 	switch opts.Format {
 	case "json":
 		return displayFilesJSON(filesToShow)
@@ -155,6 +201,36 @@ func runListCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// listResult is the JSON result of listing one archive
+type listResult struct {
+	Archive   string       `json:"archive"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt *time.Time   `json:"updated_at,omitempty"`
+	NotBefore *time.Time   `json:"not_before,omitempty"`
+	ExpiresAt *time.Time   `json:"expires_at,omitempty"`
+	Validity  string       `json:"validity,omitempty"` // see types.Archive.Validity
+	Version   string       `json:"version"`
+	Profile   string       `json:"profile,omitempty"`
+	Signer    string       `json:"signer,omitempty"`
+	Files     []fileResult `json:"files"`
+	Count     int          `json:"count"`
+	TotalSize int64        `json:"total_size"`
+}
+
+// listAllResult is the JSON result of 'list --all'
+type listAllResult struct {
+	Archives []listedArchive `json:"archives"`
+}
+
+// listedArchive is one archive in 'list --all', with contents when readable
+type listedArchive struct {
+	archiveResult
+	Signature  string `json:"signature"` // trusted signer name, "unsigned", "untrusted" or "invalid"
+	Files      *int   `json:"files,omitempty"`
+	TotalSize  *int64 `json:"total_size,omitempty"`
+	Unreadable bool   `json:"unreadable,omitempty"`
+}
+
 // listAllArchives lists contents of all available archives
 func listAllArchives(out *Output, app *types.App, passwordOpts password.Options, verbose bool) error { //nolint:unparam // error return kept for consistency
 	archives, err := app.Archiver.GetAvailableArchives("")
@@ -177,40 +253,84 @@ func listAllArchives(out *Output, app *types.App, passwordOpts password.Options,
 	out.Section(fmt.Sprintf("Archives (%d)", len(archives)))
 	out.Blank()
 
+	res := listAllResult{Archives: []listedArchive{}}
+	setResultData(&res)
+
+	// Read the password once: stdin and descriptor sources can only be read once
+	key := ""
+	if verbose && passwordOpts.NonInteractive() {
+		var keyErr error
+		if key, keyErr = password.GetPassword(passwordOpts); keyErr != nil {
+			out.Warning(fmt.Sprintf("Failed to get password: %v", keyErr))
+		}
+	} else if verbose {
+		key, _ = agentPassword(app)
+	}
+	defer password.ClearPassword(&key)
+
+	trusted, signersErr := signing.LoadSigners(signersPath())
+	if signersErr != nil {
+		out.Warning(signersErr.Error())
+	}
+
 	for i, archivePath := range archives {
 		name := filepath.Base(archivePath)
 		info, statErr := os.Stat(archivePath)
 		if statErr != nil {
 			continue
 		}
+		listed := listedArchive{archiveResult: archiveResult{
+			Path:     archivePath,
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+			Profile:  config.ProfileForArchive(app.Config, archivePath),
+		}}
 
 		out.Printf("  [%d] %s\n", i+1, name)
 		out.Indent(fmt.Sprintf("    Size: %s", utils.FormatSize(info.Size())))
 		out.Indent(fmt.Sprintf("    Modified: %s", info.ModTime().Format(constants.DateTimeFormat)))
-
-		if verbose && passwordOpts.PasswordEnv != "" {
-			if key, keyErr := password.GetPassword(passwordOpts); keyErr == nil {
-				archive, listErr := app.Archiver.List(archivePath, key)
-				password.ClearPassword(&key)
-				if listErr == nil {
-					out.Indent(fmt.Sprintf("    Files: %d", len(archive.Files)))
-					out.Indent(fmt.Sprintf("    Total size: %s", utils.FormatSize(archive.TotalSize)))
-				} else {
-					out.Indent("    Status: Cannot read (wrong password or corrupted)")
-				}
+		listed.Signature = describeSignature(archivePath, trusted)
+		out.Indent(fmt.Sprintf("    Signature: %s", listed.Signature))
+
+		if key != "" {
+			archive, listErr := app.Archiver.List(archivePath, key)
+			if listErr == nil {
+				count := len(archive.Files)
+				listed.Files, listed.TotalSize = &count, &archive.TotalSize
+				out.Indent(fmt.Sprintf("    Files: %d", len(archive.Files)))
+				out.Indent(fmt.Sprintf("    Total size: %s", utils.FormatSize(archive.TotalSize)))
+			} else {
+				listed.Unreadable = true
+				out.Indent("    Status: Cannot read (wrong password or corrupted)")
 			}
 		}
 
+		res.Archives = append(res.Archives, listed)
 		out.Blank()
 	}
 
-	if passwordOpts.PasswordEnv == "" && verbose {
-		out.Hint("Provide a password with --password-env to see detailed archive information")
+	if !passwordOpts.NonInteractive() && verbose && key == "" {
+		out.Hint("Provide a password with --password-env, --password-file or --password-cmd to see detailed archive information")
 	}
 
 	return nil
 }
 
+// describeSignature summarizes an archive's signature for list --all
+func describeSignature(archivePath string, trusted []signing.Signer) string {
+	v, err := signing.VerifyArchive(archivePath, trusted)
+	switch {
+	case err != nil:
+		return "invalid"
+	case v.Trusted:
+		return v.Signer
+	case v.Signed:
+		return "untrusted"
+	default:
+		return "unsigned"
+	}
+}
+
 // displayFilesTable displays files in table format
 func displayFilesTable(out *Output, files []types.EnvFile, opts *ListOpts) {
 	if len(files) == 0 {
@@ -0,0 +1,331 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"goingenv/internal/audit"
+	"goingenv/internal/config"
+	"goingenv/internal/git"
+	"goingenv/internal/signing"
+	"goingenv/pkg/password"
+	"goingenv/pkg/types"
+)
+
+// newGitMergeCommand creates the git-merge command
+func newGitMergeCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "git-merge <base> <ours> <theirs> [<path>]",
+		Short: "Merge two versions of an archive (git merge driver)",
+		Long: `Merge the changes two branches made to an archive. git runs this command
+as the merge driver registered by 'goingenv git setup', passing the common
+ancestor, our version (which receives the result), their version and the
+archive's path in the work tree.
+
+All three versions are decrypted with the same password, from a password
+flag or the key agent; the driver never prompts. Entries changed on one
+side take that side's version, env files changed on both sides are merged
+key by key, and the result is encrypted again with the same password. A
+signature of the archive no longer matches the merged result and is
+removed; sign the archive again once the merge is done.
+
+Entries both sides changed differently keep our version in the archive and
+are written, with conflict markers, as plaintext side files under
+.goingenv/conflicts/<archive>/ (readable only by you, ignored by git). The
+command then fails so git reports a conflict; edit the side files and run
+'goingenv resolve' to finish.
+
+Examples:
+  git config merge.goingenv.driver "goingenv git-merge %O %A %B %P"`,
+		Args: cobra.RangeArgs(3, 4),
+		RunE: runGitMergeCommand,
+	}
+
+	addPasswordFlags(cmd)
+
+	return cmd
+}
+
+// newResolveCommand creates the resolve command
+func newResolveCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "resolve",
+		Short: "Finish an archive merge from its conflict side files",
+		Long: `Write the conflict side files left by 'goingenv git-merge' back into the
+archive and remove them. Every side file must be free of conflict markers;
+delete a side file to remove its entry from the archive.
+
+Examples:
+  goingenv resolve                          # The only archive with conflicts
+  goingenv resolve -f .goingenv/prod.enc --dry-run`,
+		Args: cobra.NoArgs,
+		RunE: runResolveCommand,
+	}
+
+	addEditFlags(cmd, "Archive file with merge conflicts (default: the only one)")
+
+	return cmd
+}
+
+// runGitMergeCommand executes the git-merge command. It reports on standard
+// error only, and fails whenever our version is not the complete merge.
+func runGitMergeCommand(cmd *cobra.Command, args []string) error {
+	paths := make([]string, len(args))
+	for i, arg := range args {
+		abs, err := filepath.Abs(arg)
+		if err != nil {
+			return fmt.Errorf("failed to resolve %s: %w", arg, err)
+		}
+		paths[i] = abs
+	}
+	basePath, oursPath, theirsPath := paths[0], paths[1], paths[2]
+
+	var archivePath string
+	if len(paths) == 4 {
+		archivePath = paths[3]
+		if chdirErr := os.Chdir(mergeProjectDir(archivePath)); chdirErr != nil {
+			return fmt.Errorf("failed to enter project directory: %w", chdirErr)
+		}
+	}
+
+	passwordOpts, err := parsePasswordOpts(cmd)
+	if err != nil {
+		return err
+	}
+	if validateErr := password.ValidatePasswordOptions(passwordOpts); validateErr != nil {
+		return newUsageError("invalid password options: %v", validateErr)
+	}
+
+	app, err := NewApp()
+	if err != nil {
+		return err
+	}
+	key, err := driverPass(app, passwordOpts)
+	if err != nil {
+		return mergeFailed(args, err)
+	}
+	defer password.ClearPassword(&key)
+
+	versions := make([]*git.Version, 3)
+	for i, versionPath := range []string{basePath, oursPath, theirsPath} {
+		// git passes an empty base when the versions share no ancestor
+		if info, statErr := os.Stat(versionPath); i == 0 && statErr == nil && info.Size() == 0 {
+			versions[i] = git.NewVersion(nil, nil)
+			continue
+		}
+		archive, contents, readErr := app.Archiver.Read(versionPath, key)
+		if readErr != nil {
+			return mergeFailed(args, readErr)
+		}
+		versions[i] = git.NewVersion(archive, contents)
+	}
+
+	res := git.Merge(versions[0], versions[1], versions[2])
+	if len(res.Conflicts) > 0 && archivePath == "" {
+		return mergeFailed(args, fmt.Errorf("%w: conflicts can only be recorded when git passes the archive path (%%P)", types.ErrConflict))
+	}
+
+	if len(res.Put) > 0 || len(res.Remove) > 0 {
+		_, updateErr := app.Archiver.Update(types.UpdateOptions{
+			ArchivePath: oursPath,
+			Password:    key,
+			Put:         res.Put,
+			Remove:      res.Remove,
+			Contents:    res.Contents,
+		})
+		if updateErr != nil {
+			return mergeFailed(args, updateErr)
+		}
+		if archivePath != "" {
+			removeMergedSignature(archivePath)
+		}
+		auditMerge(app, oursPath, archivePath, res)
+	}
+	for _, link := range res.KeptLinks {
+		fmt.Fprintf(os.Stderr, "goingenv: %s: kept our version of symlink %s\n", args[len(args)-1], link)
+	}
+
+	if archivePath == "" {
+		return nil
+	}
+	conflictDir := git.ConflictDir(archivePath)
+	if len(res.Conflicts) == 0 {
+		return git.ClearConflicts(conflictDir)
+	}
+	return recordConflicts(archivePath, conflictDir, res.Conflicts)
+}
+
+// mergeProjectDir returns the project directory of an archive in the work
+// tree: the parent of its .goingenv directory, else its own directory
+func mergeProjectDir(archivePath string) string {
+	dir := filepath.Dir(archivePath)
+	if filepath.Base(dir) == config.GetGoingEnvDir() {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+// removeMergedSignature deletes the signature of a merged archive, which
+// no longer matches it and would make resolve and unpack refuse it
+func removeMergedSignature(archivePath string) {
+	if err := os.Remove(signing.SignaturePath(archivePath)); err == nil {
+		fmt.Fprintf(os.Stderr, "goingenv: removed the signature of %s; sign it again with 'goingenv sign -f %s --key <key>'\n",
+			filepath.Base(archivePath), archivePath)
+	}
+}
+
+// auditMerge records a merged archive in the audit log. git hands the driver
+// a temporary copy, so the entry names the archive's work tree path when git
+// passes it, and hashes the merged copy.
+func auditMerge(app *types.App, oursPath, archivePath string, res *git.MergeResult) {
+	if !config.IsInitialized() {
+		return
+	}
+	entry := audit.NewEntry(audit.OpMerge, oursPath, changedFiles(&types.UpdateOptions{Put: res.Put, Remove: res.Remove}))
+	if archivePath != "" {
+		entry.Archive = archivePath
+		if rel, err := filepath.Rel(mergeProjectDir(archivePath), archivePath); err == nil {
+			entry.Archive = rel
+		}
+	}
+	if err := audit.Record(config.GetGoingEnvDir(), app.Config.AuditLog, entry); err != nil {
+		fmt.Fprintf(os.Stderr, "goingenv: audit log not updated: %v\n", err)
+	}
+}
+
+// mergeFailed reports a merge the driver could not do. Our version is left
+// as it was, and the error makes git report a conflict.
+func mergeFailed(args []string, err error) error {
+	fmt.Fprintf(os.Stderr, "goingenv: cannot merge %s: %v\n", args[len(args)-1], err)
+	return err
+}
+
+// recordConflicts writes the conflict side files for an archive and fails
+// the merge
+func recordConflicts(archivePath, conflictDir string, conflicts map[string][]byte) error {
+	if _, err := git.EnsureLine(filepath.Join(filepath.Dir(archivePath), ".gitignore"), git.ConflictsDir+"/"); err != nil {
+		return err
+	}
+	if err := git.WriteConflicts(conflictDir, conflicts); err != nil {
+		return err
+	}
+
+	entries := make([]string, 0, len(conflicts))
+	for entry := range conflicts {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+
+	fmt.Fprintf(os.Stderr, "goingenv: merge conflicts in %s:\n", archivePath)
+	for _, entry := range entries {
+		fmt.Fprintf(os.Stderr, "  %s\n", filepath.Join(conflictDir, filepath.FromSlash(entry)))
+	}
+	fmt.Fprintln(os.Stderr, "goingenv: edit these files, then run 'goingenv resolve' and 'git add' the archive")
+	return fmt.Errorf("%w: %s", types.ErrConflict, strings.Join(entries, ", "))
+}
+
+// runResolveCommand executes the resolve command
+func runResolveCommand(cmd *cobra.Command, args []string) error {
+	ref, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return fmt.Errorf("failed to get file flag: %w", err)
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("failed to get dry-run flag: %w", err)
+	}
+
+	if ref == "" {
+		pending, pendingErr := git.PendingConflicts(config.GetGoingEnvDir())
+		if pendingErr != nil {
+			return pendingErr
+		}
+		switch len(pending) {
+		case 0:
+			return fmt.Errorf("no merge conflicts to resolve")
+		case 1:
+			ref = pending[0]
+		default:
+			return newUsageError("several archives have merge conflicts (%s); choose one with -f", strings.Join(pending, ", "))
+		}
+		if setErr := cmd.Flags().Set("file", ref); setErr != nil {
+			return setErr
+		}
+	}
+
+	var conflictDir string
+	plan := func(_ *Output, app *types.App, _ *cobra.Command, _ []string, archive *types.Archive) (*types.UpdateOptions, error) {
+		archivePath, pickErr := pickArchive(app, ref)
+		if pickErr != nil {
+			return nil, pickErr
+		}
+		conflictDir = git.ConflictDir(archivePath)
+		return planResolve(conflictDir, archive)
+	}
+	if err := runEditCommand(cmd, args, plan); err != nil || dryRun {
+		return err
+	}
+
+	out := NewOutput(appVersion)
+	if err := git.ClearConflicts(conflictDir); err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	out.Success(fmt.Sprintf("Removed conflict files in %s", conflictDir))
+	out.Hint(fmt.Sprintf("Run 'git add %s' and commit to conclude the merge", ref))
+	return nil
+}
+
+// planResolve turns the resolved side files in conflictDir into changes to
+// an archive
+func planResolve(conflictDir string, archive *types.Archive) (*types.UpdateOptions, error) {
+	resolution, err := git.ReadConflicts(conflictDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(resolution.Unresolved) > 0 {
+		return nil, fmt.Errorf("%w: conflict markers remain in %s", types.ErrConflict, strings.Join(resolution.Unresolved, ", "))
+	}
+
+	stored := make(map[string]types.EnvFile, len(archive.Files))
+	for _, file := range archive.Files {
+		stored[file.RelativePath] = file
+	}
+
+	opts := &types.UpdateOptions{Contents: make(map[string][]byte)}
+	entries := make([]string, 0, len(resolution.Resolved))
+	for entry := range resolution.Resolved {
+		entries = append(entries, entry)
+	}
+	sort.Strings(entries)
+	for _, entry := range entries {
+		content, readErr := os.ReadFile(resolution.Resolved[entry])
+		if readErr != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", resolution.Resolved[entry], readErr)
+		}
+		file, ok := stored[entry]
+		if !ok {
+			file = types.EnvFile{Path: entry, RelativePath: entry}
+		}
+		checksum := fmt.Sprintf("%x", sha256.Sum256(content))
+		if ok && file.LinkTarget == "" && file.Checksum == checksum {
+			continue
+		}
+		file.Size, file.Checksum, file.ModTime, file.LinkTarget = int64(len(content)), checksum, time.Now(), ""
+		opts.Put = append(opts.Put, file)
+		opts.Contents[entry] = content
+	}
+	for _, entry := range resolution.Removed {
+		if _, ok := stored[entry]; ok {
+			opts.Remove = append(opts.Remove, entry)
+		}
+	}
+	return opts, nil
+}
@@ -36,18 +36,25 @@ type Output struct {
 	stderr    io.Writer
 	useColors bool
 	version   string
+	record    bool // also record warnings and errors in the JSON result
 }
 
-// NewOutput creates a new Output instance with TTY detection
+// NewOutput creates a new Output instance with TTY detection. In JSON output
+// mode human-readable output goes to stderr, leaving stdout to the result.
 func NewOutput(version string) *Output {
-	output := termenv.NewOutput(os.Stdout)
+	stdout := io.Writer(os.Stdout)
+	if jsonOutput() {
+		stdout = os.Stderr
+	}
+	output := termenv.NewOutput(stdout)
 	useColors := output.Profile != termenv.Ascii
 
 	return &Output{
-		stdout:    os.Stdout,
+		stdout:    stdout,
 		stderr:    os.Stderr,
 		useColors: useColors,
 		version:   version,
+		record:    jsonOutput(),
 	}
 }
 
@@ -83,6 +90,9 @@ func (o *Output) Success(msg string) {
 
 // Warning prints a warning message: [!] message
 func (o *Output) Warning(msg string) {
+	if o.record {
+		recordWarning(msg)
+	}
 	if o.useColors {
 		fmt.Fprintf(o.stdout, "%s %s\n", warningStyle.Render("[!]"), msg)
 	} else {
@@ -92,6 +102,9 @@ func (o *Output) Warning(msg string) {
 
 // Error prints an error message to stderr: [x] message
 func (o *Output) Error(msg string) {
+	if o.record {
+		recordErrorDetail(msg)
+	}
 	if o.useColors {
 		fmt.Fprintf(o.stderr, "%s %s\n", errorStyleCLI.Render("[x]"), msg)
 	} else {
@@ -1,12 +1,16 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"time"
 
 	"github.com/spf13/cobra"
 
+	"goingenv/internal/audit"
+	"goingenv/internal/signing"
+	"goingenv/pkg/password"
 	"goingenv/pkg/types"
 	"goingenv/pkg/utils"
 )
@@ -27,23 +31,83 @@ The pack command will:
 Examples:
   goingenv pack                                    # Interactive password prompt
   goingenv pack --password-env MY_PASSWORD        # Read from environment variable
+  goingenv pack --password-stdin < secret.txt     # Read from standard input
+  goingenv pack --password-fd 3 3<secret.txt      # Read from a file descriptor
   goingenv pack -d /path/to/project -o backup.enc # Specify directory and output
-  goingenv pack -d . --depth 5                    # Custom scan depth`,
+  goingenv pack -d . --depth 5                    # Custom scan depth
+  goingenv pack --symlinks preserve-as-link       # Keep symlinked env files as links
+  goingenv pack --workspaces                      # One archive per monorepo workspace
+  goingenv pack --profile prod                    # Use the "prod" profile from project config
+  goingenv pack --generate-password               # Create a diceware passphrase and show it
+  goingenv pack --sign alice                      # Sign the archive with a 'goingenv keygen' key
+  goingenv pack --tag release-42 --note "Before the DB migration"  # Tag and describe the archive
+  goingenv pack --expires 2026-12-31                # Stop unpacking after the end of that day
+
+Every archive is recorded in the unencrypted index .goingenv/index.json with
+its name, tags, note, creator, file count and creation time. Never put
+secrets in names, tags or notes.
+
+--expires and --not-before are stored in the encrypted, authenticated
+metadata. unpack refuses the archive outside that period unless given
+--ignore-expiry. A date alone means the end of that day for --expires and
+its start for --not-before; an age such as 30d or 12h counts from now.
+This guards against using stale credentials by accident; it is not access
+control, since anyone with the password can unpack with --ignore-expiry.
+
+Workspaces are read from the "workspaces" config list, or discovered from
+go.work, pnpm-workspace.yaml and package.json workspaces.
+
+Password sources are tried in this order: --password-file, --password-fd,
+--password-stdin, --password-cmd, --password-env, then an interactive prompt.
+With --generate-password a new passphrase is generated and displayed instead.`,
 		RunE: runPackCommand,
 	}
 
-	cmd.Flags().String("password-env", "", "Read password from environment variable")
+	addPasswordFlags(cmd)
 	cmd.Flags().StringP("directory", "d", "", "Directory to scan (default: current directory)")
 	cmd.Flags().StringP("output", "o", "", "Output archive name (default: auto-generated with timestamp)")
 	cmd.Flags().IntP("depth", "", 0, "Maximum directory depth to scan (default: from config)")
 	cmd.Flags().StringSliceP("include", "i", nil, "Additional file patterns to include")
 	cmd.Flags().StringSliceP("exclude", "e", nil, "Additional patterns to exclude")
+	cmd.Flags().String("symlinks", "", "Symlink policy: skip, follow, preserve-as-link (default: from config)")
+	cmd.Flags().Bool("workspaces", false, "Create one archive per workspace plus an index manifest")
+	cmd.Flags().String("profile", "", "Named profile from config (patterns, archive name, password source)")
+	cmd.Flags().Bool("generate-password", false, "Generate a diceware passphrase, display it and use it for the archive")
+	cmd.Flags().String("sign", "", "Sign the archive with this key file or key name")
+	cmd.Flags().String("name", "", "Name for the archive in the archive index")
+	cmd.Flags().StringSlice("tag", nil, "Tag the archive in the archive index (repeatable)")
+	cmd.Flags().String("note", "", "Note for the archive in the archive index (not encrypted)")
+	cmd.Flags().String("expires", "", "Refuse to unpack the archive after this date, time or age (2026-12-31, 30d)")
+	cmd.Flags().String("not-before", "", "Refuse to unpack the archive before this date, time or age")
 	cmd.Flags().BoolP("dry-run", "", false, "Show what would be packed without creating archive")
 	cmd.Flags().BoolP("verbose", "v", false, "Show detailed information during packing")
 
 	return cmd
 }
 
+// packResult is the JSON result of the pack command
+type packResult struct {
+	Archive     string       `json:"archive,omitempty"`
+	Profile     string       `json:"profile,omitempty"`
+	DryRun      bool         `json:"dry_run"`
+	Files       []fileResult `json:"files"`
+	TotalSize   int64        `json:"total_size"`
+	ArchiveSize int64        `json:"archive_size,omitempty"`
+	Signer      string       `json:"signer,omitempty"`
+	DurationMS  int64        `json:"pack_duration_ms,omitempty"`
+}
+
+// newPackResult describes the files selected for packing
+func newPackResult(opts *PackOpts, files []types.EnvFile) *packResult {
+	return &packResult{
+		Archive:   opts.Output,
+		Profile:   opts.Profile,
+		DryRun:    opts.DryRun,
+		Files:     fileResults(files),
+		TotalSize: sumFileSizes(files),
+	}
+}
+
 // runPackCommand executes the pack command
 func runPackCommand(cmd *cobra.Command, args []string) error {
 	out := NewOutput(appVersion)
@@ -64,19 +128,60 @@ func runPackCommand(cmd *cobra.Command, args []string) error {
 	out.Header()
 	out.Blank()
 
-	key, cleanup, err := getPass(opts.PassEnv)
+	if opts.Workspaces && cmd.Flags().Changed("output") {
+		out.Error("--output cannot be combined with --workspaces")
+		return newUsageError("--output cannot be combined with --workspaces")
+	}
+
+	if opts.Workspaces && opts.Profile != "" {
+		out.Error("--profile cannot be combined with --workspaces")
+		return newUsageError("--profile cannot be combined with --workspaces")
+	}
+
+	if opts.Generate && opts.Password.NonInteractive() {
+		out.Error("--generate-password cannot be combined with a password source flag")
+		return newUsageError("--generate-password cannot be combined with a password source flag")
+	}
+
+	if profileErr := applyPackProfile(opts, app.Config, cmd.Flags().Changed("output")); profileErr != nil {
+		out.Error(profileErr.Error())
+		return profileErr
+	}
+
+	// Load the signing key first so a bad key fails before any prompt
+	var signKey *signing.Key
+	if opts.SignKey != "" {
+		if signKey, err = loadSigningKey(opts.SignKey); err != nil {
+			out.Error(err.Error())
+			return err
+		}
+	}
+
+	key, cleanup, err := getPackPass(out, opts, app.Config)
 	if err != nil {
 		out.Error(fmt.Sprintf("Failed to get password: %v", err))
+		var validationErr *types.ValidationError
+		if errors.As(err, &validationErr) {
+			out.Hint("Use a longer passphrase (try several random words), or lower min_password_score with 'goingenv config set'")
+		}
 		return err
 	}
 	defer cleanup()
 
+	if opts.Workspaces {
+		return runWorkspacePack(out, app, opts, key, signKey)
+	}
+
 	files, err := scanPackFiles(out, app, opts)
 	if err != nil {
 		return err
 	}
 
+	res := newPackResult(opts, files)
+	setResultData(res)
+
 	if len(files) == 0 {
+		res.Archive = ""
 		out.Warning("No environment files found")
 		out.Hint("Use 'goingenv status' to see what files are detected")
 		return nil
@@ -94,7 +199,41 @@ func runPackCommand(cmd *cobra.Command, args []string) error {
 		return nil
 	}
 
-	return executePack(out, app, files, opts, key)
+	if packErr := executePack(out, app, files, opts, key, res); packErr != nil {
+		return packErr
+	}
+	recordArchives(out, newCatalogEntry(opts.Output, opts.Name, opts, len(files)))
+	if signKey == nil {
+		removeStaleSignature(out, opts.Output)
+		return nil
+	}
+	if signErr := signArchive(out, signKey, opts.Output); signErr != nil {
+		return signErr
+	}
+	res.Signer = signKey.Name
+	return nil
+}
+
+// getPackPass returns the archive password: a freshly generated passphrase
+// with --generate-password, otherwise one read from the configured source
+func getPackPass(out *Output, opts *PackOpts, cfg *types.Config) (key string, cleanup func(), err error) {
+	if !opts.Generate {
+		return getNewPass(opts.Password, cfg)
+	}
+
+	genOpts := defaultPassgenOpts()
+	key, entropy, err := generatePassword(genOpts)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	out.Action(fmt.Sprintf("Generated password, %s:", describeEntropy(genOpts, entropy)))
+	out.Indent(key)
+	out.Warning("Save this password now; it is not stored anywhere and cannot be recovered")
+	out.Blank()
+
+	cleanup = func() { password.ClearPassword(&key) }
+	return key, cleanup, nil
 }
 
 // scanPackFiles scans for files to pack
@@ -120,6 +259,8 @@ func displayPackFiles(out *Output, files []types.EnvFile, verbose bool) {
 
 	for i, file := range files {
 		switch {
+		case verbose && file.LinkTarget != "":
+			out.ListItem(fmt.Sprintf("%s -> %s (link)", file.RelativePath, file.LinkTarget))
 		case verbose:
 			out.ListItem(fmt.Sprintf("%s (%s)", file.RelativePath, utils.FormatSize(file.Size)))
 		case i < 5:
@@ -134,11 +275,14 @@ func displayPackFiles(out *Output, files []types.EnvFile, verbose bool) {
 }
 
 // executePack performs the actual packing operation
-func executePack(out *Output, app *types.App, files []types.EnvFile, opts *PackOpts, key string) error { //nolint:unparam // error return kept for consistency
+func executePack(out *Output, app *types.App, files []types.EnvFile, opts *PackOpts, key string, res *packResult) error { //nolint:unparam // error return kept for consistency
 	packOpts := types.PackOptions{
 		Files:      files,
 		OutputPath: opts.Output,
 		Password:   key,
+		Profile:    opts.Profile,
+		NotBefore:  opts.NotBefore,
+		ExpiresAt:  opts.ExpiresAt,
 		Description: fmt.Sprintf("Environment files archive created on %s from %s",
 			time.Now().Format("2006-01-02 15:04:05"), opts.Dir),
 	}
@@ -157,9 +301,16 @@ func executePack(out *Output, app *types.App, files []types.EnvFile, opts *PackO
 	}
 
 	out.Success(fmt.Sprintf("Created %s", opts.Output))
+	auditArchive(out, app, audit.OpPack, opts.Output, files)
+
+	res.DurationMS = duration.Milliseconds()
+	info, statErr := os.Stat(opts.Output)
+	if statErr == nil {
+		res.ArchiveSize = info.Size()
+	}
 
 	if opts.Verbose {
-		if info, statErr := os.Stat(opts.Output); statErr == nil {
+		if statErr == nil {
 			out.Indent(fmt.Sprintf("Files: %d", len(files)))
 			out.Indent(fmt.Sprintf("Size: %s", utils.FormatSize(info.Size())))
 			out.Indent(fmt.Sprintf("Time: %v", duration.Round(time.Millisecond)))
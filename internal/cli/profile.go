@@ -0,0 +1,99 @@
+package cli
+
+import (
+	"fmt"
+
+	"goingenv/internal/catalog"
+	"goingenv/internal/config"
+	"goingenv/pkg/password"
+	"goingenv/pkg/types"
+)
+
+// applyPackProfile fills pack options the user did not set from the profile.
+// Explicit flags always win.
+func applyPackProfile(opts *PackOpts, cfg *types.Config, outputSet bool) error {
+	if opts.Profile == "" {
+		return nil
+	}
+	profile, err := config.GetProfile(cfg, opts.Profile)
+	if err != nil {
+		return err
+	}
+
+	if len(opts.Include) == 0 {
+		opts.Include = profile.EnvPatterns
+	}
+	opts.Exclude = append(opts.Exclude, profile.ExcludePatterns...)
+	applyProfilePassword(&opts.Password, profile)
+	if !outputSet {
+		opts.Output = config.GetProfileArchivePath(opts.Profile, profile)
+	}
+	return nil
+}
+
+// applyUnpackProfile fills unpack options the user did not set from the
+// profile and picks the profile's most recent archive
+func applyUnpackProfile(app *types.App, opts *UnpackOpts, targetSet bool) error {
+	if opts.Profile == "" {
+		return nil
+	}
+	profile, err := config.GetProfile(app.Config, opts.Profile)
+	if err != nil {
+		return err
+	}
+
+	applyProfilePassword(&opts.Password, profile)
+	if !targetSet && profile.Target != "" {
+		opts.Target = profile.Target
+	}
+	if opts.Archive == "" {
+		archive, latestErr := latestProfileArchive(app, opts.Profile)
+		if latestErr != nil {
+			return latestErr
+		}
+		opts.Archive = archive
+	}
+	return nil
+}
+
+// applyProfilePassword uses the profile's password sources when no password
+// flag was given
+func applyProfilePassword(opts *password.Options, profile types.Profile) {
+	if opts.NonInteractive() {
+		return
+	}
+	opts.PasswordFile = profile.PasswordFile
+	opts.PasswordFD = profile.PasswordFD
+	opts.PasswordCmd = profile.PasswordCmd
+	opts.PasswordEnv = profile.PasswordEnv
+}
+
+// latestProfileArchive returns the newest archive produced by a profile
+func latestProfileArchive(app *types.App, name string) (string, error) {
+	archives, err := app.Archiver.GetAvailableArchives("")
+	if err != nil {
+		return "", fmt.Errorf("failed to find archives: %w", err)
+	}
+	index, err := catalog.Load(catalogPath())
+	if err != nil {
+		return "", err
+	}
+
+	for _, archive := range index.Newest(archives) {
+		if config.ProfileForArchive(app.Config, archive) == name {
+			return archive, nil
+		}
+	}
+	return "", fmt.Errorf("%w: no archives for profile %q. Run 'goingenv pack --profile %s' first", types.ErrArchiveNotFound, name, name)
+}
+
+// groupArchivesByProfile buckets archive paths by the profile that produced
+// them; archives matching no profile are grouped under ""
+func groupArchivesByProfile(cfg *types.Config, archives []string) map[string][]string {
+	groups := make(map[string][]string)
+	for _, archive := range archives {
+		name := config.ProfileForArchive(cfg, archive)
+		groups[name] = append(groups[name], archive)
+	}
+	return groups
+}
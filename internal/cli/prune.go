@@ -0,0 +1,320 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"goingenv/internal/audit"
+	"goingenv/internal/config"
+	"goingenv/internal/constants"
+	"goingenv/internal/retention"
+	"goingenv/internal/signing"
+	"goingenv/pkg/types"
+)
+
+// newPruneCommand creates the prune command
+func newPruneCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove old archives by retention rules",
+		Long: `Remove archives that no retention rule keeps.
+
+Archives are grouped by name without their timestamp (for example every
+archive-<timestamp>.enc, or every prod-<timestamp>.enc), and each group is
+pruned on its own using the creation time recorded inside the archive, so
+prune needs the archive password. Archives that cannot be read are kept.
+
+An archive is kept if any rule keeps it, and the newest archive of each group
+is always kept:
+  --keep-last N     the N newest archives
+  --keep-daily N    the newest archive of each of the last N days
+  --keep-weekly N   the newest archive of each of the last N ISO weeks
+  --older-than AGE  every archive younger than AGE (90d, 12w, 36h); older
+                    archives are removed
+
+Without rule flags, each group uses the "retention" rules of its profile,
+falling back to the top-level "retention" config.
+
+Examples:
+  goingenv prune --keep-last 10 --dry-run          # Preview what would be removed
+  goingenv prune --keep-daily 7 --keep-weekly 8    # Keep a week of dailies and two months of weeklies
+  goingenv prune --older-than 90d                  # Remove archives older than 90 days
+  goingenv prune --profile prod                    # Apply the prod profile's retention rules`,
+		Args: cobra.NoArgs,
+		RunE: runPruneCommand,
+	}
+
+	addPasswordFlags(cmd)
+	cmd.Flags().Int("keep-last", 0, "Keep the N newest archives")
+	cmd.Flags().Int("keep-daily", 0, "Keep the newest archive of each of the last N days")
+	cmd.Flags().Int("keep-weekly", 0, "Keep the newest archive of each of the last N weeks")
+	cmd.Flags().String("older-than", "", "Keep archives younger than this age, e.g. 90d, 12w, 36h")
+	cmd.Flags().String("profile", "", "Only prune archives of this profile")
+	cmd.Flags().Bool("dry-run", false, "Show what would be removed without removing anything")
+
+	return cmd
+}
+
+// pruneResult is the JSON result of the prune command
+type pruneResult struct {
+	DryRun   bool           `json:"dry_run"`
+	Archives []prunedResult `json:"archives"`
+	Removed  int            `json:"removed"`
+}
+
+// prunedResult is the retention decision for one archive
+type prunedResult struct {
+	Path       string     `json:"path"`
+	Series     string     `json:"series"`
+	Profile    string     `json:"profile,omitempty"`
+	CreatedAt  *time.Time `json:"created_at,omitempty"`
+	Keep       bool       `json:"keep"`
+	Reasons    []string   `json:"reasons,omitempty"`
+	Unreadable bool       `json:"unreadable,omitempty"`
+	Validity   string     `json:"validity,omitempty"` // see types.Archive.Validity
+}
+
+// runPruneCommand executes the prune command
+func runPruneCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+
+	app, err := initApp()
+	if err != nil {
+		out.Header()
+		out.Blank()
+		out.Error(err.Error())
+		return err
+	}
+
+	flagPolicy, err := parseRetentionFlags(cmd)
+	if err != nil {
+		return err
+	}
+	profile, err := cmd.Flags().GetString("profile")
+	if err != nil {
+		return fmt.Errorf("failed to get profile flag: %w", err)
+	}
+	dryRun, err := cmd.Flags().GetBool("dry-run")
+	if err != nil {
+		return fmt.Errorf("failed to get dry-run flag: %w", err)
+	}
+	passwordOpts, err := parsePasswordOpts(cmd)
+	if err != nil {
+		return err
+	}
+
+	out.Header()
+	out.Blank()
+
+	if profile != "" {
+		p, profileErr := config.GetProfile(app.Config, profile)
+		if profileErr != nil {
+			out.Error(profileErr.Error())
+			return profileErr
+		}
+		applyProfilePassword(&passwordOpts, p)
+	}
+
+	series, err := pruneSeries(app, profile, flagPolicy)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	if len(series) == 0 {
+		out.Success("Nothing to prune")
+		return nil
+	}
+
+	key, cleanup, err := getPass(app, passwordOpts)
+	if err != nil {
+		out.Error(fmt.Sprintf("Failed to get password: %v", err))
+		return err
+	}
+	defer cleanup()
+
+	res := pruneResult{DryRun: dryRun, Archives: []prunedResult{}}
+	setResultData(&res)
+
+	var remove []string
+	for _, s := range series {
+		decided := decidePrune(out, app, s, key)
+		res.Archives = append(res.Archives, decided...)
+		for _, d := range decided {
+			if !d.Keep {
+				remove = append(remove, d.Path)
+			}
+		}
+	}
+
+	return removePruned(out, app, remove, dryRun, &res)
+}
+
+// parseRetentionFlags returns the retention rules given on the command line,
+// or nil when none were given
+func parseRetentionFlags(cmd *cobra.Command) (*types.Retention, error) {
+	policy := &types.Retention{}
+	var err error
+	if policy.KeepLast, err = cmd.Flags().GetInt("keep-last"); err != nil {
+		return nil, fmt.Errorf("failed to get keep-last flag: %w", err)
+	}
+	if policy.KeepDaily, err = cmd.Flags().GetInt("keep-daily"); err != nil {
+		return nil, fmt.Errorf("failed to get keep-daily flag: %w", err)
+	}
+	if policy.KeepWeekly, err = cmd.Flags().GetInt("keep-weekly"); err != nil {
+		return nil, fmt.Errorf("failed to get keep-weekly flag: %w", err)
+	}
+	if policy.OlderThan, err = cmd.Flags().GetString("older-than"); err != nil {
+		return nil, fmt.Errorf("failed to get older-than flag: %w", err)
+	}
+
+	if validateErr := retention.Validate(policy); validateErr != nil {
+		return nil, newUsageError("%v", validateErr)
+	}
+	if retention.IsEmpty(policy) {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+// archiveSeries is a group of archives pruned together under one policy
+type archiveSeries struct {
+	Name     string
+	Profile  string
+	Policy   *types.Retention
+	Archives []string
+}
+
+// pruneSeries groups the archives to prune by series and resolves each
+// group's policy: command line rules, then the profile's, then the default.
+// Groups without rules are left alone; it is an error if no group has any.
+func pruneSeries(app *types.App, profile string, flagPolicy *types.Retention) ([]archiveSeries, error) {
+	archives, err := app.Archiver.GetAvailableArchives("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find archives: %w", err)
+	}
+
+	groups := make(map[string]*archiveSeries)
+	var names []string
+	for _, archivePath := range archives {
+		archiveProfile := config.ProfileForArchive(app.Config, archivePath)
+		if profile != "" && archiveProfile != profile {
+			continue
+		}
+		name := config.ArchiveSeries(archivePath)
+		if groups[name] == nil {
+			groups[name] = &archiveSeries{Name: name, Profile: archiveProfile}
+			names = append(names, name)
+		}
+		groups[name].Archives = append(groups[name].Archives, archivePath)
+	}
+	sort.Strings(names)
+
+	var series []archiveSeries
+	for _, name := range names {
+		s := groups[name]
+		s.Policy = flagPolicy
+		if retention.IsEmpty(s.Policy) && s.Profile != "" {
+			s.Policy = app.Config.Profiles[s.Profile].Retention
+		}
+		if retention.IsEmpty(s.Policy) {
+			s.Policy = app.Config.Retention
+		}
+		if !retention.IsEmpty(s.Policy) {
+			series = append(series, *s)
+		}
+	}
+
+	if len(series) == 0 && len(groups) > 0 {
+		return nil, newUsageError("no retention rules: pass --keep-last, --keep-daily, --keep-weekly or --older-than, or set \"retention\" in config")
+	}
+	return series, nil
+}
+
+// decidePrune reads each archive's creation time and applies the series
+// policy, printing the outcome. Unreadable archives are always kept.
+func decidePrune(out *Output, app *types.App, s archiveSeries, key string) []prunedResult {
+	label := s.Name
+	if s.Profile != "" {
+		label = fmt.Sprintf("%s (profile %s)", s.Name, s.Profile)
+	}
+	out.Section(label)
+
+	now := time.Now()
+	var items []retention.Item
+	var results []prunedResult
+	validity := make(map[string]string)
+	for _, archivePath := range s.Archives {
+		archive, err := app.Archiver.List(archivePath, key)
+		if err != nil {
+			out.Indent(fmt.Sprintf("keep    %s  (cannot read; wrong password or corrupted)", filepath.Base(archivePath)))
+			results = append(results, prunedResult{Path: archivePath, Series: s.Name, Profile: s.Profile, Keep: true, Unreadable: true})
+			continue
+		}
+		items = append(items, retention.Item{Path: archivePath, CreatedAt: archive.CreatedAt})
+		validity[archivePath] = archive.Validity(now)
+	}
+
+	decisions, _ := retention.Apply(items, s.Policy, now) //nolint:errcheck // policies are validated by flags and config
+	for _, d := range decisions {
+		created := d.CreatedAt
+		results = append(results, prunedResult{
+			Path:      d.Path,
+			Series:    s.Name,
+			Profile:   s.Profile,
+			CreatedAt: &created,
+			Keep:      d.Keep,
+			Reasons:   d.Reasons,
+			Validity:  validity[d.Path],
+		})
+		note := ""
+		if validity[d.Path] != "" {
+			note = "  [" + validity[d.Path] + "]"
+		}
+		if d.Keep {
+			out.Indent(fmt.Sprintf("keep    %s  %s  (%s)%s", filepath.Base(d.Path),
+				created.Format(constants.DateTimeFormat), strings.Join(d.Reasons, ", "), note))
+		} else {
+			out.Indent(fmt.Sprintf("remove  %s  %s%s", filepath.Base(d.Path), created.Format(constants.DateTimeFormat), note))
+		}
+	}
+	out.Blank()
+	return results
+}
+
+// removePruned deletes the pruned archives and their signatures
+func removePruned(out *Output, app *types.App, remove []string, dryRun bool, res *pruneResult) error {
+	switch {
+	case len(remove) == 0:
+		out.Success("Nothing to prune")
+		return nil
+	case dryRun:
+		out.Success(fmt.Sprintf("Dry run: would remove %d archives", len(remove)))
+		return nil
+	case !confirm(fmt.Sprintf("Remove %d archives?", len(remove))):
+		out.Skipped("Operation cancelled")
+		return nil
+	}
+
+	for _, archivePath := range remove {
+		// Recorded first, while the archive can still be hashed
+		auditArchive(out, app, audit.OpPrune, archivePath, nil)
+		if err := os.Remove(archivePath); err != nil {
+			out.Error(fmt.Sprintf("Failed to remove %s: %v", archivePath, err))
+			return fmt.Errorf("failed to remove %s: %w", archivePath, err)
+		}
+		if err := os.Remove(signing.SignaturePath(archivePath)); err != nil && !os.IsNotExist(err) {
+			out.Warning(fmt.Sprintf("Failed to remove the signature of %s: %v", archivePath, err))
+		}
+		res.Removed++
+	}
+	forgetArchives(out, remove)
+
+	out.Success(fmt.Sprintf("Removed %d archives", res.Removed))
+	return nil
+}
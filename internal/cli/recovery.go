@@ -0,0 +1,461 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+
+	"goingenv/internal/agent"
+	"goingenv/internal/audit"
+	"goingenv/internal/crypto"
+	"goingenv/pkg/password"
+	"goingenv/pkg/types"
+)
+
+// Default split-key parameters
+const (
+	defaultShares    = 5
+	defaultThreshold = 3
+)
+
+// splitKeyResult is the JSON result of the split-key command
+type splitKeyResult struct {
+	SetID     string   `json:"set_id"`
+	Threshold int      `json:"threshold"`
+	Shares    []string `json:"shares"`
+	Files     []string `json:"files,omitempty"`
+}
+
+// recoverResult is the JSON result of the recover command
+type recoverResult struct {
+	SetID    string   `json:"set_id"`
+	Shares   int      `json:"shares_used"`
+	Unlocked bool     `json:"unlocked,omitempty"`
+	Rekeyed  []string `json:"rekeyed,omitempty"`
+	Skipped  []string `json:"skipped,omitempty"`
+	Password string   `json:"password,omitempty"`
+}
+
+// recoverOpts holds parsed recover command flags
+type recoverOpts struct {
+	password password.Options
+	rekey    bool
+	unlock   bool
+	printKey bool
+	archive  string
+	ttl      time.Duration
+}
+
+// newSplitKeyCommand creates the split-key command
+func newSplitKeyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "split-key",
+		Short: "Split the archive password into recovery shares",
+		Long: `Split the archive password into printable Shamir shares.
+
+Any --threshold shares recover the password with 'goingenv recover'; fewer
+reveal nothing about it. Give each share to a different person or store
+them in different places, so archives survive the loss of whoever knows
+the password.
+
+The password must open at least one existing archive before it is split.
+
+Examples:
+  goingenv split-key                               # 5 shares, any 3 recover
+  goingenv split-key --shares 3 --threshold 2
+  goingenv split-key --out-dir /media/usb/shares   # One file per share`,
+		Args: cobra.NoArgs,
+		RunE: runSplitKeyCommand,
+	}
+
+	addPasswordFlags(cmd)
+	cmd.Flags().Int("shares", defaultShares, "Number of shares to create")
+	cmd.Flags().Int("threshold", defaultThreshold, "Number of shares needed to recover the password")
+	cmd.Flags().String("out-dir", "", "Write each share to its own file in this directory")
+
+	return cmd
+}
+
+// newRecoverCommand creates the recover command
+func newRecoverCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "recover [share...]",
+		Short: "Recover the archive password from shares",
+		Long: `Combine shares from 'goingenv split-key' to recover the archive password.
+
+Shares are taken from the arguments, or read one per line from standard
+input. Then one of:
+  --rekey    re-encrypt the archives under a new password
+  --unlock   hand the password to the key agent
+  --print    print the password
+
+With --rekey the password flags choose the new password, which must meet
+the strength policy. Old shares only recover the old password, so split
+the new one afterwards.
+
+Examples:
+  goingenv recover --rekey GE1-... GE1-... GE1-...
+  goingenv recover --unlock --ttl 1h < shares.txt
+  goingenv recover --print -f .goingenv/prod.enc`,
+		RunE: runRecoverCommand,
+	}
+
+	addPasswordFlags(cmd)
+	cmd.Flags().Bool("rekey", false, "Re-encrypt archives under a new password")
+	cmd.Flags().Bool("unlock", false, "Add the recovered password to the key agent")
+	cmd.Flags().Bool("print", false, "Print the recovered password")
+	cmd.Flags().StringP("file", "f", "", "Only verify and rekey this archive")
+	cmd.Flags().Duration("ttl", agent.DefaultTTL, "How long --unlock keeps the password unlocked")
+
+	return cmd
+}
+
+// runSplitKeyCommand executes the split-key command
+func runSplitKeyCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+
+	app, err := initApp()
+	if err != nil {
+		out.Header()
+		out.Blank()
+		out.Error(err.Error())
+		return err
+	}
+
+	n, threshold, outDir, err := parseSplitKeyOpts(cmd)
+	if err != nil {
+		return err
+	}
+	passwordOpts, err := parsePasswordOpts(cmd)
+	if err != nil {
+		return err
+	}
+
+	out.Header()
+	out.Blank()
+
+	key, cleanup, err := getPass(app, passwordOpts)
+	if err != nil {
+		out.Error(fmt.Sprintf("Failed to get password: %v", err))
+		return err
+	}
+	defer cleanup()
+
+	if verifyErr := verifyArchivePass(out, app, key); verifyErr != nil {
+		return verifyErr
+	}
+
+	shares, err := crypto.SplitSecret([]byte(key), n, threshold)
+	if err != nil {
+		out.Error(fmt.Sprintf("Failed to split password: %v", err))
+		return err
+	}
+
+	res := &splitKeyResult{SetID: shares[0].SetID, Threshold: threshold}
+	for _, share := range shares {
+		res.Shares = append(res.Shares, share.Encode())
+	}
+	setResultData(res)
+
+	if outDir != "" {
+		if res.Files, err = writeShareFiles(outDir, res); err != nil {
+			out.Error(err.Error())
+			return err
+		}
+	}
+
+	out.Success(fmt.Sprintf("Split into %d shares; any %d recover the password (set %s)", n, threshold, res.SetID))
+	out.Blank()
+	if !jsonOutput() {
+		for i, encoded := range res.Shares {
+			out.Section(fmt.Sprintf("Share %d of %d", i+1, n))
+			out.Indent(encoded)
+		}
+		out.Blank()
+	}
+	for _, path := range res.Files {
+		out.ListItem(path)
+	}
+	out.Hint("Give each share to a different person; recover with 'goingenv recover'")
+
+	return nil
+}
+
+// parseSplitKeyOpts parses and validates the split-key flags
+func parseSplitKeyOpts(cmd *cobra.Command) (n, threshold int, outDir string, err error) {
+	if n, err = cmd.Flags().GetInt("shares"); err != nil {
+		return 0, 0, "", fmt.Errorf("failed to get shares flag: %w", err)
+	}
+	if threshold, err = cmd.Flags().GetInt("threshold"); err != nil {
+		return 0, 0, "", fmt.Errorf("failed to get threshold flag: %w", err)
+	}
+	if outDir, err = cmd.Flags().GetString("out-dir"); err != nil {
+		return 0, 0, "", fmt.Errorf("failed to get out-dir flag: %w", err)
+	}
+
+	switch {
+	case threshold < crypto.MinShareThreshold:
+		return 0, 0, "", newUsageError(fmt.Sprintf("--threshold must be at least %d", crypto.MinShareThreshold))
+	case n < threshold:
+		return 0, 0, "", newUsageError("--shares must be at least --threshold")
+	case n > crypto.MaxShares:
+		return 0, 0, "", newUsageError(fmt.Sprintf("--shares must be at most %d", crypto.MaxShares))
+	}
+	return n, threshold, outDir, nil
+}
+
+// writeShareFiles writes one private file per share
+func writeShareFiles(dir string, res *splitKeyResult) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+
+	var files []string
+	for i, encoded := range res.Shares {
+		path := filepath.Join(dir, fmt.Sprintf("goingenv-share-%s-%d.txt", strings.ToLower(res.SetID), i+1))
+		content := fmt.Sprintf("goingenv recovery share %d of %d (any %d recover the password)\n%s\n",
+			i+1, len(res.Shares), res.Threshold, encoded)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			return files, fmt.Errorf("failed to write share file: %w", err)
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
+
+// runRecoverCommand executes the recover command
+func runRecoverCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+
+	app, err := initApp()
+	if err != nil {
+		out.Header()
+		out.Blank()
+		out.Error(err.Error())
+		return err
+	}
+
+	opts, err := parseRecoverOpts(cmd, args)
+	if err != nil {
+		return err
+	}
+
+	out.Header()
+	out.Blank()
+
+	shares, err := collectShares(args, os.Stdin)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+
+	secret, err := crypto.CombineShares(shares)
+	if err != nil {
+		out.Error(fmt.Sprintf("Failed to combine shares: %v", err))
+		return &types.ValidationError{Field: "shares", Message: err.Error()}
+	}
+	key := string(secret)
+	clear(secret)
+	defer password.ClearPassword(&key)
+
+	if opts.archive != "" {
+		if _, listErr := app.Archiver.List(opts.archive, key); listErr != nil {
+			out.Error(fmt.Sprintf("Recovered password does not open %s", filepath.Base(opts.archive)))
+			return fmt.Errorf("failed to verify password: %w", listErr)
+		}
+	} else if verifyErr := verifyArchivePass(out, app, key); verifyErr != nil {
+		return verifyErr
+	}
+
+	res := &recoverResult{SetID: shares[0].SetID, Shares: len(shares)}
+	setResultData(res)
+	out.Success(fmt.Sprintf("Recovered the password from %d shares (set %s)", len(shares), res.SetID))
+
+	switch {
+	case opts.rekey:
+		return rekeyArchives(out, app, opts, key, res)
+	case opts.unlock:
+		return unlockRecovered(out, opts, key, res)
+	default:
+		res.Password = key
+		if !jsonOutput() {
+			out.Blank()
+			out.Indent(key)
+		}
+		return nil
+	}
+}
+
+// parseRecoverOpts parses and validates the recover flags
+func parseRecoverOpts(cmd *cobra.Command, args []string) (*recoverOpts, error) {
+	o := &recoverOpts{}
+	var err error
+
+	if o.password, err = parsePasswordOpts(cmd); err != nil {
+		return nil, err
+	}
+	if o.rekey, err = cmd.Flags().GetBool("rekey"); err != nil {
+		return nil, fmt.Errorf("failed to get rekey flag: %w", err)
+	}
+	if o.unlock, err = cmd.Flags().GetBool("unlock"); err != nil {
+		return nil, fmt.Errorf("failed to get unlock flag: %w", err)
+	}
+	if o.printKey, err = cmd.Flags().GetBool("print"); err != nil {
+		return nil, fmt.Errorf("failed to get print flag: %w", err)
+	}
+	if o.archive, err = cmd.Flags().GetString("file"); err != nil {
+		return nil, fmt.Errorf("failed to get file flag: %w", err)
+	}
+	if o.ttl, err = cmd.Flags().GetDuration("ttl"); err != nil {
+		return nil, fmt.Errorf("failed to get ttl flag: %w", err)
+	}
+
+	actions := 0
+	for _, set := range []bool{o.rekey, o.unlock, o.printKey} {
+		if set {
+			actions++
+		}
+	}
+	switch {
+	case actions != 1:
+		return nil, newUsageError("choose exactly one of --rekey, --unlock or --print")
+	case !o.rekey && o.password.NonInteractive():
+		return nil, newUsageError("password flags only apply to --rekey")
+	case len(args) == 0 && o.password.PasswordStdin:
+		return nil, newUsageError("--password-stdin needs the shares as arguments")
+	}
+	return o, nil
+}
+
+// collectShares parses shares from args, or reads them one per line from r
+// until enough shares for the threshold have been entered
+func collectShares(args []string, r io.Reader) ([]crypto.Share, error) {
+	if len(args) > 0 {
+		shares := make([]crypto.Share, 0, len(args))
+		for i, arg := range args {
+			share, err := crypto.ParseShare(arg)
+			if err != nil {
+				return nil, fmt.Errorf("share %d: %w", i+1, err)
+			}
+			shares = append(shares, share)
+		}
+		return shares, nil
+	}
+
+	interactive := false
+	if f, ok := r.(*os.File); ok {
+		interactive = term.IsTerminal(int(f.Fd()))
+	}
+
+	var shares []crypto.Share
+	scanner := bufio.NewScanner(r)
+	for {
+		if interactive {
+			fmt.Fprintf(os.Stderr, "Share %d: ", len(shares)+1)
+		}
+		if !scanner.Scan() {
+			break
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || !strings.HasPrefix(strings.ToUpper(line), "GE1-") {
+			continue // blank lines and share file headers
+		}
+		share, err := crypto.ParseShare(line)
+		if err != nil {
+			if interactive {
+				fmt.Fprintf(os.Stderr, "  %v, try again\n", err)
+				continue
+			}
+			return nil, fmt.Errorf("share %d: %w", len(shares)+1, err)
+		}
+		shares = append(shares, share)
+		if len(shares) >= share.Threshold {
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read shares: %w", err)
+	}
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+	return shares, nil
+}
+
+// rekeyArchives re-encrypts every archive the recovered password opens
+// (or just --file) under a new password that meets the strength policy
+func rekeyArchives(out *Output, app *types.App, opts *recoverOpts, oldKey string, res *recoverResult) error {
+	archives := []string{opts.archive}
+	if opts.archive == "" {
+		var err error
+		if archives, err = app.Archiver.GetAvailableArchives(""); err != nil {
+			out.Error(fmt.Sprintf("Failed to find archives: %v", err))
+			return err
+		}
+	}
+	if len(archives) == 0 {
+		out.Warning("No archives to rekey")
+		return nil
+	}
+
+	out.Blank()
+	newKey, cleanup, err := getNewPass(opts.password, app.Config)
+	if err != nil {
+		out.Error(fmt.Sprintf("Failed to get new password: %v", err))
+		return err
+	}
+	defer cleanup()
+
+	for _, archivePath := range archives {
+		rekeyErr := app.Archiver.Rekey(archivePath, oldKey, newKey)
+		switch {
+		case rekeyErr == nil:
+			res.Rekeyed = append(res.Rekeyed, archivePath)
+			auditArchive(out, app, audit.OpRekey, archivePath, nil)
+			out.ListItem(fmt.Sprintf("Rekeyed %s", filepath.Base(archivePath)))
+			removeStaleSignature(out, archivePath)
+		case errors.Is(rekeyErr, types.ErrWrongPassword):
+			res.Skipped = append(res.Skipped, archivePath)
+			out.Skipped(fmt.Sprintf("%s uses a different password", filepath.Base(archivePath)))
+		default:
+			out.Error(fmt.Sprintf("Failed to rekey %s: %v", filepath.Base(archivePath), rekeyErr))
+			return rekeyErr
+		}
+	}
+
+	// A cached copy of the old password no longer opens the archives
+	if scope, scopeErr := agentScope(); scopeErr == nil {
+		_ = agent.NewClient(agent.SocketPath()).Lock(scope) //nolint:errcheck // agent may not be running
+	}
+
+	out.Blank()
+	out.Success(fmt.Sprintf("Rekeyed %d archives", len(res.Rekeyed)))
+	out.Hint("The old shares only recover the old password; run 'goingenv split-key' again")
+	return nil
+}
+
+// unlockRecovered hands the recovered password to the key agent
+func unlockRecovered(out *Output, opts *recoverOpts, key string, res *recoverResult) error {
+	scope, err := agentScope()
+	if err != nil {
+		return err
+	}
+	if addErr := agent.NewClient(agent.SocketPath()).Add(scope, key, opts.ttl); addErr != nil {
+		out.Error(fmt.Sprintf("Failed to unlock: %v", addErr))
+		if errors.Is(addErr, agent.ErrNotRunning) {
+			out.Hint("Start it with 'goingenv agent start'")
+		}
+		return addErr
+	}
+	res.Unlocked = true
+	out.Success(fmt.Sprintf("Unlocked %s for %s", scope, opts.ttl))
+	return nil
+}
@@ -0,0 +1,223 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"goingenv/pkg/types"
+)
+
+// Output formats selected with the global --output flag
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// outputFormatEnv selects the output format when no flag is given
+const outputFormatEnv = "GOINGENV_OUTPUT"
+
+// outputFormat is the format of the running command, resolved before it runs
+var outputFormat = FormatText
+
+// result collects the structured document for the running command
+var result = &Result{}
+
+// Result is the single document a command writes to stdout in JSON mode
+type Result struct {
+	Command    string       `json:"command"`
+	OK         bool         `json:"ok"`
+	Data       interface{}  `json:"data,omitempty"`
+	Warnings   []string     `json:"warnings,omitempty"`
+	Error      *ResultError `json:"error,omitempty"`
+	DurationMS int64        `json:"duration_ms"`
+}
+
+// ResultError describes why a command failed
+type ResultError struct {
+	Code    string   `json:"code"`
+	Message string   `json:"message"`
+	Details []string `json:"details,omitempty"`
+}
+
+// jsonOutput reports whether the running command emits a JSON document
+func jsonOutput() bool {
+	return outputFormat == FormatJSON
+}
+
+// setResultData records the command-specific payload of the result document
+func setResultData(data interface{}) {
+	result.Data = data
+}
+
+// resolveOutputFormat reads --output/--json from the root command, falling
+// back to GOINGENV_OUTPUT. Commands whose own --output names a file (pack,
+// keygen) shadow the global flag; given "text" or "json" there, it selects
+// the format instead of naming a file, so --output json works everywhere.
+func resolveOutputFormat(cmd *cobra.Command) error {
+	flags := cmd.Root().PersistentFlags()
+
+	format := os.Getenv(outputFormatEnv)
+	if flags.Changed("output") {
+		value, err := flags.GetString("output")
+		if err != nil {
+			return fmt.Errorf("failed to get output flag: %w", err)
+		}
+		format = value
+	}
+	if local := cmd.LocalNonPersistentFlags().Lookup("output"); local != nil && local.Changed {
+		if value := local.Value.String(); value == FormatText || value == FormatJSON {
+			format = value
+			if err := local.Value.Set(local.DefValue); err != nil {
+				return fmt.Errorf("failed to reset output flag: %w", err)
+			}
+			local.Changed = false
+		}
+	}
+	if asJSON, err := flags.GetBool("json"); err == nil && asJSON {
+		format = FormatJSON
+	}
+
+	switch format {
+	case "", FormatText:
+		outputFormat = FormatText
+	case FormatJSON:
+		outputFormat = FormatJSON
+	default:
+		return newUsageError("unknown output format %q: must be %s or %s", format, FormatText, FormatJSON)
+	}
+	return nil
+}
+
+// Execute runs the CLI and returns the process exit code (see exitClasses).
+// In JSON mode the result document is written to stdout; otherwise errors are
+// printed as text.
+func Execute(version string) int {
+	rootCmd := NewRootCommand(version)
+	if os.Getenv(outputFormatEnv) == FormatJSON {
+		outputFormat = FormatJSON
+	}
+
+	start := time.Now()
+	cmd, err := rootCmd.ExecuteC()
+
+	// Flag errors stop cobra before resolveOutputFormat runs
+	if err != nil && !jsonOutput() && argsRequestJSON(os.Args[1:]) {
+		outputFormat = FormatJSON
+	}
+
+	if !jsonOutput() {
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+		}
+		return exitCode(err)
+	}
+
+	writeResult(os.Stdout, cmd, err, time.Since(start))
+	return exitCode(err)
+}
+
+// argsRequestJSON reports whether raw arguments ask for JSON output
+func argsRequestJSON(args []string) bool {
+	for i, arg := range args {
+		if arg == "--" {
+			return false
+		}
+		if arg == "--json" || arg == "--json=true" || arg == "--output="+FormatJSON {
+			return true
+		}
+		if arg == "--output" && i+1 < len(args) && args[i+1] == FormatJSON {
+			return true
+		}
+	}
+	return false
+}
+
+// writeResult completes and writes the result document
+func writeResult(w io.Writer, cmd *cobra.Command, err error, duration time.Duration) {
+	if cmd != nil {
+		result.Command = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	}
+	result.OK = err == nil
+	result.DurationMS = duration.Milliseconds()
+	if err != nil {
+		if result.Error == nil {
+			result.Error = &ResultError{}
+		}
+		result.Error.Code = errorCode(err)
+		result.Error.Message = err.Error()
+	}
+
+	data, marshalErr := json.MarshalIndent(result, "", "  ")
+	if marshalErr != nil {
+		fmt.Fprintf(os.Stderr, "failed to encode result: %v\n", marshalErr)
+		return
+	}
+	fmt.Fprintln(w, string(data))
+}
+
+// recordWarning adds a warning to the result document
+func recordWarning(msg string) {
+	result.Warnings = append(result.Warnings, msg)
+}
+
+// recordErrorDetail adds a human-readable detail to the result error
+func recordErrorDetail(msg string) {
+	if result.Error == nil {
+		result.Error = &ResultError{}
+	}
+	result.Error.Details = append(result.Error.Details, msg)
+}
+
+// fileResult describes one environment file in a result document
+type fileResult struct {
+	Path       string    `json:"path"`
+	Size       int64     `json:"size"`
+	Modified   time.Time `json:"modified"`
+	Checksum   string    `json:"checksum,omitempty"`
+	LinkTarget string    `json:"link_target,omitempty"`
+}
+
+// archiveResult describes one archive file in a result document
+type archiveResult struct {
+	Path      string     `json:"path"`
+	Size      int64      `json:"size"`
+	Modified  time.Time  `json:"modified"`
+	Profile   string     `json:"profile,omitempty"`
+	SHA256    string     `json:"sha256,omitempty"`
+	Name      string     `json:"name,omitempty"`
+	Tags      []string   `json:"tags,omitempty"`
+	Note      string     `json:"note,omitempty"`
+	NotBefore *time.Time `json:"not_before,omitempty"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	Validity  string     `json:"validity,omitempty"` // see types.Archive.Validity
+}
+
+// fileResults converts scanned or archived files for a result document
+func fileResults(files []types.EnvFile) []fileResult {
+	results := make([]fileResult, 0, len(files))
+	for _, f := range files {
+		results = append(results, fileResult{
+			Path:       f.RelativePath,
+			Size:       f.Size,
+			Modified:   f.ModTime,
+			Checksum:   f.Checksum,
+			LinkTarget: f.LinkTarget,
+		})
+	}
+	return results
+}
+
+// sumFileSizes sums the sizes of files
+func sumFileSizes(files []types.EnvFile) int64 {
+	var total int64
+	for _, f := range files {
+		total += f.Size
+	}
+	return total
+}
@@ -0,0 +1,203 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"goingenv/pkg/types"
+)
+
+func TestWriteResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		data     interface{}
+		warning  string
+		err      error
+		wantOK   bool
+		wantCode string
+	}{
+		{
+			name:   "success with data",
+			args:   []string{"status"},
+			data:   map[string]int{"count": 2},
+			wantOK: true,
+		},
+		{
+			name:    "success with warning",
+			args:    []string{"pack"},
+			warning: "No environment files found",
+			wantOK:  true,
+		},
+		{
+			name:     "validation failure",
+			args:     []string{"config", "validate"},
+			err:      &types.ValidationError{Field: "DefaultDepth", Message: "too deep"},
+			wantCode: "validation_error",
+		},
+		{
+			name:     "wrapped crypto failure",
+			args:     []string{"unpack"},
+			err:      fmt.Errorf("unpack: %w", &types.CryptoError{Operation: "decrypt", Err: fmt.Errorf("bad key")}),
+			wantCode: "crypto_error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result = &Result{}
+			defer func() { result = &Result{} }()
+
+			root := NewRootCommand("1.0.0")
+			cmd, _, findErr := root.Find(tt.args)
+			if findErr != nil {
+				t.Fatalf("Find(%v) error = %v", tt.args, findErr)
+			}
+
+			setResultData(tt.data)
+			if tt.warning != "" {
+				recordWarning(tt.warning)
+			}
+
+			var buf bytes.Buffer
+			writeResult(&buf, cmd, tt.err, 1500*time.Millisecond)
+
+			var got struct {
+				Command    string          `json:"command"`
+				OK         bool            `json:"ok"`
+				Data       json.RawMessage `json:"data"`
+				Warnings   []string        `json:"warnings"`
+				Error      *ResultError    `json:"error"`
+				DurationMS int64           `json:"duration_ms"`
+			}
+			if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+				t.Fatalf("writeResult() wrote invalid JSON: %v\n%s", err, buf.String())
+			}
+
+			wantCommand := tt.args[0]
+			if len(tt.args) > 1 {
+				wantCommand += " " + tt.args[1]
+			}
+			if got.Command != wantCommand {
+				t.Errorf("command = %q, want %q", got.Command, wantCommand)
+			}
+			if got.OK != tt.wantOK {
+				t.Errorf("ok = %v, want %v", got.OK, tt.wantOK)
+			}
+			if got.DurationMS != 1500 {
+				t.Errorf("duration_ms = %d, want 1500", got.DurationMS)
+			}
+			if tt.data != nil && len(got.Data) == 0 {
+				t.Error("data missing from result")
+			}
+			if tt.warning != "" && (len(got.Warnings) != 1 || got.Warnings[0] != tt.warning) {
+				t.Errorf("warnings = %v, want [%q]", got.Warnings, tt.warning)
+			}
+			if tt.err == nil {
+				if got.Error != nil {
+					t.Errorf("error = %+v, want none", got.Error)
+				}
+				return
+			}
+			if got.Error == nil {
+				t.Fatal("error missing from result")
+			}
+			if got.Error.Code != tt.wantCode {
+				t.Errorf("error.code = %q, want %q", got.Error.Code, tt.wantCode)
+			}
+			if got.Error.Message != tt.err.Error() {
+				t.Errorf("error.message = %q, want %q", got.Error.Message, tt.err.Error())
+			}
+		})
+	}
+}
+
+func TestResolveOutputFormat(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		env     string
+		want    string
+		wantErr bool
+	}{
+		{name: "default", args: []string{"status"}, want: FormatText},
+		{name: "json flag", args: []string{"--json", "status"}, want: FormatJSON},
+		{name: "output flag", args: []string{"--output", "json", "status"}, want: FormatJSON},
+		{name: "environment", args: []string{"status"}, env: "json", want: FormatJSON},
+		{name: "flag overrides environment", args: []string{"--output", "text", "status"}, env: "json", want: FormatText},
+		{name: "json flag on pack", args: []string{"pack", "--json", "--output", "backup.enc"}, want: FormatJSON},
+		{name: "output flag on pack", args: []string{"pack", "--output", "json"}, want: FormatJSON},
+		{name: "output flag on keygen", args: []string{"keygen", "--output", "json"}, want: FormatJSON},
+		{name: "pack archive name", args: []string{"pack", "--output", "backup.enc"}, want: FormatText},
+		{name: "unknown format", args: []string{"--output", "yaml", "status"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv(outputFormatEnv, tt.env)
+			outputFormat = FormatText
+			defer func() { outputFormat = FormatText }()
+
+			root := NewRootCommand("1.0.0")
+			cmd, _, err := root.Find(tt.args)
+			if err != nil {
+				t.Fatalf("Find(%v) error = %v", tt.args, err)
+			}
+			if err := cmd.ParseFlags(tt.args); err != nil {
+				t.Fatalf("ParseFlags(%v) error = %v", tt.args, err)
+			}
+
+			err = resolveOutputFormat(cmd)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("resolveOutputFormat() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && outputFormat != tt.want {
+				t.Errorf("outputFormat = %q, want %q", outputFormat, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveOutputFormat_PackOutput(t *testing.T) {
+	defer func() { outputFormat = FormatText }()
+
+	// pack --output json selects the format and leaves the archive unnamed
+	root := NewRootCommand("1.0.0")
+	args := []string{"pack", "--output", "json"}
+	cmd, _, err := root.Find(args)
+	if err != nil {
+		t.Fatalf("Find(%v) error = %v", args, err)
+	}
+	if err := cmd.ParseFlags(args); err != nil {
+		t.Fatalf("ParseFlags(%v) error = %v", args, err)
+	}
+	if err := resolveOutputFormat(cmd); err != nil {
+		t.Fatalf("resolveOutputFormat() error = %v", err)
+	}
+	if name, _ := cmd.Flags().GetString("output"); name != "" || cmd.Flags().Changed("output") {
+		t.Errorf("pack --output = %q (changed %v), want the default archive name", name, cmd.Flags().Changed("output"))
+	}
+}
+
+func TestArgsRequestJSON(t *testing.T) {
+	tests := []struct {
+		args []string
+		want bool
+	}{
+		{[]string{"status"}, false},
+		{[]string{"--json", "status", "--bogus"}, true},
+		{[]string{"status", "--output=json"}, true},
+		{[]string{"pack", "--output", "json"}, true},
+		{[]string{"pack", "--output", "backup.enc"}, false},
+		{[]string{"unpack", "--", "--json"}, false},
+	}
+
+	for _, tt := range tests {
+		if got := argsRequestJSON(tt.args); got != tt.want {
+			t.Errorf("argsRequestJSON(%v) = %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
@@ -54,7 +54,13 @@ func NewRootCommand(version string) *cobra.Command {
 It can scan, encrypt, and archive your .env files securely, making it easy to
 backup, transfer, and restore your environment configurations.`,
 		Version: version,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return resolveOutputFormat(cmd)
+		},
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if jsonOutput() {
+				return newUsageError("interactive mode is not available with JSON output; run a subcommand")
+			}
 			verbose, _ := cmd.Flags().GetBool("verbose") //nolint:errcheck // flag always exists
 			return runInteractiveMode(verbose, version)
 		},
@@ -66,12 +72,39 @@ backup, transfer, and restore your environment configurations.`,
 	// Add global verbose flag
 	rootCmd.PersistentFlags().BoolP("verbose", "v", false, "Enable verbose debug logging for TUI mode")
 
+	// Add global output format flags. pack and keygen name a file with their
+	// own --output, which shadows this one; there "text" and "json" still
+	// select the format (see resolveOutputFormat).
+	rootCmd.PersistentFlags().String("output", FormatText, "Output format: text, json")
+	rootCmd.PersistentFlags().Bool("json", false, "Shorthand for --output json")
+
 	// Add subcommands
 	rootCmd.AddCommand(newInitCommand())
 	rootCmd.AddCommand(newPackCommand())
 	rootCmd.AddCommand(newUnpackCommand())
 	rootCmd.AddCommand(newListCommand())
+	rootCmd.AddCommand(newAddCommand())
+	rootCmd.AddCommand(newRmCommand())
+	rootCmd.AddCommand(newUpdateCommand())
 	rootCmd.AddCommand(newStatusCommand())
+	rootCmd.AddCommand(newConfigCommand())
+	rootCmd.AddCommand(newPassgenCommand())
+	rootCmd.AddCommand(newAgentCommand())
+	rootCmd.AddCommand(newSplitKeyCommand())
+	rootCmd.AddCommand(newRecoverCommand())
+	rootCmd.AddCommand(newKeygenCommand())
+	rootCmd.AddCommand(newSignersCommand())
+	rootCmd.AddCommand(newSignCommand())
+	rootCmd.AddCommand(newVerifyCommand())
+	rootCmd.AddCommand(newPruneCommand())
+	rootCmd.AddCommand(newGitCommand())
+	rootCmd.AddCommand(newGitTextconvCommand())
+	rootCmd.AddCommand(newGitMergeCommand())
+	rootCmd.AddCommand(newResolveCommand())
+	rootCmd.AddCommand(newGuardCommand())
+	rootCmd.AddCommand(newAuditCommand())
+
+	markUsageErrors(rootCmd)
 
 	return rootCmd
 }
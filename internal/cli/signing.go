@@ -0,0 +1,415 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"goingenv/internal/config"
+	"goingenv/internal/signing"
+	"goingenv/pkg/types"
+	"goingenv/pkg/utils"
+)
+
+// keygenResult is the JSON result of the keygen command
+type keygenResult struct {
+	Name      string `json:"name"`
+	KeyFile   string `json:"key_file"`
+	PublicKey string `json:"public_key"`
+}
+
+// signerResult is one trusted signer in JSON output
+type signerResult struct {
+	Name      string `json:"name"`
+	PublicKey string `json:"public_key"`
+}
+
+// signersResult is the JSON result of the signers commands
+type signersResult struct {
+	File    string         `json:"file"`
+	Signers []signerResult `json:"signers"`
+}
+
+// signResult is the JSON result of the sign command
+type signResult struct {
+	Archive   string `json:"archive"`
+	Signature string `json:"signature"`
+	Signer    string `json:"signer"`
+}
+
+// newKeygenCommand creates the keygen command
+func newKeygenCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Create an Ed25519 key for signing archives",
+		Long: `Create a personal Ed25519 signing key and print its public key.
+
+The private key is written readable only by you, by default to
+<user config dir>/goingenv/signing/<name>.key. Share the printed public key
+line so teammates can trust it with 'goingenv signers add'.
+
+Examples:
+  goingenv keygen                       # Key named after your user name
+  goingenv keygen --name alice@laptop   # Pick the signer name
+  goingenv keygen -o ~/keys/ci.key --name ci`,
+		Args: cobra.NoArgs,
+		RunE: runKeygenCommand,
+	}
+	cmd.Flags().String("name", "", "Signer name (default: your user name)")
+	cmd.Flags().StringP("output", "o", "", "Key file to create")
+	return cmd
+}
+
+// newSignersCommand creates the signers command
+func newSignersCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "signers",
+		Short: "Manage the public keys trusted to sign archives",
+		Long: `Manage .goingenv/signers, the project's list of trusted signing keys.
+
+Commit this file. unpack and list check each archive's signature against it;
+the signature_policy setting decides what happens to unsigned archives and
+unknown signers (off, warn or require).
+
+Examples:
+  goingenv signers list
+  goingenv signers add alice ed25519:Xk3...      # Trust a teammate's key
+  goingenv signers add --key alice               # Trust your own key
+  goingenv signers remove alice`,
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "list",
+		Short: "List trusted signers",
+		Args:  cobra.NoArgs,
+		RunE:  runSignersListCommand,
+	})
+
+	add := &cobra.Command{
+		Use:   "add [name public-key]",
+		Short: "Trust a signing key",
+		Args:  cobra.RangeArgs(0, 2),
+		RunE:  runSignersAddCommand,
+	}
+	add.Flags().String("key", "", "Trust the public half of this key file or key name")
+	cmd.AddCommand(add)
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "remove <name>",
+		Short: "Stop trusting a signer",
+		Args:  cobra.ExactArgs(1),
+		RunE:  runSignersRemoveCommand,
+	})
+
+	return cmd
+}
+
+// newSignCommand creates the sign command
+func newSignCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "sign",
+		Short: "Sign an existing archive",
+		Long: `Write a detached signature (<archive>.sig) for an archive.
+
+The signature covers the whole encrypted archive, so any change to it,
+including re-encrypting under a new password, needs a new signature.
+Only sign archives you created or have checked.
+
+Examples:
+  goingenv sign --key alice                   # Sign the most recent archive
+  goingenv sign -f .goingenv/prod.enc --key ~/keys/ci.key`,
+		Args: cobra.NoArgs,
+		RunE: runSignCommand,
+	}
+	cmd.Flags().StringP("file", "f", "", "Archive file or reference to sign (default: latest)")
+	cmd.Flags().String("key", "", "Signing key file or key name (required)")
+	return cmd
+}
+
+// runKeygenCommand creates a signing key
+func runKeygenCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+	out.Header()
+	out.Blank()
+
+	name, err := cmd.Flags().GetString("name")
+	if err != nil {
+		return fmt.Errorf("failed to get name flag: %w", err)
+	}
+	path, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return fmt.Errorf("failed to get output flag: %w", err)
+	}
+
+	if name == "" {
+		name = utils.CurrentUserName()
+	}
+	if nameErr := signing.ValidateName(name); nameErr != nil {
+		out.Error(nameErr.Error())
+		return newUsageError("%v; choose one with --name", nameErr)
+	}
+	if path == "" {
+		if path, err = signing.DefaultKeyPath(name); err != nil {
+			out.Error(err.Error())
+			return err
+		}
+	}
+
+	key, err := signing.GenerateKey(name)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	if saveErr := signing.SaveKey(path, key); saveErr != nil {
+		out.Error(saveErr.Error())
+		return saveErr
+	}
+
+	signer := key.Signer()
+	setResultData(keygenResult{Name: name, KeyFile: path, PublicKey: signing.FormatPublicKey(signer.PublicKey)})
+
+	out.Success(fmt.Sprintf("Created signing key %s", path))
+	out.Blank()
+	out.Section("Public key")
+	out.Indent(signer.String())
+	out.Blank()
+	out.Hint(fmt.Sprintf("Trust it in a project with 'goingenv signers add %s'", signer.String()))
+	out.Hint(fmt.Sprintf("Sign archives with 'goingenv pack --sign %s'", name))
+	return nil
+}
+
+// runSignersListCommand lists the trusted signers
+func runSignersListCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+	out.Header()
+	out.Blank()
+
+	if !config.IsInitialized() {
+		err := fmt.Errorf("%w. Run 'goingenv init' first", types.ErrNotInitialized)
+		out.Error(err.Error())
+		return err
+	}
+
+	path := signersPath()
+	signers, err := signing.LoadSigners(path)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	setResultData(newSignersResult(path, signers))
+
+	if len(signers) == 0 {
+		out.MutedPrint("  No trusted signers")
+		out.Hint("Create a key with 'goingenv keygen', then trust it with 'goingenv signers add --key <name>'")
+		return nil
+	}
+
+	out.Section(fmt.Sprintf("Trusted signers (%d)", len(signers)))
+	for _, s := range signers {
+		out.ListItem(s.String())
+	}
+	return nil
+}
+
+// runSignersAddCommand trusts a public key given as arguments or --key
+func runSignersAddCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+	out.Header()
+	out.Blank()
+
+	keyArg, err := cmd.Flags().GetString("key")
+	if err != nil {
+		return fmt.Errorf("failed to get key flag: %w", err)
+	}
+
+	var signer signing.Signer
+	switch {
+	case keyArg != "" && len(args) > 0:
+		return newUsageError("give either <name> <public-key> or --key, not both")
+	case keyArg != "":
+		key, loadErr := loadSigningKey(keyArg)
+		if loadErr != nil {
+			out.Error(loadErr.Error())
+			return loadErr
+		}
+		signer = key.Signer()
+	case len(args) == 2:
+		if nameErr := signing.ValidateName(args[0]); nameErr != nil {
+			return newUsageError("%v", nameErr)
+		}
+		pub, parseErr := signing.ParsePublicKey(args[1])
+		if parseErr != nil {
+			return newUsageError("%v", parseErr)
+		}
+		signer = signing.Signer{Name: args[0], PublicKey: pub}
+	default:
+		return newUsageError("signers add needs <name> <public-key>, or --key")
+	}
+
+	return updateSigners(out, fmt.Sprintf("Trusted %s", signer.Name), func(signers []signing.Signer) ([]signing.Signer, error) {
+		return signing.AddSigner(signers, signer)
+	})
+}
+
+// runSignersRemoveCommand stops trusting a signer
+func runSignersRemoveCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+	out.Header()
+	out.Blank()
+
+	return updateSigners(out, fmt.Sprintf("Removed %s", args[0]), func(signers []signing.Signer) ([]signing.Signer, error) {
+		return signing.RemoveSigner(signers, args[0])
+	})
+}
+
+// updateSigners applies change to the project's signers file
+func updateSigners(out *Output, done string, change func([]signing.Signer) ([]signing.Signer, error)) error {
+	if !config.IsInitialized() {
+		err := fmt.Errorf("%w. Run 'goingenv init' first", types.ErrNotInitialized)
+		out.Error(err.Error())
+		return err
+	}
+
+	path := signersPath()
+	signers, err := signing.LoadSigners(path)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	if signers, err = change(signers); err != nil {
+		out.Error(err.Error())
+		return &types.ValidationError{Field: "signers", Message: err.Error()}
+	}
+	if saveErr := signing.SaveSigners(path, signers); saveErr != nil {
+		out.Error(saveErr.Error())
+		return saveErr
+	}
+
+	setResultData(newSignersResult(path, signers))
+	out.Success(done)
+	out.Hint(fmt.Sprintf("Commit %s so teammates trust the same keys", path))
+	return nil
+}
+
+// runSignCommand signs an existing archive
+func runSignCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+
+	app, err := initApp()
+	if err != nil {
+		out.Header()
+		out.Blank()
+		out.Error(err.Error())
+		return err
+	}
+
+	archiveArg, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return fmt.Errorf("failed to get file flag: %w", err)
+	}
+	keyArg, err := cmd.Flags().GetString("key")
+	if err != nil {
+		return fmt.Errorf("failed to get key flag: %w", err)
+	}
+	if keyArg == "" {
+		return newUsageError("--key is required")
+	}
+
+	out.Header()
+	out.Blank()
+
+	archivePath, err := pickArchive(app, archiveArg)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	echoArchive(out, archiveArg, archivePath)
+
+	key, err := loadSigningKey(keyArg)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+
+	if signErr := signArchive(out, key, archivePath); signErr != nil {
+		return signErr
+	}
+	setResultData(signResult{Archive: archivePath, Signature: signing.SignaturePath(archivePath), Signer: key.Name})
+	return nil
+}
+
+// newSignersResult converts signers for JSON output
+func newSignersResult(path string, signers []signing.Signer) signersResult {
+	res := signersResult{File: path, Signers: []signerResult{}}
+	for _, s := range signers {
+		res.Signers = append(res.Signers, signerResult{Name: s.Name, PublicKey: signing.FormatPublicKey(s.PublicKey)})
+	}
+	return res
+}
+
+// signersPath returns the project's trusted signers file
+func signersPath() string {
+	return filepath.Join(config.GetGoingEnvDir(), signing.SignersFileName)
+}
+
+// loadSigningKey loads a signing key from a path or key name
+func loadSigningKey(nameOrPath string) (*signing.Key, error) {
+	path, err := signing.ResolveKeyPath(nameOrPath)
+	if err != nil {
+		return nil, err
+	}
+	return signing.LoadKey(path)
+}
+
+// signArchive writes the archive's detached signature
+func signArchive(out *Output, key *signing.Key, archivePath string) error {
+	if _, err := signing.SignArchive(archivePath, key); err != nil {
+		out.Error(fmt.Sprintf("Failed to sign %s: %v", filepath.Base(archivePath), err))
+		return err
+	}
+	out.Success(fmt.Sprintf("Signed %s as %s", filepath.Base(archivePath), key.Name))
+	return nil
+}
+
+// checkArchiveSignature verifies the archive's signature against the trusted
+// signers under the configured signature policy, printing the outcome. It
+// returns the trusted signer's name, if any.
+func checkArchiveSignature(out *Output, app *types.App, archivePath string) (string, error) {
+	trusted, err := signing.LoadSigners(signersPath())
+	if err != nil {
+		out.Error(err.Error())
+		return "", err
+	}
+
+	v, warning, err := signing.CheckPolicy(archivePath, trusted, app.Config.SignaturePolicy)
+	switch {
+	case errors.Is(err, types.ErrUntrustedSigner):
+		out.Error(err.Error())
+		out.Hint("Trust the signer with 'goingenv signers add', or have a trusted signer run 'goingenv sign'")
+		return "", err
+	case err != nil:
+		out.Error(fmt.Sprintf("Signature check failed: %v", err))
+		if errors.Is(err, types.ErrBadSignature) {
+			out.Hint("Do not use this archive unless you know why it changed")
+		}
+		return "", err
+	case warning != "":
+		out.Warning(warning)
+	case v.Trusted:
+		out.Success(fmt.Sprintf("Signed by %s", v.Signer))
+		return v.Signer, nil
+	}
+	return "", nil
+}
+
+// removeStaleSignature deletes a signature that no longer matches its
+// archive, warning that the archive needs signing again
+func removeStaleSignature(out *Output, archivePath string) {
+	if err := os.Remove(signing.SignaturePath(archivePath)); err == nil {
+		out.Warning(fmt.Sprintf("Removed the signature of %s; sign it again with 'goingenv sign -f %s --key <key>'",
+			filepath.Base(archivePath), archivePath))
+	}
+}
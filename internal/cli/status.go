@@ -4,9 +4,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/spf13/cobra"
 
+	"goingenv/internal/catalog"
 	"goingenv/internal/config"
 	"goingenv/internal/constants"
 	"goingenv/pkg/types"
@@ -63,7 +65,10 @@ func runStatusCommand(cmd *cobra.Command, args []string) error {
 	displayDirectory(out, directory)
 	displayConfig(out, app, verbose)
 	files := displayFiles(out, app, directory, verbose)
-	archives := displayArchives(out, app, verbose)
+	index := loadCatalog(out)
+	archives := displayArchives(out, app, index, verbose)
+
+	setResultData(newStatusResult(app, directory, files, archives, index))
 
 	// Hint for next steps
 	if len(files) > 0 && len(archives) == 0 {
@@ -73,6 +78,49 @@ func runStatusCommand(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+// statusResult is the JSON result of the status command
+type statusResult struct {
+	Directory   string          `json:"directory"`
+	ScanDepth   int             `json:"scan_depth"`
+	MaxFileSize int64           `json:"max_file_size"`
+	Files       []fileResult    `json:"files"`
+	Archives    []archiveResult `json:"archives"`
+}
+
+// newStatusResult describes the scanned files and available archives
+func newStatusResult(app *types.App, directory string, files []types.EnvFile, archives []string, index *catalog.Catalog) statusResult {
+	absDir, _ := filepath.Abs(directory) //nolint:errcheck // best effort
+	res := statusResult{
+		Directory:   absDir,
+		ScanDepth:   app.Config.DefaultDepth,
+		MaxFileSize: app.Config.MaxFileSize,
+		Files:       fileResults(files),
+		Archives:    []archiveResult{},
+	}
+	for _, archivePath := range archives {
+		info, err := os.Stat(archivePath)
+		if err != nil {
+			continue
+		}
+		archive := archiveResult{
+			Path:     archivePath,
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+			Profile:  config.ProfileForArchive(app.Config, archivePath),
+		}
+		if hash, hashErr := catalog.Hash(archivePath); hashErr == nil {
+			archive.SHA256 = hash
+		}
+		if entry, ok := index.Lookup(archivePath); ok {
+			archive.Name, archive.Tags, archive.Note = entry.Name, entry.Tags, entry.Note
+			archive.NotBefore, archive.ExpiresAt = entry.NotBefore, entry.ExpiresAt
+			archive.Validity = entry.Validity(time.Now())
+		}
+		res.Archives = append(res.Archives, archive)
+	}
+	return res
+}
+
 // displayDirectory shows the current directory section
 func displayDirectory(out *Output, directory string) {
 	cwd, _ := os.Getwd() //nolint:errcheck // best effort
@@ -135,9 +183,12 @@ func displayFiles(out *Output, app *types.App, directory string, verbose bool) [
 	return files
 }
 
-// displayArchives shows the archives section and returns found archives
-func displayArchives(out *Output, app *types.App, verbose bool) []string {
+// displayArchives shows the archives section, newest first, and returns
+// found archives. When profiles are configured, archives are grouped by
+// profile.
+func displayArchives(out *Output, app *types.App, index *catalog.Catalog, verbose bool) []string {
 	archives, err := app.Archiver.GetAvailableArchives("")
+	archives = index.Newest(archives)
 	switch {
 	case err != nil:
 		out.Warning(fmt.Sprintf("Could not read archives: %v", err))
@@ -145,25 +196,67 @@ func displayArchives(out *Output, app *types.App, verbose bool) []string {
 		out.Section("Archives (0)")
 		out.MutedPrint("  No archives found")
 		out.Blank()
-	default:
+	case len(app.Config.Profiles) > 0:
 		out.Section(fmt.Sprintf("Archives (%d)", len(archives)))
-		for _, archivePath := range archives {
-			info, statErr := os.Stat(archivePath)
-			if statErr == nil {
-				if verbose {
-					out.Indent(fmt.Sprintf("%-25s %10s   %s",
-						filepath.Base(archivePath),
-						utils.FormatSize(info.Size()),
-						info.ModTime().Format(constants.DateTimeFormat)))
-				} else {
-					out.Indent(fmt.Sprintf("%s    %s    %s",
-						filepath.Base(archivePath),
-						utils.FormatSize(info.Size()),
-						utils.FormatTimeAgo(info.ModTime())))
+		groups := groupArchivesByProfile(app.Config, archives)
+		for _, name := range append(config.ProfileNames(app.Config), "") {
+			label := "Profile " + name
+			if name == "" {
+				label = "No profile"
+			}
+			if len(groups[name]) == 0 {
+				if name != "" {
+					out.MutedPrint(fmt.Sprintf("  %s: no archives", label))
 				}
+				continue
+			}
+			out.Indent(fmt.Sprintf("%s (%d)", label, len(groups[name])))
+			for _, archivePath := range groups[name] {
+				displayArchiveLine(out, archivePath, "  ", index, verbose)
 			}
 		}
 		out.Blank()
+	default:
+		out.Section(fmt.Sprintf("Archives (%d)", len(archives)))
+		for _, archivePath := range archives {
+			displayArchiveLine(out, archivePath, "", index, verbose)
+		}
+		out.Blank()
 	}
 	return archives
 }
+
+// displayArchiveLine prints one archive with its size and age, plus its
+// name, tags and (when verbose) short hash and note from the archive index
+func displayArchiveLine(out *Output, archivePath, indent string, index *catalog.Catalog, verbose bool) {
+	info, err := os.Stat(archivePath)
+	if err != nil {
+		return
+	}
+	entry, indexed := index.Lookup(archivePath)
+	label := ""
+	if indexed {
+		if label = entry.Label(); label != "" {
+			label = "    " + label
+		}
+	}
+	if verbose {
+		hash, hashErr := catalog.Hash(archivePath)
+		if hashErr != nil {
+			hash = "?"
+		}
+		out.Indent(fmt.Sprintf("%s%-25s %10s   %s   %.*s%s", indent,
+			filepath.Base(archivePath),
+			utils.FormatSize(info.Size()),
+			info.ModTime().Format(constants.DateTimeFormat),
+			catalog.ShortHashLen, hash, label))
+		if indexed && entry.Note != "" {
+			out.Indent(fmt.Sprintf("%s  %s", indent, entry.Note))
+		}
+	} else {
+		out.Indent(fmt.Sprintf("%s%s    %s    %s%s", indent,
+			filepath.Base(archivePath),
+			utils.FormatSize(info.Size()),
+			utils.FormatTimeAgo(info.ModTime()), label))
+	}
+}
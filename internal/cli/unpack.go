@@ -8,6 +8,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"goingenv/internal/audit"
 	"goingenv/pkg/types"
 	"goingenv/pkg/utils"
 )
@@ -28,14 +29,37 @@ The unpack command will:
 Examples:
   goingenv unpack                                         # Interactive password prompt
   goingenv unpack --password-env MY_PASSWORD             # Read from environment variable
+  goingenv unpack --password-file /run/secrets/goingenv  # Read from a secret file
+  goingenv unpack --password-cmd "op read op://dev/goingenv/password"  # Ask a password manager
   goingenv unpack -f backup-prod.enc --target /path/to/extract  # Specify archive and target
-  goingenv unpack -f archive.enc --overwrite --backup    # Overwrite with backup`,
+  goingenv unpack -f archive.enc --overwrite --backup    # Overwrite with backup
+  goingenv unpack --workspace api                        # Restore only the api workspace
+  goingenv unpack --profile prod                         # Latest archive of the "prod" profile
+  goingenv unpack --tag release-42                       # Newest archive tagged release-42
+  goingenv unpack -f latest~1                            # The archive before the newest
+  goingenv unpack -f @2026-09-01                         # The newest archive as of that day
+
+-f takes a path or an archive reference: latest, latest~N, an archive file
+name, a tag, @YYYY-MM-DD, or a prefix of the archive's SHA-256 (shown by
+'goingenv status -v'). Archives are ordered by creation time, and the
+resolved archive is always printed.
+
+Password sources are tried in this order: --password-file, --password-fd,
+--password-stdin, --password-cmd, --password-env, then an interactive prompt.
+
+The archive's signature is checked against .goingenv/signers before
+decrypting; see 'goingenv signers --help'. Archives packed with --expires or
+--not-before are refused outside that period unless --ignore-expiry is
+given.`,
 		RunE: runUnpackCommand,
 	}
 
-	cmd.Flags().String("password-env", "", "Read password from environment variable")
-	cmd.Flags().StringP("file", "f", "", "Archive file to unpack (default: most recent)")
+	addPasswordFlags(cmd)
+	cmd.Flags().StringP("file", "f", "", "Archive file or reference to unpack (default: latest)")
+	cmd.Flags().String("tag", "", "Unpack the newest archive with this tag in the archive index")
 	cmd.Flags().StringP("target", "t", "", "Target directory for extraction (default: current directory)")
+	cmd.Flags().String("workspace", "", "Restore a single workspace from the latest 'pack --workspaces' run")
+	cmd.Flags().String("profile", "", "Named profile from config (latest archive, password source, target)")
 	cmd.Flags().Bool("overwrite", false, "Overwrite existing files without prompting")
 	cmd.Flags().Bool("backup", false, "Create backups of existing files before overwriting")
 	cmd.Flags().Bool("verify", true, "Verify file checksums after extraction")
@@ -43,10 +67,26 @@ Examples:
 	cmd.Flags().BoolP("dry-run", "", false, "Show what would be extracted without actually doing it")
 	cmd.Flags().StringSliceP("include", "i", nil, "Only extract files matching these patterns")
 	cmd.Flags().StringSliceP("exclude", "e", nil, "Skip files matching these patterns")
+	cmd.Flags().Bool("ignore-expiry", false, "Unpack even if the archive has expired or is not valid yet")
 
 	return cmd
 }
 
+// unpackResult is the JSON result of the unpack command
+type unpackResult struct {
+	Archive            string       `json:"archive"`
+	Profile            string       `json:"profile,omitempty"`
+	Signer             string       `json:"signer,omitempty"`
+	Target             string       `json:"target"`
+	DryRun             bool         `json:"dry_run"`
+	Files              []fileResult `json:"files"`
+	Conflicts          []string     `json:"conflicts"`
+	Overwritten        bool         `json:"overwritten,omitempty"`
+	BackedUp           bool         `json:"backed_up,omitempty"`
+	VerificationErrors []string     `json:"verification_errors,omitempty"`
+	DurationMS         int64        `json:"unpack_duration_ms,omitempty"`
+}
+
 // runUnpackCommand executes the unpack command
 func runUnpackCommand(cmd *cobra.Command, args []string) error {
 	out := NewOutput(appVersion)
@@ -64,15 +104,34 @@ func runUnpackCommand(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
+	if tagErr := applyUnpackTag(app, opts); tagErr != nil {
+		out.Header()
+		out.Blank()
+		out.Error(tagErr.Error())
+		return tagErr
+	}
+
+	if profileErr := applyUnpackProfile(app, opts, cmd.Flags().Changed("target")); profileErr != nil {
+		out.Header()
+		out.Blank()
+		out.Error(profileErr.Error())
+		return profileErr
+	}
+
+	out.Header()
+	out.Blank()
+
 	archiveFile, err := selectArchive(out, app, opts)
 	if err != nil {
 		return err
 	}
 
-	out.Header()
-	out.Blank()
+	signer, err := checkArchiveSignature(out, app, archiveFile)
+	if err != nil {
+		return err
+	}
 
-	key, cleanup, err := getPass(opts.PassEnv)
+	key, cleanup, err := getPass(app, opts.Password)
 	if err != nil {
 		out.Error(fmt.Sprintf("Failed to get password: %v", err))
 		return err
@@ -81,14 +140,41 @@ func runUnpackCommand(cmd *cobra.Command, args []string) error {
 
 	archive, err := decryptArchive(out, app, archiveFile, key)
 	if err != nil {
-		return fmt.Errorf("decryption failed")
+		return fmt.Errorf("decryption failed: %w", err)
+	}
+
+	if validityErr := archive.CheckValidity(time.Now()); validityErr != nil {
+		if !opts.IgnoreExpiry {
+			out.Error(validityErr.Error())
+			out.Hint("Use --ignore-expiry to unpack it anyway")
+			return &types.ArchiveError{Operation: "unpack", Path: archiveFile, Err: validityErr}
+		}
+		out.Warning(fmt.Sprintf("%v; unpacking anyway (--ignore-expiry)", validityErr))
+	}
+
+	if opts.Profile != "" && archive.Profile != "" && archive.Profile != opts.Profile {
+		out.Warning(fmt.Sprintf("Archive was created by profile %q, not %q", archive.Profile, opts.Profile))
 	}
 
 	filesToExtract := filterArchiveFiles(archive.Files, opts.Include, opts.Exclude)
 	displayUnpackFiles(out, filesToExtract, opts.Verbose)
 
+	res := &unpackResult{
+		Archive:   archiveFile,
+		Profile:   archive.Profile,
+		Signer:    signer,
+		Target:    opts.Target,
+		DryRun:    opts.DryRun,
+		Files:     fileResults(filesToExtract),
+		Conflicts: checkFileConflicts(filesToExtract, opts.Target),
+	}
+	if res.Conflicts == nil {
+		res.Conflicts = []string{}
+	}
+	setResultData(res)
+
 	if opts.DryRun {
-		conflicts := checkFileConflicts(filesToExtract, opts.Target)
+		conflicts := res.Conflicts
 		out.Success(fmt.Sprintf("Dry run: would extract %d files to %s", len(filesToExtract), opts.Target))
 		if len(conflicts) > 0 {
 			out.Indent(fmt.Sprintf("%d existing files would be affected", len(conflicts)))
@@ -97,30 +183,49 @@ func runUnpackCommand(cmd *cobra.Command, args []string) error {
 	}
 
 	if !handleConflicts(out, filesToExtract, opts) {
-		return fmt.Errorf("file conflicts detected, use --overwrite to proceed")
+		return fmt.Errorf("%w, use --overwrite to proceed", types.ErrConflict)
 	}
 
-	return executeUnpack(out, app, archiveFile, filesToExtract, opts, key)
+	return executeUnpack(out, app, archiveFile, filesToExtract, opts, key, res)
 }
 
 // selectArchive selects the archive file to unpack
 func selectArchive(out *Output, app *types.App, opts *UnpackOpts) (string, error) {
+	if opts.Workspace != "" {
+		return selectWorkspaceArchive(out, opts)
+	}
+
 	archiveFile, err := pickArchive(app, opts.Archive)
 	if err != nil {
 		out.Error(err.Error())
 		return "", err
 	}
 
-	if opts.Archive == "" {
-		out.Action(fmt.Sprintf("Using most recent archive: %s", filepath.Base(archiveFile)))
+	if opts.Tag != "" {
+		out.Action(fmt.Sprintf("Using archive tagged %s: %s", opts.Tag, archiveFile))
+	} else {
+		echoArchive(out, opts.Archive, archiveFile)
 	}
+	return archiveFile, nil
+}
 
-	if _, statErr := os.Stat(archiveFile); os.IsNotExist(statErr) {
-		out.Error(fmt.Sprintf("Archive not found: %s", archiveFile))
-		return "", statErr
+// applyUnpackTag resolves --tag to the newest archive carrying the tag
+func applyUnpackTag(app *types.App, opts *UnpackOpts) error {
+	switch {
+	case opts.Tag == "":
+		return nil
+	case opts.Archive != "":
+		return newUsageError("--tag cannot be combined with --file")
+	case opts.Workspace != "":
+		return newUsageError("--tag cannot be combined with --workspace")
 	}
 
-	return archiveFile, nil
+	archive, err := taggedArchive(app, opts.Tag)
+	if err != nil {
+		return err
+	}
+	opts.Archive = archive
+	return nil
 }
 
 // decryptArchive decrypts and reads the archive
@@ -167,7 +272,7 @@ func handleConflicts(out *Output, files []types.EnvFile, opts *UnpackOpts) bool
 }
 
 // executeUnpack performs the actual unpacking operation
-func executeUnpack(out *Output, app *types.App, archiveFile string, files []types.EnvFile, opts *UnpackOpts, key string) error { //nolint:unparam // error return kept for consistency
+func executeUnpack(out *Output, app *types.App, archiveFile string, files []types.EnvFile, opts *UnpackOpts, key string, res *unpackResult) error { //nolint:unparam // error return kept for consistency
 	if opts.Verbose {
 		out.Action("Extracting...")
 	}
@@ -177,11 +282,12 @@ func executeUnpack(out *Output, app *types.App, archiveFile string, files []type
 
 	start := time.Now()
 	err := app.Archiver.Unpack(types.UnpackOptions{
-		ArchivePath: archiveFile,
-		Password:    key,
-		TargetDir:   opts.Target,
-		Overwrite:   opts.Overwrite,
-		Backup:      opts.Backup,
+		ArchivePath:  archiveFile,
+		Password:     key,
+		TargetDir:    opts.Target,
+		Overwrite:    opts.Overwrite,
+		Backup:       opts.Backup,
+		IgnoreExpiry: opts.IgnoreExpiry,
 	})
 	duration := time.Since(start)
 
@@ -190,16 +296,22 @@ func executeUnpack(out *Output, app *types.App, archiveFile string, files []type
 		return err
 	}
 
+	auditArchive(out, app, audit.OpUnpack, archiveFile, files)
+
+	res.DurationMS = duration.Milliseconds()
+	res.Overwritten = len(conflicts) > 0
+	res.BackedUp = len(conflicts) > 0 && opts.Backup
+
 	if opts.Verify {
-		verifyUnpackedFiles(out, files, opts.Target, opts.Verbose)
+		res.VerificationErrors = verifyUnpackedFiles(out, files, opts.Target, opts.Verbose)
 	}
 
 	displayUnpackResult(out, files, conflicts, opts, duration)
 	return nil
 }
 
-// verifyUnpackedFiles verifies extracted files
-func verifyUnpackedFiles(out *Output, files []types.EnvFile, targetDir string, verbose bool) {
+// verifyUnpackedFiles verifies extracted files and returns the problems found
+func verifyUnpackedFiles(out *Output, files []types.EnvFile, targetDir string, verbose bool) []string {
 	errs := verifyExtractedFiles(files, targetDir)
 	if len(errs) > 0 {
 		out.Warning("Verification warnings:")
@@ -209,6 +321,7 @@ func verifyUnpackedFiles(out *Output, files []types.EnvFile, targetDir string, v
 	} else if verbose {
 		out.Success("All files verified")
 	}
+	return errs
 }
 
 // displayUnpackResult shows the unpack result
@@ -269,7 +382,7 @@ func checkFileConflicts(files []types.EnvFile, targetDir string) []string {
 
 	for _, file := range files {
 		targetPath := filepath.Join(targetDir, file.RelativePath)
-		if _, err := os.Stat(targetPath); err == nil {
+		if _, err := os.Lstat(targetPath); err == nil {
 			conflicts = append(conflicts, file.RelativePath)
 		}
 	}
@@ -284,6 +397,16 @@ func verifyExtractedFiles(files []types.EnvFile, targetDir string) []string {
 	for _, file := range files {
 		targetPath := filepath.Join(targetDir, file.RelativePath)
 
+		if file.LinkTarget != "" {
+			if target, err := os.Readlink(targetPath); err != nil {
+				errors = append(errors, fmt.Sprintf("%s: link not found after extraction", file.RelativePath))
+			} else if target != file.LinkTarget {
+				errors = append(errors, fmt.Sprintf("%s: link target mismatch (expected %s, got %s)",
+					file.RelativePath, file.LinkTarget, target))
+			}
+			continue
+		}
+
 		info, err := os.Stat(targetPath)
 		if err != nil {
 			errors = append(errors, fmt.Sprintf("%s: file not found after extraction", file.RelativePath))
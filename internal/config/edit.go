@@ -0,0 +1,233 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"goingenv/pkg/types"
+)
+
+// Editor applies changes to a single config layer file. Only keys that are
+// explicitly set are written, so the file keeps inheriting everything else.
+type Editor struct {
+	m     *Manager
+	layer string
+	path  string
+	raw   map[string]json.RawMessage
+	below *types.Config // effective values from the layers under this one
+}
+
+// Edit opens the user or project config file for editing
+func (m *Manager) Edit(layer string) (*Editor, error) {
+	below := m.GetDefault()
+
+	var path string
+	switch layer {
+	case LayerUser:
+		path = m.configPath
+	case LayerProject:
+		if m.projectPath == "" {
+			return nil, fmt.Errorf("no project config available")
+		}
+		path = m.projectPath
+		user, err := readRaw(m.configPath)
+		if err != nil {
+			return nil, err
+		}
+		if err := applyRaw(newLayered(below), user, m.configPath, LayerUser); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("cannot edit the %s layer", layer)
+	}
+
+	raw, err := readRaw(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Editor{m: m, layer: layer, path: path, raw: raw, below: below}, nil
+}
+
+// Path returns the file being edited
+func (e *Editor) Path() string {
+	return e.path
+}
+
+// Set replaces a setting's value. Lists take one value per element; scalars
+// take exactly one value.
+func (e *Editor) Set(s Setting, values []string) error {
+	raw, err := s.Encode(values)
+	if err != nil {
+		return err
+	}
+	e.raw[s.Key] = raw
+	return nil
+}
+
+// Add appends values to a list setting, skipping ones already present
+func (e *Editor) Add(s Setting, values []string) error {
+	current, err := e.list(s)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		if !containsString(current, v) {
+			current = append(current, v)
+		}
+	}
+	return e.Set(s, current)
+}
+
+// Remove deletes values from a list setting
+func (e *Editor) Remove(s Setting, values []string) error {
+	current, err := e.list(s)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		if !containsString(current, v) {
+			return &types.ValidationError{Field: s.Field, Value: v, Message: fmt.Sprintf("%q is not in the list", v)}
+		}
+	}
+
+	kept := []string{}
+	for _, v := range current {
+		if !containsString(values, v) {
+			kept = append(kept, v)
+		}
+	}
+	return e.Set(s, kept)
+}
+
+// Reset drops a setting from this file so it inherits from the layers below
+func (e *Editor) Reset(s Setting) {
+	delete(e.raw, s.Key)
+}
+
+// ResetAll drops every setting from this file
+func (e *Editor) ResetAll() {
+	for _, s := range Settings {
+		delete(e.raw, s.Key)
+	}
+}
+
+// Check returns the validation problems caused by settings in this file,
+// both in the effective configuration and in the file read on its own (a
+// value can be hidden by a higher layer today and exposed later). Problems
+// from other layers are ignored so that a broken file can always be fixed.
+func (e *Editor) Check() (*Layered, []*types.ValidationError, error) {
+	effective, err := e.m.loadLayers(e)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	below := *e.below
+	local := newLayered(&below)
+	if err := applyRaw(local, e.raw, e.path, e.layer); err != nil {
+		return nil, nil, err
+	}
+
+	var problems []*types.ValidationError
+	seen := make(map[string]bool)
+	for _, l := range []*Layered{effective, local} {
+		for _, problem := range e.m.ValidateAll(l.Config) {
+			setting, ok := settingForField(problem.Field)
+			if !ok || l.Origins[setting.Key].Layer != e.layer || seen[problem.Error()] {
+				continue
+			}
+			seen[problem.Error()] = true
+			problems = append(problems, problem)
+		}
+	}
+
+	return effective, problems, nil
+}
+
+// Save validates the resulting configuration and atomically writes the file
+func (e *Editor) Save() error {
+	_, problems, err := e.Check()
+	if err != nil {
+		return err
+	}
+	if len(problems) > 0 {
+		origins := make(map[string]Origin, len(Settings))
+		for _, s := range Settings {
+			origins[s.Key] = Origin{Layer: e.layer, Source: e.path}
+		}
+		return DescribeOrigin(problems[0], origins)
+	}
+
+	return writeRaw(e.path, e.raw)
+}
+
+// list returns the current value of a list setting in this layer, falling
+// back to the value inherited from below
+func (e *Editor) list(s Setting) ([]string, error) {
+	if !s.IsList() {
+		return nil, &types.ValidationError{
+			Field:   s.Field,
+			Message: fmt.Sprintf("is %s, not a list; use 'set' instead", s.TypeName()),
+		}
+	}
+
+	cfg := *e.below
+	if raw, ok := e.raw[s.Key]; ok {
+		if err := s.SetJSON(&cfg, raw); err != nil {
+			return nil, err
+		}
+	}
+
+	list, ok := s.Get(&cfg).([]string)
+	if !ok {
+		return nil, fmt.Errorf("setting %s is not a string list", s.Key)
+	}
+	return append([]string{}, list...), nil
+}
+
+// Encode converts command-line values to the JSON stored in a config file
+func (s Setting) Encode(values []string) (json.RawMessage, error) {
+	if s.IsList() {
+		if values == nil {
+			values = []string{}
+		}
+		return json.Marshal(values)
+	}
+
+	if len(values) != 1 {
+		return nil, &types.ValidationError{
+			Field:   s.Field,
+			Value:   values,
+			Message: fmt.Sprintf("expected exactly one value, got %d", len(values)),
+		}
+	}
+
+	if s.IsObject() {
+		if !json.Valid([]byte(values[0])) {
+			return nil, &types.ValidationError{Field: s.Field, Value: values[0], Message: "must be a JSON object"}
+		}
+		return json.RawMessage(values[0]), nil
+	}
+
+	if kind := s.field(&types.Config{}).Kind(); kind == reflect.Int || kind == reflect.Int64 {
+		n, err := strconv.ParseInt(strings.TrimSpace(values[0]), 10, 64)
+		if err != nil {
+			return nil, &types.ValidationError{Field: s.Field, Value: values[0], Message: "must be an integer"}
+		}
+		return json.Marshal(n)
+	}
+
+	return json.Marshal(values[0])
+}
+
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func newEditTestManager(t *testing.T) (m *Manager, cleanup func()) {
+	t.Helper()
+	tmpDir, err := os.MkdirTemp("", "goingenv-config-edit-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	m = &Manager{
+		configPath:  filepath.Join(tmpDir, ".goingenv.json"),
+		projectPath: filepath.Join(tmpDir, "project", "config.json"),
+		lookupEnv:   func(string) (string, bool) { return "", false },
+	}
+	return m, func() { os.RemoveAll(tmpDir) }
+}
+
+func readKeys(t *testing.T, path string) map[string]json.RawMessage {
+	t.Helper()
+	raw, err := readRaw(path)
+	if err != nil {
+		t.Fatalf("readRaw() error = %v", err)
+	}
+	return raw
+}
+
+func TestEditor_SetAddRemoveReset(t *testing.T) {
+	m, cleanup := newEditTestManager(t)
+	defer cleanup()
+
+	editor, err := m.Edit(LayerProject)
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	depth, _ := LookupSetting("default_depth")
+	patterns, _ := LookupSetting("env_patterns")
+
+	if err := editor.Set(depth, []string{"4"}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := editor.Add(patterns, []string{`\.secrets$`, `\.env.*`}); err != nil {
+		t.Fatalf("Add() error = %v", err)
+	}
+	if err := editor.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	raw := readKeys(t, m.projectPath)
+	delete(raw, versionKey)
+	if len(raw) != 2 {
+		t.Errorf("project file keys = %v, want only the edited keys", raw)
+	}
+
+	cfg, err := m.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.DefaultDepth != 4 {
+		t.Errorf("DefaultDepth = %d, want 4", cfg.DefaultDepth)
+	}
+	if strings.Join(cfg.EnvPatterns, " ") != `\.env.* \.secrets$` {
+		t.Errorf("EnvPatterns = %v, want default plus added pattern without duplicates", cfg.EnvPatterns)
+	}
+
+	editor, err = m.Edit(LayerProject)
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if err := editor.Remove(patterns, []string{`\.env.*`}); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if err := editor.Remove(patterns, []string{"missing"}); err == nil {
+		t.Error("Remove() of an absent value should fail")
+	}
+	editor.Reset(depth)
+	if err := editor.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	cfg, err = m.Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.DefaultDepth != m.GetDefault().DefaultDepth {
+		t.Errorf("DefaultDepth = %d, want default after reset", cfg.DefaultDepth)
+	}
+	if len(cfg.EnvPatterns) != 1 || cfg.EnvPatterns[0] != `\.secrets$` {
+		t.Errorf("EnvPatterns = %v, want [\\.secrets$]", cfg.EnvPatterns)
+	}
+}
+
+func TestEditor_Errors(t *testing.T) {
+	tests := []struct {
+		name      string
+		key       string
+		op        string
+		values    []string
+		errSubstr string
+	}{
+		{name: "Non-integer", key: "max_file_size", op: "set", values: []string{"big"}, errSubstr: "must be an integer"},
+		{name: "Too many values", key: "default_depth", op: "set", values: []string{"1", "2"}, errSubstr: "exactly one value"},
+		{name: "Add to scalar", key: "default_depth", op: "add", values: []string{"3"}, errSubstr: "not a list"},
+		{name: "Out of range", key: "default_depth", op: "set", values: []string{"0"}, errSubstr: "between 1 and 50"},
+		{name: "Bad regex", key: "exclude_patterns", op: "add", values: []string{"[a"}, errSubstr: "invalid regular expression"},
+		{name: "Bad policy", key: "symlink_policy", op: "set", values: []string{"copy"}, errSubstr: "must be one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, cleanup := newEditTestManager(t)
+			defer cleanup()
+
+			editor, err := m.Edit(LayerUser)
+			if err != nil {
+				t.Fatalf("Edit() error = %v", err)
+			}
+			setting, _ := LookupSetting(tt.key)
+
+			if tt.op == "add" {
+				err = editor.Add(setting, tt.values)
+			} else {
+				err = editor.Set(setting, tt.values)
+			}
+			if err == nil {
+				err = editor.Save()
+			}
+
+			if err == nil || !strings.Contains(err.Error(), tt.errSubstr) {
+				t.Errorf("edit error = %v, want error containing %q", err, tt.errSubstr)
+			}
+			if _, statErr := os.Stat(m.configPath); !os.IsNotExist(statErr) {
+				t.Error("config file should not be written when the edit is invalid")
+			}
+		})
+	}
+}
+
+func TestEditor_FixesBrokenFile(t *testing.T) {
+	m, cleanup := newEditTestManager(t)
+	defer cleanup()
+
+	if err := os.WriteFile(m.configPath, []byte(`{"env_patterns": ["("]}`), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	// An unrelated edit to the project file is still allowed
+	editor, err := m.Edit(LayerProject)
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	depth, _ := LookupSetting("default_depth")
+	if err := editor.Set(depth, []string{"3"}); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := editor.Save(); err != nil {
+		t.Errorf("Save() error = %v, want problems in other layers ignored", err)
+	}
+
+	// Resetting the broken key repairs the user file
+	editor, err = m.Edit(LayerUser)
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	patterns, _ := LookupSetting("env_patterns")
+	editor.Reset(patterns)
+	if err := editor.Save(); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := m.Load(); err != nil {
+		t.Errorf("Load() error = %v after reset", err)
+	}
+}
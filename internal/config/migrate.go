@@ -0,0 +1,219 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"goingenv/pkg/utils"
+)
+
+// CurrentVersion is the config schema version written by this build.
+// Files without a version field are version 0.
+const CurrentVersion = 1
+
+// versionKey is the JSON key holding the schema version
+const versionKey = "version"
+
+// migration upgrades a raw config file from one schema version to the next
+type migration struct {
+	From        int
+	Description string
+	Apply       func(raw map[string]json.RawMessage) error
+}
+
+// migrations must stay ordered by From, one step per version
+var migrations = []migration{
+	{
+		From:        0,
+		Description: "keep only the settings that differ from the defaults",
+		Apply:       migrateV0Snapshot,
+	},
+}
+
+// v0Defaults holds the values version 0 wrote for its settings. Version 0
+// saved the whole config, so a file holding every one of these keys is a
+// snapshot in which unchanged settings were pinned to the defaults of the
+// day. Frozen here so later changes to GetDefault do not affect the step.
+var v0Defaults = map[string]string{
+	"default_depth":        `10`,
+	"env_patterns":         `["\\.env.*"]`,
+	"env_exclude_patterns": `[]`,
+	"exclude_patterns":     `["node_modules/", "\\.git/", "vendor/", "dist/", "build/", "target/", "bin/", "obj/", "\\.next/", "\\.nuxt/", "coverage/"]`,
+	"max_file_size":        `10485760`,
+}
+
+// migrateV0Snapshot drops null values and, in files saved whole by version
+// 0, the settings still holding the version 0 defaults, so they inherit from
+// lower layers and follow future default changes
+func migrateV0Snapshot(raw map[string]json.RawMessage) error {
+	snapshot := true
+	for key := range v0Defaults {
+		if _, ok := raw[key]; !ok {
+			snapshot = false
+		}
+	}
+
+	for key, value := range raw {
+		if string(value) == "null" {
+			delete(raw, key)
+			continue
+		}
+		if def, ok := v0Defaults[key]; ok && snapshot {
+			same, err := jsonEqual(value, json.RawMessage(def))
+			if err != nil {
+				return fmt.Errorf("invalid value for %s: %w", key, err)
+			}
+			if same {
+				delete(raw, key)
+			}
+		}
+	}
+	return nil
+}
+
+// jsonEqual reports whether two JSON values decode to the same value
+func jsonEqual(a, b json.RawMessage) (bool, error) {
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(x, y), nil
+}
+
+// MigrationReport describes the migration of one config file
+type MigrationReport struct {
+	Path    string
+	From    int
+	To      int
+	Steps   []string
+	Removed []string // keys the migration drops, sorted
+}
+
+// Changed reports whether the file needed migrating
+func (r MigrationReport) Changed() bool {
+	return r.From != r.To
+}
+
+// rawVersion returns the schema version recorded in a raw config file
+func rawVersion(raw map[string]json.RawMessage) (int, error) {
+	value, ok := raw[versionKey]
+	if !ok {
+		return 0, nil
+	}
+	var version int
+	if err := json.Unmarshal(value, &version); err != nil {
+		return 0, fmt.Errorf("invalid config version %s: %w", value, err)
+	}
+	return version, nil
+}
+
+// migrateRaw upgrades raw in place to CurrentVersion, returning the
+// descriptions of the steps applied. Unknown keys are left untouched.
+func migrateRaw(raw map[string]json.RawMessage) (from int, steps []string, err error) {
+	from, err = rawVersion(raw)
+	if err != nil {
+		return 0, nil, err
+	}
+	if from > CurrentVersion {
+		return from, nil, fmt.Errorf("config version %d is newer than this goingenv supports (%d); please upgrade", from, CurrentVersion)
+	}
+
+	for _, m := range migrations {
+		if m.From < from {
+			continue
+		}
+		if err := m.Apply(raw); err != nil {
+			return from, steps, fmt.Errorf("migration from version %d failed: %w", m.From, err)
+		}
+		steps = append(steps, fmt.Sprintf("v%d -> v%d: %s", m.From, m.From+1, m.Description))
+	}
+
+	if from != CurrentVersion {
+		raw[versionKey] = json.RawMessage(fmt.Sprint(CurrentVersion))
+	}
+	return from, steps, nil
+}
+
+// Migrate upgrades the user and project config files to CurrentVersion.
+// With dryRun set nothing is written.
+func (m *Manager) Migrate(dryRun bool) ([]MigrationReport, error) {
+	var reports []MigrationReport
+
+	for _, path := range []string{m.configPath, m.projectPath} {
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return reports, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		raw := map[string]json.RawMessage{}
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return reports, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+
+		keys := make([]string, 0, len(raw))
+		for key := range raw {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		from, steps, err := migrateRaw(raw)
+		if err != nil {
+			return reports, fmt.Errorf("%s: %w", path, err)
+		}
+		report := MigrationReport{Path: path, From: from, To: CurrentVersion, Steps: steps}
+		for _, key := range keys {
+			if _, ok := raw[key]; !ok {
+				report.Removed = append(report.Removed, key)
+			}
+		}
+		reports = append(reports, report)
+
+		if dryRun || !report.Changed() {
+			continue
+		}
+		if err := writeRaw(path, raw); err != nil {
+			return reports, err
+		}
+	}
+
+	return reports, nil
+}
+
+// writeRaw atomically writes a raw config file, stamping the current version
+func writeRaw(path string, raw map[string]json.RawMessage) error {
+	raw[versionKey] = json.RawMessage(fmt.Sprint(CurrentVersion))
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+
+	if err := utils.WriteFileAtomic(path, append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
+// Schema is the JSON Schema describing config files, for editor completion
+// and validation
+//
+//go:embed schema.json
+var Schema []byte
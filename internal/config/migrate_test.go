@@ -0,0 +1,287 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestMigrateRaw(t *testing.T) {
+	tests := []struct {
+		name      string
+		input     string
+		wantFrom  int
+		wantSteps int
+		wantKeys  []string
+		wantErr   bool
+	}{
+		{
+			name:      "Unversioned file",
+			input:     `{"default_depth": 3, "exclude_patterns": null}`,
+			wantFrom:  0,
+			wantSteps: 1,
+			wantKeys:  []string{"default_depth", "version"},
+		},
+		{
+			name:      "Version 0 snapshot",
+			input:     `{"default_depth": 10, "env_patterns": ["\\.env.*"], "env_exclude_patterns": [], "exclude_patterns": [], "max_file_size": 10485760}`,
+			wantFrom:  0,
+			wantSteps: 1,
+			wantKeys:  []string{"exclude_patterns", "version"},
+		},
+		{
+			name:      "Default value outside a snapshot",
+			input:     `{"default_depth": 10}`,
+			wantFrom:  0,
+			wantSteps: 1,
+			wantKeys:  []string{"default_depth", "version"},
+		},
+		{
+			name:     "Current version",
+			input:    `{"version": 1, "default_depth": 3}`,
+			wantFrom: 1,
+			wantKeys: []string{"default_depth", "version"},
+		},
+		{
+			name:     "Unknown keys kept",
+			input:    `{"version": 1, "added_later": [1, 2]}`,
+			wantFrom: 1,
+			wantKeys: []string{"added_later", "version"},
+		},
+		{
+			name:    "Newer version",
+			input:   `{"version": 99}`,
+			wantErr: true,
+		},
+		{
+			name:    "Invalid version",
+			input:   `{"version": "one"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := map[string]json.RawMessage{}
+			if err := json.Unmarshal([]byte(tt.input), &raw); err != nil {
+				t.Fatalf("Failed to parse input: %v", err)
+			}
+
+			from, steps, err := migrateRaw(raw)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("migrateRaw() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if from != tt.wantFrom || len(steps) != tt.wantSteps {
+				t.Errorf("migrateRaw() = %d, %v, want from %d with %d steps", from, steps, tt.wantFrom, tt.wantSteps)
+			}
+			if len(raw) != len(tt.wantKeys) {
+				t.Errorf("keys = %v, want %v", raw, tt.wantKeys)
+			}
+			for _, key := range tt.wantKeys {
+				if _, ok := raw[key]; !ok {
+					t.Errorf("key %s missing after migration", key)
+				}
+			}
+		})
+	}
+}
+
+func TestManager_Migrate(t *testing.T) {
+	m, cleanup := newEditTestManager(t)
+	defer cleanup()
+
+	original := `{"default_depth": 3, "env_exclude_patterns": null, "unknown": "kept"}`
+	if err := os.WriteFile(m.configPath, []byte(original), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	reports, err := m.Migrate(true)
+	if err != nil {
+		t.Fatalf("Migrate(dry run) error = %v", err)
+	}
+	if len(reports) != 1 || !reports[0].Changed() {
+		t.Fatalf("Migrate(dry run) reports = %+v, want one changed file", reports)
+	}
+	data, err := os.ReadFile(m.configPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if string(data) != original {
+		t.Error("dry run should not modify the file")
+	}
+
+	if _, err := m.Migrate(false); err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	raw := readKeys(t, m.configPath)
+	if string(raw["version"]) != "1" || string(raw["unknown"]) != `"kept"` {
+		t.Errorf("migrated file = %v, want version 1 and unknown key kept", raw)
+	}
+	if _, ok := raw["env_exclude_patterns"]; ok {
+		t.Error("null value should be dropped by migration")
+	}
+
+	reports, err = m.Migrate(false)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if reports[0].Changed() {
+		t.Error("second Migrate() should find the file up to date")
+	}
+}
+
+// v0Config is a user config as version 0 saved it: the whole config, with
+// only default_depth and env_patterns changed from the defaults
+const v0Config = `{
+  "default_depth": 3,
+  "env_patterns": [
+    "\\.env.*",
+    "\\.secrets"
+  ],
+  "env_exclude_patterns": [],
+  "exclude_patterns": [
+    "node_modules/",
+    "\\.git/",
+    "vendor/",
+    "dist/",
+    "build/",
+    "target/",
+    "bin/",
+    "obj/",
+    "\\.next/",
+    "\\.nuxt/",
+    "coverage/"
+  ],
+  "max_file_size": 10485760
+}`
+
+func TestManager_Migrate_V0Snapshot(t *testing.T) {
+	m, cleanup := newEditTestManager(t)
+	defer cleanup()
+
+	if err := os.WriteFile(m.configPath, []byte(v0Config), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	before, err := m.LoadUser()
+	if err != nil {
+		t.Fatalf("LoadUser() before migrating error = %v", err)
+	}
+
+	reports, err := m.Migrate(false)
+	if err != nil {
+		t.Fatalf("Migrate() error = %v", err)
+	}
+	if len(reports) != 1 || reports[0].From != 0 || len(reports[0].Steps) != 1 {
+		t.Fatalf("Migrate() reports = %+v, want one file migrated from version 0", reports)
+	}
+	wantRemoved := []string{"env_exclude_patterns", "exclude_patterns", "max_file_size"}
+	if !reflect.DeepEqual(reports[0].Removed, wantRemoved) {
+		t.Errorf("Removed = %v, want %v", reports[0].Removed, wantRemoved)
+	}
+
+	raw := readKeys(t, m.configPath)
+	if len(raw) != 3 || string(raw["version"]) != "1" || string(raw["default_depth"]) != "3" {
+		t.Errorf("migrated file = %v, want only version, default_depth and env_patterns", raw)
+	}
+	if _, ok := raw["env_patterns"]; !ok {
+		t.Error("changed env_patterns should be kept")
+	}
+
+	after, err := m.LoadUser()
+	if err != nil {
+		t.Fatalf("LoadUser() after migrating error = %v", err)
+	}
+	if !reflect.DeepEqual(before, after) {
+		t.Errorf("LoadUser() = %+v after migrating, want %+v", after, before)
+	}
+}
+
+func TestManager_Save_PreservesUnknownKeys(t *testing.T) {
+	m, cleanup := newEditTestManager(t)
+	defer cleanup()
+
+	if err := os.WriteFile(m.configPath, []byte(`{"default_depth": 3, "from_the_future": {"a": 1}}`), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := m.LoadUser()
+	if err != nil {
+		t.Fatalf("LoadUser() error = %v", err)
+	}
+	cfg.DefaultDepth = 7
+	if err := m.Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	raw := readKeys(t, m.configPath)
+	if string(raw["default_depth"]) != "7" {
+		t.Errorf("default_depth = %s, want 7", raw["default_depth"])
+	}
+	if _, ok := raw["from_the_future"]; !ok {
+		t.Error("Save() dropped an unknown key")
+	}
+}
+
+func TestManager_Save_ClearsEmptiedKeys(t *testing.T) {
+	m, cleanup := newEditTestManager(t)
+	defer cleanup()
+
+	content := `{"default_depth": 3, "symlink_policy": "skip", "workspaces": ["apps/*"], "retention": {"keep_last": 5}}`
+	if err := os.WriteFile(m.configPath, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	cfg, err := m.LoadUser()
+	if err != nil {
+		t.Fatalf("LoadUser() error = %v", err)
+	}
+	cfg.SymlinkPolicy, cfg.Workspaces, cfg.Retention = "", nil, nil
+	if err := m.Save(cfg); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	raw := readKeys(t, m.configPath)
+	for _, key := range []string{"symlink_policy", "workspaces", "retention"} {
+		if value, ok := raw[key]; ok {
+			t.Errorf("%s = %s after clearing it, want the key removed", key, value)
+		}
+	}
+}
+
+func TestSchema(t *testing.T) {
+	var schema struct {
+		Properties map[string]struct {
+			Type    string `json:"type"`
+			Maximum *int   `json:"maximum"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+
+	for _, s := range Settings {
+		prop, ok := schema.Properties[s.Key]
+		if !ok {
+			t.Errorf("schema is missing setting %s", s.Key)
+			continue
+		}
+		wantType := map[string]string{
+			"an integer":        "integer",
+			"a string":          "string",
+			"a list of strings": "array",
+			"an object":         "object",
+		}[s.TypeName()]
+		if prop.Type != wantType {
+			t.Errorf("schema type of %s = %s, want %s", s.Key, prop.Type, wantType)
+		}
+	}
+
+	version, ok := schema.Properties[versionKey]
+	if !ok || version.Maximum == nil || *version.Maximum != CurrentVersion {
+		t.Errorf("schema version maximum should be CurrentVersion (%d)", CurrentVersion)
+	}
+}
@@ -0,0 +1,195 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"goingenv/internal/constants"
+	"goingenv/internal/retention"
+	"goingenv/pkg/types"
+)
+
+// userOnlyProfileKeys are profile keys that run a command or read from a
+// path or descriptor of the user's choosing. The project config is committed
+// by anyone with push access, so they are only honored from the user config.
+var userOnlyProfileKeys = []string{"password_cmd", "password_file", "password_fd"}
+
+// checkProjectProfiles rejects user-only keys in the profiles of a project
+// config file
+func checkProjectProfiles(raw map[string]json.RawMessage) error {
+	value, ok := raw["profiles"]
+	if !ok {
+		return nil
+	}
+	var profiles map[string]map[string]json.RawMessage
+	if err := json.Unmarshal(value, &profiles); err != nil {
+		return nil // reported with the profile's type error
+	}
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, key := range userOnlyProfileKeys {
+			if _, found := profiles[name][key]; found {
+				return fmt.Errorf("profile %q: %s may only be set in the user config", name, key)
+			}
+		}
+	}
+	return nil
+}
+
+// keepUserPasswordSources carries the user-only password sources of the
+// profiles in below over to the same profiles from the project config
+func keepUserPasswordSources(profiles, below map[string]types.Profile) {
+	for name, profile := range profiles {
+		user, ok := below[name]
+		if !ok {
+			continue
+		}
+		profile.PasswordCmd, profile.PasswordFile, profile.PasswordFD = user.PasswordCmd, user.PasswordFile, user.PasswordFD
+		profiles[name] = profile
+	}
+}
+
+// profileNamePattern restricts profile names and archive prefixes to
+// characters that are safe in file names
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// GetProfile returns the named profile, listing the available ones on failure
+func GetProfile(cfg *types.Config, name string) (types.Profile, error) {
+	profile, ok := cfg.Profiles[name]
+	if !ok {
+		if len(cfg.Profiles) == 0 {
+			return profile, fmt.Errorf("profile %q not found: no profiles are configured", name)
+		}
+		return profile, fmt.Errorf("profile %q not found (available: %s)", name, strings.Join(ProfileNames(cfg), ", "))
+	}
+	return profile, nil
+}
+
+// ProfileNames returns the configured profile names in sorted order
+func ProfileNames(cfg *types.Config) []string {
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ProfileArchivePrefix returns the file name prefix of a profile's archives
+func ProfileArchivePrefix(name string, profile types.Profile) string {
+	if profile.ArchivePrefix != "" {
+		return profile.ArchivePrefix
+	}
+	return name
+}
+
+// GetProfileArchivePath generates a timestamped archive path for a profile
+func GetProfileArchivePath(name string, profile types.Profile) string {
+	return filepath.Join(GetGoingEnvDir(), fmt.Sprintf("%s-%s%s",
+		ProfileArchivePrefix(name, profile), getCurrentTimestamp(), constants.ArchiveExtension))
+}
+
+// ProfileForArchive returns the profile whose prefix matches the archive file
+// name, or "" when none does. The longest matching prefix wins so that
+// "prod" and "prod-eu" can coexist.
+func ProfileForArchive(cfg *types.Config, archivePath string) string {
+	base := filepath.Base(archivePath)
+	match, matchLen := "", 0
+	for name, profile := range cfg.Profiles {
+		prefix := ProfileArchivePrefix(name, profile) + "-"
+		if strings.HasPrefix(base, prefix) && len(prefix) > matchLen && isTimestamped(base[len(prefix):]) {
+			match, matchLen = name, len(prefix)
+		}
+	}
+	return match
+}
+
+// ArchiveSeries returns the archive file name without its timestamp, so
+// archives from repeated packs of the same kind group together. Archives
+// without a timestamp form a series of their own.
+func ArchiveSeries(archivePath string) string {
+	base := filepath.Base(archivePath)
+	stem := strings.TrimSuffix(base, constants.ArchiveExtension)
+	if i := len(stem) - len(constants.TimestampFormat) - 1; i > 0 && stem[i] == '-' && isTimestamped(base[i+1:]) {
+		return stem[:i]
+	}
+	return base
+}
+
+// ArchiveTimestamp returns the local time in a timestamped archive file
+// name, reporting false for names without one
+func ArchiveTimestamp(archivePath string) (time.Time, bool) {
+	stem := strings.TrimSuffix(filepath.Base(archivePath), constants.ArchiveExtension)
+	if len(stem) < len(constants.TimestampFormat) {
+		return time.Time{}, false
+	}
+	ts, err := time.ParseInLocation(constants.TimestampFormat, stem[len(stem)-len(constants.TimestampFormat):], time.Local)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return ts, true
+}
+
+// isTimestamped reports whether rest is "<timestamp>.enc"
+func isTimestamped(rest string) bool {
+	ts := strings.TrimSuffix(rest, constants.ArchiveExtension)
+	return len(ts) == len(constants.TimestampFormat) && ts != rest
+}
+
+// validateProfiles checks profile names, patterns, targets and retention
+func validateProfiles(profiles map[string]types.Profile) []*types.ValidationError {
+	var errs []*types.ValidationError
+	invalid := func(name, message string) {
+		errs = append(errs, &types.ValidationError{
+			Field:   "Profiles",
+			Value:   name,
+			Message: fmt.Sprintf("profile %q: %s", name, message),
+		})
+	}
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		profile := profiles[name]
+		if !profileNamePattern.MatchString(name) {
+			invalid(name, "name may only contain letters, digits, '-' and '_'")
+		}
+		if profile.ArchivePrefix != "" && !profileNamePattern.MatchString(profile.ArchivePrefix) {
+			invalid(name, "archive_prefix may only contain letters, digits, '-' and '_'")
+		}
+		for _, pattern := range append(append([]string{}, profile.EnvPatterns...), profile.ExcludePatterns...) {
+			if _, err := regexp.Compile(pattern); err != nil {
+				invalid(name, fmt.Sprintf("invalid regular expression %q: %v", pattern, compileMessage(err)))
+			}
+		}
+		if target := filepath.Clean(profile.Target); profile.Target != "" &&
+			(filepath.IsAbs(target) || target == ".." || strings.HasPrefix(target, ".."+string(filepath.Separator))) {
+			invalid(name, "target must be a directory inside the project")
+		}
+		if err := retention.Validate(profile.Retention); err != nil {
+			invalid(name, fmt.Sprintf("retention: %v", err))
+		}
+		if profile.PasswordFD < 0 || profile.PasswordFD == 1 || profile.PasswordFD == 2 {
+			invalid(name, "password_fd must be an open descriptor other than 1 and 2")
+		}
+		if (profile.PasswordFile != "" && profile.PasswordFD != 0) || (profile.PasswordFile != "" && profile.PasswordCmd != "") ||
+			(profile.PasswordFD != 0 && profile.PasswordCmd != "") {
+			invalid(name, "use only one of password_file, password_fd and password_cmd")
+		}
+	}
+
+	return errs
+}
@@ -0,0 +1,99 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"goingenv/pkg/types"
+)
+
+func TestValidateProfiles(t *testing.T) {
+	tests := []struct {
+		name      string
+		profiles  map[string]types.Profile
+		errSubstr string
+	}{
+		{
+			name: "Valid profiles",
+			profiles: map[string]types.Profile{
+				"prod":    {EnvPatterns: []string{`\.env\.prod$`}, Target: "deploy/prod"},
+				"staging": {ArchivePrefix: "stage_1"},
+			},
+		},
+		{name: "Bad name", profiles: map[string]types.Profile{"../prod": {}}, errSubstr: "name may only contain"},
+		{name: "Bad prefix", profiles: map[string]types.Profile{"prod": {ArchivePrefix: "a/b"}}, errSubstr: "archive_prefix"},
+		{name: "Bad pattern", profiles: map[string]types.Profile{"prod": {ExcludePatterns: []string{"("}}}, errSubstr: "invalid regular expression"},
+		{name: "Absolute target", profiles: map[string]types.Profile{"prod": {Target: "/etc"}}, errSubstr: "inside the project"},
+		{name: "Bad retention", profiles: map[string]types.Profile{"prod": {Retention: &types.Retention{OlderThan: "soon"}}}, errSubstr: "retention: invalid age"},
+		{name: "Escaping target", profiles: map[string]types.Profile{"prod": {Target: "a/../../x"}}, errSubstr: "inside the project"},
+		{name: "Stdout password fd", profiles: map[string]types.Profile{"prod": {PasswordFD: 1}}, errSubstr: "password_fd"},
+		{name: "Several password sources", profiles: map[string]types.Profile{"prod": {PasswordFile: "/run/secrets/pw", PasswordCmd: "pass show pw"}}, errSubstr: "only one of"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateProfiles(tt.profiles)
+			if tt.errSubstr == "" {
+				if len(errs) != 0 {
+					t.Errorf("validateProfiles() = %v, want no errors", errs)
+				}
+				return
+			}
+			if len(errs) == 0 || !strings.Contains(errs[0].Error(), tt.errSubstr) {
+				t.Errorf("validateProfiles() = %v, want error containing %q", errs, tt.errSubstr)
+			}
+		})
+	}
+}
+
+func TestGetProfile(t *testing.T) {
+	cfg := &types.Config{Profiles: map[string]types.Profile{"prod": {}, "dev": {}}}
+
+	if _, err := GetProfile(cfg, "prod"); err != nil {
+		t.Errorf("GetProfile(prod) error = %v", err)
+	}
+	_, err := GetProfile(cfg, "qa")
+	if err == nil || !strings.Contains(err.Error(), "dev, prod") {
+		t.Errorf("GetProfile(qa) error = %v, want available profiles listed", err)
+	}
+}
+
+func TestArchiveSeries(t *testing.T) {
+	tests := []struct {
+		path string
+		want string
+	}{
+		{path: ".goingenv/archive-20240101-120000.enc", want: "archive"},
+		{path: ".goingenv/prod-eu-20240101-120000.enc", want: "prod-eu"},
+		{path: ".goingenv/archive-api-20240101-120000.enc", want: "archive-api"},
+		{path: ".goingenv/backup.enc", want: "backup.enc"},
+		{path: ".goingenv/20240101-120000.enc", want: "20240101-120000.enc"},
+	}
+
+	for _, tt := range tests {
+		if got := ArchiveSeries(tt.path); got != tt.want {
+			t.Errorf("ArchiveSeries(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestArchiveTimestamp(t *testing.T) {
+	tests := []struct {
+		path   string
+		want   time.Time
+		wantOK bool
+	}{
+		{path: ".goingenv/archive-20240102-150405.enc", want: time.Date(2024, 1, 2, 15, 4, 5, 0, time.Local), wantOK: true},
+		{path: ".goingenv/prod-eu-20240101-120000.enc", want: time.Date(2024, 1, 1, 12, 0, 0, 0, time.Local), wantOK: true},
+		{path: ".goingenv/backup.enc"},
+		{path: ".goingenv/archive-20241399-999999.enc"},
+	}
+
+	for _, tt := range tests {
+		got, ok := ArchiveTimestamp(tt.path)
+		if ok != tt.wantOK || !got.Equal(tt.want) {
+			t.Errorf("ArchiveTimestamp(%q) = %v, %v, want %v, %v", tt.path, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
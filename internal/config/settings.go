@@ -0,0 +1,168 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"goingenv/pkg/types"
+)
+
+// Setting describes one configuration key and how it maps onto types.Config
+type Setting struct {
+	Key         string // JSON key, e.g. "default_depth"
+	Field       string // types.Config field name, used in ValidationError
+	Env         string // environment variable override, if any
+	Description string
+}
+
+// Settings lists every configurable key in display order
+var Settings = []Setting{
+	{Key: "default_depth", Field: "DefaultDepth", Env: "GOINGENV_DEPTH",
+		Description: "Maximum directory depth to scan"},
+	{Key: "env_patterns", Field: "EnvPatterns", Env: "GOINGENV_ENV_PATTERNS",
+		Description: "Regular expressions matching environment file names"},
+	{Key: "env_exclude_patterns", Field: "EnvExcludePatterns", Env: "GOINGENV_ENV_EXCLUDE_PATTERNS",
+		Description: "Regular expressions for env file names to skip"},
+	{Key: "exclude_patterns", Field: "ExcludePatterns", Env: "GOINGENV_EXCLUDE_PATTERNS",
+		Description: "Regular expressions for directories to skip"},
+	{Key: "max_file_size", Field: "MaxFileSize", Env: "GOINGENV_MAX_FILE_SIZE",
+		Description: "Largest file size in bytes that will be packed"},
+	{Key: "min_password_score", Field: "MinPasswordScore", Env: "GOINGENV_MIN_PASSWORD_SCORE",
+		Description: "Minimum password strength (0-4) for new archives; 0 disables the check"},
+	{Key: "symlink_policy", Field: "SymlinkPolicy", Env: "GOINGENV_SYMLINK_POLICY",
+		Description: "How symlinks are handled: skip, follow, preserve-as-link"},
+	{Key: "signature_policy", Field: "SignaturePolicy", Env: "GOINGENV_SIGNATURE_POLICY",
+		Description: "How unsigned or untrusted archives are handled: off, warn, require"},
+	{Key: "audit_log", Field: "AuditLog", Env: "GOINGENV_AUDIT_LOG",
+		Description: "Where operations are recorded: project, project-and-user"},
+	{Key: "workspaces", Field: "Workspaces", Env: "GOINGENV_WORKSPACES",
+		Description: "Workspace directories or globs for 'pack --workspaces'"},
+	{Key: "profiles", Field: "Profiles",
+		Description: "Named profiles for 'pack/unpack --profile' (project config)"},
+	{Key: "retention", Field: "Retention",
+		Description: "Default archive retention rules for 'goingenv prune'"},
+}
+
+// LookupSetting finds a setting by JSON key or field name
+func LookupSetting(name string) (Setting, bool) {
+	for _, s := range Settings {
+		if s.Key == name || s.Field == name {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// settingForField returns the setting that owns a types.Config field
+func settingForField(field string) (Setting, bool) {
+	for _, s := range Settings {
+		if s.Field == field {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// field returns the addressable types.Config field for this setting
+func (s Setting) field(cfg *types.Config) reflect.Value {
+	return reflect.ValueOf(cfg).Elem().FieldByName(s.Field)
+}
+
+// Get returns the setting's current value in cfg
+func (s Setting) Get(cfg *types.Config) interface{} {
+	return s.field(cfg).Interface()
+}
+
+// IsList reports whether the setting holds a list of strings
+func (s Setting) IsList() bool {
+	return s.field(&types.Config{}).Kind() == reflect.Slice
+}
+
+// IsObject reports whether the setting holds a JSON object
+func (s Setting) IsObject() bool {
+	kind := s.field(&types.Config{}).Kind()
+	return kind == reflect.Map || kind == reflect.Ptr
+}
+
+// SetJSON decodes a raw JSON value into the setting
+func (s Setting) SetJSON(cfg *types.Config, raw json.RawMessage) error {
+	target := reflect.New(s.field(cfg).Type())
+	if err := json.Unmarshal(raw, target.Interface()); err != nil {
+		return &types.ValidationError{
+			Field:   s.Field,
+			Value:   string(raw),
+			Message: fmt.Sprintf("expected %s", s.TypeName()),
+		}
+	}
+	s.field(cfg).Set(target.Elem())
+	return nil
+}
+
+// SetString parses a string value (from an env var or flag) into the setting.
+// Lists are comma-separated.
+func (s Setting) SetString(cfg *types.Config, value string) error {
+	f := s.field(cfg)
+	switch f.Kind() {
+	case reflect.Int, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return &types.ValidationError{Field: s.Field, Value: value, Message: "must be an integer"}
+		}
+		f.SetInt(n)
+	case reflect.String:
+		f.SetString(strings.TrimSpace(value))
+	case reflect.Slice:
+		list := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		f.Set(reflect.ValueOf(list))
+	default:
+		return fmt.Errorf("unsupported setting type for %s", s.Key)
+	}
+	return nil
+}
+
+// TypeName describes the expected value type for error messages
+func (s Setting) TypeName() string {
+	switch s.field(&types.Config{}).Kind() {
+	case reflect.Int, reflect.Int64:
+		return "an integer"
+	case reflect.Slice:
+		return "a list of strings"
+	case reflect.Map, reflect.Ptr:
+		return "an object"
+	default:
+		return "a string"
+	}
+}
+
+// Format renders a setting value for display
+func (s Setting) Format(cfg *types.Config) string {
+	if list, ok := s.Get(cfg).([]string); ok {
+		return "[" + strings.Join(list, ", ") + "]"
+	}
+	if f := s.field(cfg); f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return "{}"
+		}
+		data, _ := json.Marshal(f.Interface()) //nolint:errcheck // plain structs always marshal
+		return string(data)
+	}
+	if s.IsObject() {
+		keys := s.field(cfg).MapKeys()
+		names := make([]string, 0, len(keys))
+		for _, k := range keys {
+			names = append(names, k.String())
+		}
+		sort.Strings(names)
+		return "{" + strings.Join(names, ", ") + "}"
+	}
+	return fmt.Sprint(s.Get(cfg))
+}
@@ -23,15 +23,49 @@ const (
 	PBKDF2Iterations = 100000
 )
 
+// KeyDeriver turns a password and salt into an encryption key. It lets a
+// caller reuse keys derived earlier, e.g. by the key agent.
+type KeyDeriver interface {
+	DeriveKey(password string, salt []byte) ([]byte, error)
+}
+
 // Service implements the Cryptor interface
-type Service struct{}
+type Service struct {
+	deriver KeyDeriver
+}
 
 // NewService creates a new crypto service
 func NewService() *Service {
 	return &Service{}
 }
 
-// Encrypt encrypts data using AES-256-GCM with PBKDF2 key derivation
+// SetKeyDeriver replaces PBKDF2 key derivation; nil restores the default
+func (s *Service) SetKeyDeriver(d KeyDeriver) {
+	s.deriver = d
+}
+
+// DeriveKey derives the AES key for a password and salt using PBKDF2
+func DeriveKey(password string, salt []byte) []byte {
+	return pbkdf2.Key([]byte(password), salt, PBKDF2Iterations, KeySize, sha256.New)
+}
+
+// deriveKey derives a key with the configured deriver, falling back to PBKDF2.
+// The deriver only knows the default iteration count.
+func (s *Service) deriveKey(password string, salt []byte, iterations int) []byte {
+	if iterations != PBKDF2Iterations {
+		return pbkdf2.Key([]byte(password), salt, iterations, KeySize, sha256.New)
+	}
+	if s.deriver != nil {
+		if key, err := s.deriver.DeriveKey(password, salt); err == nil && len(key) == KeySize {
+			return key
+		}
+	}
+	return DeriveKey(password, salt)
+}
+
+// Encrypt encrypts data using AES-256-GCM with PBKDF2 key derivation. The
+// result is a version 2 header followed by the ciphertext; the header is
+// authenticated as additional data.
 func (s *Service) Encrypt(data []byte, password string) ([]byte, error) {
 	if len(data) == 0 {
 		return nil, &types.CryptoError{
@@ -56,8 +90,8 @@ func (s *Service) Encrypt(data []byte, password string) ([]byte, error) {
 		}
 	}
 
-	// Derive key using PBKDF2
-	key := pbkdf2.Key([]byte(password), salt, PBKDF2Iterations, KeySize, sha256.New)
+	// Derive key using PBKDF2 (or the configured deriver)
+	key := s.deriveKey(password, salt, PBKDF2Iterations)
 
 	// Create AES cipher
 	block, err := aes.NewCipher(key)
@@ -86,24 +120,19 @@ func (s *Service) Encrypt(data []byte, password string) ([]byte, error) {
 		}
 	}
 
-	// Encrypt data
-	ciphertext := gcm.Seal(nil, nonce, data, nil)
-
-	// Combine salt + nonce + ciphertext
-	result := make([]byte, SaltSize+NonceSize+len(ciphertext))
-	copy(result[:SaltSize], salt)
-	copy(result[SaltSize:SaltSize+NonceSize], nonce)
-	copy(result[SaltSize+NonceSize:], ciphertext)
-
-	return result, nil
+	// Encrypt data after the header, authenticating the header
+	h := newHeader(salt, nonce)
+	return gcm.Seal(h.raw, nonce, data, h.raw), nil
 }
 
-// Decrypt decrypts data using AES-256-GCM with PBKDF2 key derivation
+// Decrypt decrypts data using AES-256-GCM with PBKDF2 key derivation. Both
+// versioned and original headerless archives are accepted.
 func (s *Service) Decrypt(data []byte, password string) ([]byte, error) {
-	if len(data) < SaltSize+NonceSize {
+	h, ciphertext, err := parseHeader(data)
+	if err != nil {
 		return nil, &types.CryptoError{
 			Operation: "decrypt",
-			Err:       fmt.Errorf("invalid encrypted data: too short"),
+			Err:       err,
 		}
 	}
 
@@ -114,13 +143,8 @@ func (s *Service) Decrypt(data []byte, password string) ([]byte, error) {
 		}
 	}
 
-	// Extract salt, nonce, and ciphertext
-	salt := data[:SaltSize]
-	nonce := data[SaltSize : SaltSize+NonceSize]
-	ciphertext := data[SaltSize+NonceSize:]
-
-	// Derive key using PBKDF2
-	key := pbkdf2.Key([]byte(password), salt, PBKDF2Iterations, KeySize, sha256.New)
+	// Derive key using PBKDF2 (or the configured deriver)
+	key := s.deriveKey(password, h.salt, h.iterations)
 
 	// Create AES cipher
 	block, err := aes.NewCipher(key)
@@ -141,11 +165,11 @@ func (s *Service) Decrypt(data []byte, password string) ([]byte, error) {
 	}
 
 	// Decrypt data
-	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	plaintext, err := gcm.Open(nil, h.nonce, ciphertext, h.raw)
 	if err != nil {
 		return nil, &types.CryptoError{
 			Operation: "decrypt",
-			Err:       fmt.Errorf("decryption failed: invalid password or corrupted data"),
+			Err:       fmt.Errorf("decryption failed: %w", types.ErrWrongPassword),
 		}
 	}
 
@@ -171,20 +195,19 @@ func (s *Service) ValidatePassword(data []byte, password string) error {
 }
 
 // GenerateSecurePassword generates a cryptographically secure random password
+// drawn uniformly from PasswordCharset
 func GenerateSecurePassword(length int) (string, error) {
-	if length < 8 {
-		return "", fmt.Errorf("password length must be at least 8 characters")
+	if length < MinPasswordLength {
+		return "", fmt.Errorf("password length must be at least %d characters", MinPasswordLength)
 	}
 
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*"
 	password := make([]byte, length)
-
 	for i := range password {
-		randomByte := make([]byte, 1)
-		if _, err := rand.Read(randomByte); err != nil {
+		n, err := randomIndex(len(PasswordCharset))
+		if err != nil {
 			return "", fmt.Errorf("failed to generate random password: %w", err)
 		}
-		password[i] = charset[int(randomByte[0])%len(charset)]
+		password[i] = PasswordCharset[n]
 	}
 
 	return string(password), nil
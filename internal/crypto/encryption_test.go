@@ -2,6 +2,7 @@ package crypto
 
 import (
 	"bytes"
+	"errors"
 	"strings"
 	"testing"
 
@@ -120,10 +121,11 @@ func TestService_DecryptErrors(t *testing.T) {
 	service := NewService()
 
 	tests := []struct {
-		name     string
-		data     []byte
-		password string
-		wantErr  bool
+		name      string
+		data      []byte
+		password  string
+		wantErr   bool
+		wantWrong bool
 	}{
 		{
 			name:     "Invalid data - too short",
@@ -138,16 +140,18 @@ func TestService_DecryptErrors(t *testing.T) {
 			wantErr:  true,
 		},
 		{
-			name:     "Wrong password",
-			data:     mustEncrypt([]byte("test"), "correct"),
-			password: "wrong",
-			wantErr:  true,
+			name:      "Wrong password",
+			data:      mustEncrypt([]byte("test"), "correct"),
+			password:  "wrong",
+			wantErr:   true,
+			wantWrong: true,
 		},
 		{
-			name:     "Corrupted data",
-			data:     corruptData(mustEncrypt([]byte("test"), "correct")),
-			password: "correct",
-			wantErr:  true,
+			name:      "Corrupted data",
+			data:      corruptData(mustEncrypt([]byte("test"), "correct")),
+			password:  "correct",
+			wantErr:   true,
+			wantWrong: true,
 		},
 	}
 
@@ -157,6 +161,9 @@ func TestService_DecryptErrors(t *testing.T) {
 			if (err != nil) != tt.wantErr {
 				t.Errorf("Decrypt() error = %v, wantErr %v", err, tt.wantErr)
 			}
+			if got := errors.Is(err, types.ErrWrongPassword); got != tt.wantWrong {
+				t.Errorf("errors.Is(err, ErrWrongPassword) = %v, want %v", got, tt.wantWrong)
+			}
 
 			if err != nil {
 				if cryptoErr, ok := err.(*types.CryptoError); !ok {
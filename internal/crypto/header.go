@@ -0,0 +1,114 @@
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// Archive format versions. Version 1 is the original headerless layout,
+// salt(32) + nonce(12) + ciphertext, kept readable for existing archives.
+// Version 2 prefixes a header that records the KDF parameters and is passed
+// to AES-GCM as additional authenticated data, so changing any header byte
+// makes decryption fail instead of silently weakening the parameters.
+const (
+	FormatV1      = 1
+	FormatV2      = 2
+	FormatCurrent = FormatV2
+)
+
+// headerMagic starts every versioned archive
+const headerMagic = "GENV"
+
+// kdfPBKDF2SHA256 identifies PBKDF2-HMAC-SHA256 in the header
+const kdfPBKDF2SHA256 = 1
+
+// MaxPBKDF2Iterations caps the work a header can demand before the
+// authentication tag is checked
+const MaxPBKDF2Iterations = 100 * PBKDF2Iterations
+
+// HeaderSize is the size of a version 2 header:
+// magic(4) version(1) kdf(1) iterations(4) salt length(1) salt nonce
+const HeaderSize = len(headerMagic) + 1 + 1 + 4 + 1 + SaltSize + NonceSize
+
+// header holds the parameters needed to decrypt an archive
+type header struct {
+	version    int
+	iterations int
+	salt       []byte
+	nonce      []byte
+	raw        []byte // authenticated bytes; nil for version 1
+}
+
+// newHeader builds a current-version header
+func newHeader(salt, nonce []byte) header {
+	raw := make([]byte, 0, HeaderSize)
+	raw = append(raw, headerMagic...)
+	raw = append(raw, FormatCurrent, kdfPBKDF2SHA256)
+	raw = binary.BigEndian.AppendUint32(raw, PBKDF2Iterations)
+	raw = append(raw, byte(len(salt)))
+	raw = append(raw, salt...)
+	raw = append(raw, nonce...)
+
+	return header{
+		version:    FormatCurrent,
+		iterations: PBKDF2Iterations,
+		salt:       salt,
+		nonce:      nonce,
+		raw:        raw,
+	}
+}
+
+// parseHeader splits encrypted data into its header and ciphertext. Data
+// without the magic prefix is read as version 1.
+func parseHeader(data []byte) (header, []byte, error) {
+	if !bytes.HasPrefix(data, []byte(headerMagic)) {
+		if len(data) < SaltSize+NonceSize {
+			return header{}, nil, fmt.Errorf("invalid encrypted data: too short")
+		}
+		return header{
+			version:    FormatV1,
+			iterations: PBKDF2Iterations,
+			salt:       data[:SaltSize],
+			nonce:      data[SaltSize : SaltSize+NonceSize],
+		}, data[SaltSize+NonceSize:], nil
+	}
+
+	if len(data) < HeaderSize {
+		return header{}, nil, fmt.Errorf("invalid encrypted data: truncated header")
+	}
+
+	p := len(headerMagic)
+	version, kdf := int(data[p]), data[p+1]
+	iterations := int(binary.BigEndian.Uint32(data[p+2 : p+6]))
+	saltLen := int(data[p+6])
+	p += 7
+
+	switch {
+	case version != FormatV2:
+		return header{}, nil, fmt.Errorf("unsupported archive format version %d", version)
+	case kdf != kdfPBKDF2SHA256:
+		return header{}, nil, fmt.Errorf("unsupported key derivation function %d", kdf)
+	case iterations < PBKDF2Iterations || iterations > MaxPBKDF2Iterations:
+		return header{}, nil, fmt.Errorf("unsupported PBKDF2 iteration count %d", iterations)
+	case saltLen != SaltSize:
+		return header{}, nil, fmt.Errorf("unsupported salt length %d", saltLen)
+	}
+
+	return header{
+		version:    version,
+		iterations: iterations,
+		salt:       data[p : p+SaltSize],
+		nonce:      data[p+SaltSize : HeaderSize],
+		raw:        data[:HeaderSize],
+	}, data[HeaderSize:], nil
+}
+
+// FormatVersion reports the format version of encrypted archive data
+func FormatVersion(data []byte) (int, error) {
+	h, _, err := parseHeader(data)
+	if err != nil {
+		return 0, err
+	}
+	return h.version, nil
+}
@@ -0,0 +1,86 @@
+package crypto
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+)
+
+const (
+	// PasswordCharset is the alphabet used by GenerateSecurePassword
+	PasswordCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789!@#$%^&*"
+	// MinPasswordLength is the shortest password GenerateSecurePassword creates
+	MinPasswordLength = 8
+	// DefaultPassphraseWords is the number of words in a generated passphrase
+	DefaultPassphraseWords = 6
+	// MinPassphraseWords is the fewest words GeneratePassphrase accepts
+	MinPassphraseWords = 4
+	// DefaultPassphraseSeparator joins the words of a generated passphrase
+	DefaultPassphraseSeparator = "-"
+)
+
+//go:embed eff_large_wordlist.txt
+var wordlistData string
+
+// wordlist is the EFF large diceware wordlist (7776 words, five dice each)
+var wordlist = strings.Fields(wordlistData)
+
+// PassphraseOptions controls diceware passphrase generation
+type PassphraseOptions struct {
+	Words      int    // number of words, at least MinPassphraseWords
+	Separator  string // placed between words
+	Capitalize bool   // upper-case the first letter of each word
+}
+
+// GeneratePassphrase picks words uniformly at random from the embedded EFF
+// wordlist
+func GeneratePassphrase(opts PassphraseOptions) (string, error) {
+	if opts.Words < MinPassphraseWords {
+		return "", fmt.Errorf("passphrase must have at least %d words", MinPassphraseWords)
+	}
+
+	words := make([]string, opts.Words)
+	for i := range words {
+		n, err := randomIndex(len(wordlist))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate random passphrase: %w", err)
+		}
+		words[i] = wordlist[n]
+		if opts.Capitalize {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+
+	return strings.Join(words, opts.Separator), nil
+}
+
+// PassphraseEntropy returns the entropy in bits of a generated passphrase
+// with the given number of words. Separators and capitalization are fixed,
+// so they add nothing.
+func PassphraseEntropy(words int) float64 {
+	return float64(words) * math.Log2(float64(len(wordlist)))
+}
+
+// PasswordEntropy returns the entropy in bits of a password of the given
+// length from GenerateSecurePassword
+func PasswordEntropy(length int) float64 {
+	return float64(length) * math.Log2(float64(len(PasswordCharset)))
+}
+
+// WordlistSize returns the number of words in the embedded wordlist
+func WordlistSize() int {
+	return len(wordlist)
+}
+
+// randomIndex returns a uniformly distributed integer in [0, n). Unlike
+// reducing a random byte modulo n, it does not favor small values.
+func randomIndex(n int) (int, error) {
+	v, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(v.Int64()), nil
+}
@@ -0,0 +1,89 @@
+package crypto
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+func TestWordlist(t *testing.T) {
+	if WordlistSize() != 7776 {
+		t.Fatalf("WordlistSize() = %d, want 7776", WordlistSize())
+	}
+
+	seen := make(map[string]bool, len(wordlist))
+	for _, word := range wordlist {
+		if seen[word] {
+			t.Errorf("duplicate word %q", word)
+		}
+		seen[word] = true
+	}
+}
+
+func TestGeneratePassphrase(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    PassphraseOptions
+		wantErr bool
+	}{
+		{"Default", PassphraseOptions{Words: DefaultPassphraseWords, Separator: DefaultPassphraseSeparator}, false},
+		{"Minimum words", PassphraseOptions{Words: MinPassphraseWords, Separator: " "}, false},
+		{"Capitalized", PassphraseOptions{Words: 8, Separator: ".", Capitalize: true}, false},
+		{"Too few words", PassphraseOptions{Words: MinPassphraseWords - 1, Separator: "-"}, true},
+		{"Zero words", PassphraseOptions{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			passphrase, err := GeneratePassphrase(tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GeneratePassphrase() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			words := strings.Split(passphrase, tt.opts.Separator)
+			if len(words) != tt.opts.Words {
+				t.Fatalf("GeneratePassphrase() = %q, want %d words", passphrase, tt.opts.Words)
+			}
+			for _, word := range words {
+				if tt.opts.Capitalize != unicode.IsUpper(rune(word[0])) {
+					t.Errorf("word %q capitalization = %v, want %v", word, !tt.opts.Capitalize, tt.opts.Capitalize)
+				}
+			}
+		})
+	}
+}
+
+func TestGeneratedEntropy(t *testing.T) {
+	if got, want := PassphraseEntropy(6), 6*math.Log2(7776); math.Abs(got-want) > 1e-9 {
+		t.Errorf("PassphraseEntropy(6) = %f, want %f", got, want)
+	}
+	if got, want := PasswordEntropy(20), 20*math.Log2(70); math.Abs(got-want) > 1e-9 {
+		t.Errorf("PasswordEntropy(20) = %f, want %f", got, want)
+	}
+}
+
+func TestRandomIndex(t *testing.T) {
+	// Every value must be reachable, including those a byte-modulo
+	// reduction of 70 would under-sample
+	const n = 70
+	counts := make([]int, n)
+	for i := 0; i < n*200; i++ {
+		v, err := randomIndex(n)
+		if err != nil {
+			t.Fatalf("randomIndex() error = %v", err)
+		}
+		if v < 0 || v >= n {
+			t.Fatalf("randomIndex(%d) = %d, out of range", n, v)
+		}
+		counts[v]++
+	}
+	for v, c := range counts {
+		if c == 0 {
+			t.Errorf("randomIndex(%d) never returned %d", n, v)
+		}
+	}
+}
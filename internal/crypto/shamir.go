@@ -0,0 +1,208 @@
+package crypto
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Shamir share limits: x coordinates are non-zero bytes
+const (
+	MinShareThreshold = 2
+	MaxShares         = 255
+)
+
+// shareVersion prefixes every encoded share
+const shareVersion = "GE1"
+
+// shareEncoding is base32 without padding, easy to read aloud and retype
+var shareEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Share is one piece of a secret split with SplitSecret. Any Threshold
+// shares from the same set recover the secret; fewer reveal nothing.
+type Share struct {
+	SetID     string // random ID shared by all shares of one split
+	Threshold int
+	Index     int // x coordinate, 1-255
+	Data      []byte
+}
+
+// SplitSecret splits secret into n shares, any threshold of which recover it
+func SplitSecret(secret []byte, n, threshold int) ([]Share, error) {
+	switch {
+	case len(secret) == 0:
+		return nil, errors.New("secret cannot be empty")
+	case threshold < MinShareThreshold:
+		return nil, fmt.Errorf("threshold must be at least %d", MinShareThreshold)
+	case n < threshold:
+		return nil, fmt.Errorf("shares (%d) must be at least the threshold (%d)", n, threshold)
+	case n > MaxShares:
+		return nil, fmt.Errorf("at most %d shares are supported", MaxShares)
+	}
+
+	id := make([]byte, 4)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate share set ID: %w", err)
+	}
+	setID := strings.ToUpper(hex.EncodeToString(id))
+
+	shares := make([]Share, n)
+	for i := range shares {
+		shares[i] = Share{SetID: setID, Threshold: threshold, Index: i + 1, Data: make([]byte, len(secret))}
+	}
+
+	// One random polynomial of degree threshold-1 per secret byte, with the
+	// byte as its constant term
+	coeffs := make([]byte, threshold)
+	for b, value := range secret {
+		coeffs[0] = value
+		if _, err := rand.Read(coeffs[1:]); err != nil {
+			return nil, fmt.Errorf("failed to generate share coefficients: %w", err)
+		}
+		for i := range shares {
+			shares[i].Data[b] = gfEval(coeffs, byte(shares[i].Index))
+		}
+	}
+	clear(coeffs)
+
+	return shares, nil
+}
+
+// CombineShares recovers the secret from at least Threshold shares of one set
+func CombineShares(shares []Share) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, errors.New("no shares given")
+	}
+
+	first := shares[0]
+	seen := make(map[int]bool, len(shares))
+	var unique []Share
+	for _, s := range shares {
+		switch {
+		case s.SetID != first.SetID:
+			return nil, fmt.Errorf("share %d belongs to set %s, not %s", s.Index, s.SetID, first.SetID)
+		case s.Threshold != first.Threshold || len(s.Data) != len(first.Data):
+			return nil, fmt.Errorf("share %d does not match the other shares", s.Index)
+		case s.Index < 1 || s.Index > MaxShares:
+			return nil, fmt.Errorf("invalid share index %d", s.Index)
+		case seen[s.Index]:
+			continue
+		}
+		seen[s.Index] = true
+		unique = append(unique, s)
+	}
+	if len(unique) < first.Threshold {
+		return nil, fmt.Errorf("need %d distinct shares, got %d", first.Threshold, len(unique))
+	}
+	unique = unique[:first.Threshold]
+
+	// Lagrange interpolation at x = 0
+	secret := make([]byte, len(first.Data))
+	for i, si := range unique {
+		xi := byte(si.Index)
+		basis := byte(1)
+		for j, sj := range unique {
+			if i != j {
+				xj := byte(sj.Index)
+				basis = gfMul(basis, gfDiv(xj, xj^xi))
+			}
+		}
+		for b := range secret {
+			secret[b] ^= gfMul(si.Data[b], basis)
+		}
+	}
+	return secret, nil
+}
+
+// Encode renders the share as a single printable line:
+// GE1-<set>-<threshold>-<index>-<base32 data>-<checksum>
+func (s Share) Encode() string {
+	body := fmt.Sprintf("%s-%s-%d-%d-%s", shareVersion, s.SetID, s.Threshold, s.Index, shareEncoding.EncodeToString(s.Data))
+	return body + "-" + shareChecksum(body)
+}
+
+// ParseShare decodes a share produced by Share.Encode. Case and surrounding
+// whitespace are ignored; a wrong checksum means the share was mistyped.
+func ParseShare(text string) (Share, error) {
+	text = strings.ToUpper(strings.Join(strings.Fields(text), ""))
+	parts := strings.Split(text, "-")
+	if len(parts) != 6 || parts[0] != shareVersion {
+		return Share{}, errors.New("not a goingenv share")
+	}
+
+	body := strings.Join(parts[:5], "-")
+	if parts[5] != shareChecksum(body) {
+		return Share{}, errors.New("share checksum mismatch (mistyped?)")
+	}
+
+	threshold, err := strconv.Atoi(parts[2])
+	if err != nil || threshold < MinShareThreshold {
+		return Share{}, fmt.Errorf("invalid share threshold %q", parts[2])
+	}
+	index, err := strconv.Atoi(parts[3])
+	if err != nil || index < 1 || index > MaxShares {
+		return Share{}, fmt.Errorf("invalid share index %q", parts[3])
+	}
+	data, err := shareEncoding.DecodeString(parts[4])
+	if err != nil || len(data) == 0 {
+		return Share{}, errors.New("invalid share data")
+	}
+
+	return Share{SetID: parts[1], Threshold: threshold, Index: index, Data: data}, nil
+}
+
+// shareChecksum is the first two bytes of SHA-256 over the share text
+func shareChecksum(body string) string {
+	sum := sha256.Sum256([]byte(body))
+	return strings.ToUpper(hex.EncodeToString(sum[:2]))
+}
+
+// GF(2^8) arithmetic with the AES polynomial x^8 + x^4 + x^3 + x + 1
+
+var gfExp, gfLog = gfTables()
+
+// gfTables builds exponent and logarithm tables for generator 3
+func gfTables() (exp [510]byte, log [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		exp[i+255] = x
+		log[x] = byte(i)
+		// multiply by 3: x*2 ^ x
+		hi := x & 0x80
+		x2 := x << 1
+		if hi != 0 {
+			x2 ^= 0x1b
+		}
+		x = x2 ^ x
+	}
+	return exp, log
+}
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// gfEval evaluates the polynomial with the given coefficients at x
+func gfEval(coeffs []byte, x byte) byte {
+	var y byte
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coeffs[i]
+	}
+	return y
+}
@@ -0,0 +1,136 @@
+package crypto
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSplitCombineSecret(t *testing.T) {
+	secret := []byte("correct horse battery staple")
+
+	tests := []struct {
+		name      string
+		n         int
+		threshold int
+		use       []int // share indexes (1-based) passed to CombineShares
+		wantErr   bool
+	}{
+		{"3 of 5, first three", 5, 3, []int{1, 2, 3}, false},
+		{"3 of 5, last three", 5, 3, []int{3, 4, 5}, false},
+		{"3 of 5, all five", 5, 3, []int{5, 1, 4, 2, 3}, false},
+		{"3 of 5, duplicates ignored", 5, 3, []int{2, 2, 4, 5}, false},
+		{"2 of 2", 2, 2, []int{2, 1}, false},
+		{"3 of 5, too few", 5, 3, []int{1, 4}, true},
+		{"3 of 5, duplicates do not count", 5, 3, []int{1, 1, 4}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := SplitSecret(secret, tt.n, tt.threshold)
+			if err != nil {
+				t.Fatalf("SplitSecret() error = %v", err)
+			}
+			if len(shares) != tt.n {
+				t.Fatalf("SplitSecret() returned %d shares, want %d", len(shares), tt.n)
+			}
+
+			var subset []Share
+			for _, i := range tt.use {
+				subset = append(subset, shares[i-1])
+			}
+			got, err := CombineShares(subset)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CombineShares() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !bytes.Equal(got, secret) {
+				t.Errorf("CombineShares() = %q, want %q", got, secret)
+			}
+		})
+	}
+}
+
+func TestSplitSecretValidation(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    []byte
+		n         int
+		threshold int
+	}{
+		{"empty secret", nil, 5, 3},
+		{"threshold of one", []byte("x"), 5, 1},
+		{"fewer shares than threshold", []byte("x"), 2, 3},
+		{"too many shares", []byte("x"), 256, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := SplitSecret(tt.secret, tt.n, tt.threshold); err == nil {
+				t.Error("SplitSecret() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestCombineSharesFromDifferentSets(t *testing.T) {
+	a, err := SplitSecret([]byte("first secret"), 3, 2)
+	if err != nil {
+		t.Fatalf("SplitSecret() error = %v", err)
+	}
+	b, err := SplitSecret([]byte("other secret"), 3, 2)
+	if err != nil {
+		t.Fatalf("SplitSecret() error = %v", err)
+	}
+
+	if _, err := CombineShares([]Share{a[0], b[1]}); err == nil {
+		t.Error("CombineShares() accepted shares from different sets")
+	}
+}
+
+func TestShareEncoding(t *testing.T) {
+	shares, err := SplitSecret([]byte("correct horse battery staple"), 3, 2)
+	if err != nil {
+		t.Fatalf("SplitSecret() error = %v", err)
+	}
+
+	encoded := shares[1].Encode()
+	if !strings.HasPrefix(encoded, "GE1-") {
+		t.Errorf("Encode() = %q, want GE1- prefix", encoded)
+	}
+
+	// Case and whitespace don't matter when retyping a share
+	parsed, err := ParseShare("  " + strings.ToLower(encoded[:20]) + "\n " + encoded[20:] + " ")
+	if err != nil {
+		t.Fatalf("ParseShare() error = %v", err)
+	}
+	if parsed.SetID != shares[1].SetID || parsed.Index != 2 || parsed.Threshold != 2 || !bytes.Equal(parsed.Data, shares[1].Data) {
+		t.Errorf("ParseShare() = %+v, want %+v", parsed, shares[1])
+	}
+
+	// Change one data character
+	parts := strings.Split(encoded, "-")
+	data := []byte(parts[4])
+	if data[0] == 'A' {
+		data[0] = 'B'
+	} else {
+		data[0] = 'A'
+	}
+	parts[4] = string(data)
+	if _, err := ParseShare(strings.Join(parts, "-")); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("ParseShare() of a mistyped share error = %v, want checksum mismatch", err)
+	}
+
+	if _, err := ParseShare("not a share"); err == nil {
+		t.Error("ParseShare() accepted garbage")
+	}
+}
+
+func TestGFArithmetic(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			if got := gfDiv(gfMul(byte(a), byte(b)), byte(b)); got != byte(a) {
+				t.Fatalf("gfDiv(gfMul(%d, %d), %d) = %d, want %d", a, b, b, got, a)
+			}
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// Package dotenv reads KEY=VALUE assignments from env files. It follows the
+// common dotenv conventions (comments, blank lines, an optional "export "
+// prefix, single or double quoted values) closely enough to tell which keys
+// a file sets and whether their values changed; it does not expand
+// variables or handle values spanning several lines.
+package dotenv
+
+import (
+	"bufio"
+	"bytes"
+	"regexp"
+	"strings"
+)
+
+// validKey matches the variable names env loaders accept
+var validKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// Entry is one assignment in an env file
+type Entry struct {
+	Key   string
+	Value string
+	Line  int // 1-based line number
+}
+
+// Parse returns the assignments of an env file in file order. A key set
+// more than once appears once per assignment. Lines that are not
+// assignments are skipped.
+func Parse(content []byte) []Entry {
+	var entries []Entry
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(content)+1)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+		if entry, ok := parseLine(scanner.Text()); ok {
+			entry.Line = line
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// Map returns the value of each key, the last assignment winning as it
+// does when the file is loaded
+func Map(content []byte) map[string]string {
+	values := make(map[string]string)
+	for _, entry := range Parse(content) {
+		values[entry.Key] = entry.Value
+	}
+	return values
+}
+
+// parseLine parses one line, reporting false for blank lines, comments and
+// anything else that is not an assignment
+func parseLine(text string) (Entry, bool) {
+	text = strings.TrimSpace(text)
+	if text == "" || strings.HasPrefix(text, "#") {
+		return Entry{}, false
+	}
+	text = strings.TrimPrefix(text, "export ")
+
+	key, value, found := strings.Cut(text, "=")
+	key = strings.TrimSpace(key)
+	if !found || !validKey.MatchString(key) {
+		return Entry{}, false
+	}
+	return Entry{Key: key, Value: parseValue(strings.TrimSpace(value))}, true
+}
+
+// parseValue unquotes a quoted value, or strips a trailing " #" comment
+// from an unquoted one
+func parseValue(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') {
+		if end := closingQuote(value); end > 0 {
+			inner := value[1:end]
+			if value[0] == '"' {
+				inner = strings.NewReplacer(`\n`, "\n", `\"`, `"`, `\\`, `\`).Replace(inner)
+			}
+			return inner
+		}
+	}
+	if i := strings.Index(value, " #"); i >= 0 {
+		value = strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+// closingQuote returns the index of the quote closing value[0], skipping
+// backslash escapes inside double quotes, or -1
+func closingQuote(value string) int {
+	quote := value[0]
+	for i := 1; i < len(value); i++ {
+		switch {
+		case quote == '"' && value[i] == '\\':
+			i++
+		case value[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,42 @@
+package dotenv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	content := []byte(`# Database
+DATABASE_URL=postgres://localhost/app
+export API_KEY = abc123
+
+EMPTY=
+QUOTED="hello # not a comment"
+SINGLE='it''s'
+ESCAPED="say \"hi\"\nbye"
+INLINE=value # comment
+not an assignment
+1BAD=x
+DATABASE_URL=postgres://db/app
+`)
+
+	want := []Entry{
+		{Key: "DATABASE_URL", Value: "postgres://localhost/app", Line: 2},
+		{Key: "API_KEY", Value: "abc123", Line: 3},
+		{Key: "EMPTY", Value: "", Line: 5},
+		{Key: "QUOTED", Value: "hello # not a comment", Line: 6},
+		{Key: "SINGLE", Value: "it", Line: 7},
+		{Key: "ESCAPED", Value: "say \"hi\"\nbye", Line: 8},
+		{Key: "INLINE", Value: "value", Line: 9},
+		{Key: "DATABASE_URL", Value: "postgres://db/app", Line: 12},
+	}
+
+	if got := Parse(content); !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+
+	values := Map(content)
+	if values["DATABASE_URL"] != "postgres://db/app" || len(values) != 7 {
+		t.Errorf("Map() = %v, want the last DATABASE_URL and 7 keys", values)
+	}
+}
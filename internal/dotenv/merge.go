@@ -0,0 +1,202 @@
+package dotenv
+
+import (
+	"slices"
+	"strings"
+)
+
+// Conflict markers written around keys both sides changed differently
+const (
+	MarkerOurs   = "<<<<<<< ours"
+	MarkerSep    = "======="
+	MarkerTheirs = ">>>>>>> theirs"
+)
+
+// MergeResult is the outcome of a three-way merge of an env file
+type MergeResult struct {
+	Content   []byte   // merged file; conflicting keys keep our assignment
+	Marked    []byte   // merged file with conflict markers around conflicting keys
+	Conflicts []string // keys both sides changed differently
+}
+
+// assignment is the effective value of a key in one version of a file
+type assignment struct {
+	set   bool
+	value string
+	raw   string // the line that sets the value
+}
+
+// version is one side of a merge, indexed by key
+type version struct {
+	lines   []string
+	keys    map[string]assignment
+	last    map[string]int // key -> index in lines of its effective assignment
+	order   []string       // keys in order of first assignment
+	layout  []string       // lines that are not assignments
+	newline bool           // content ends with a newline
+}
+
+// Merge3 merges the changes ours and theirs made to base key by key: a key
+// changed on one side takes that side's assignment, a key changed the same
+// way on both sides is taken once, and a key changed differently on both
+// sides is a conflict. Comments and blank lines come from the side that
+// changed them. Merge3 reports false when both sides changed comments or
+// blank lines differently, which it cannot merge; callers then treat the
+// file as a whole.
+func Merge3(base, ours, theirs []byte) (*MergeResult, bool) {
+	b, o, t := parseVersion(base), parseVersion(ours), parseVersion(theirs)
+
+	layout := o
+	switch {
+	case slices.Equal(o.layout, t.layout), slices.Equal(t.layout, b.layout):
+	case slices.Equal(o.layout, b.layout):
+		layout = t
+	default:
+		return nil, false
+	}
+
+	keys := unionKeys(layout, o, t)
+	merged := make(map[string]assignment)
+	marked := make(map[string]string)
+	var conflicts []string
+	for _, key := range keys {
+		bv, ov, tv := b.keys[key], o.keys[key], t.keys[key]
+		switch {
+		case ov.same(tv), tv.same(bv):
+			merged[key] = ov
+		case ov.same(bv):
+			merged[key] = tv
+		default:
+			merged[key] = ov
+			marked[key] = conflictBlock(ov, tv)
+			conflicts = append(conflicts, key)
+		}
+	}
+
+	res := &MergeResult{Conflicts: conflicts}
+	res.Content = layout.render(keys, merged, nil)
+	res.Marked = res.Content
+	if len(conflicts) > 0 {
+		res.Marked = layout.render(keys, merged, marked)
+	}
+	return res, true
+}
+
+// same reports whether two assignments give a key the same value
+func (a assignment) same(other assignment) bool {
+	return a.set == other.set && (!a.set || a.value == other.value)
+}
+
+// parseVersion indexes the assignments of content
+func parseVersion(content []byte) *version {
+	text := string(content)
+	v := &version{
+		keys:    make(map[string]assignment),
+		last:    make(map[string]int),
+		newline: text == "" || strings.HasSuffix(text, "\n"),
+	}
+	if text != "" {
+		v.lines = strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	}
+
+	for i, line := range v.lines {
+		entry, ok := parseLine(line)
+		if !ok {
+			v.layout = append(v.layout, line)
+			continue
+		}
+		if _, seen := v.keys[entry.Key]; !seen {
+			v.order = append(v.order, entry.Key)
+		}
+		v.keys[entry.Key] = assignment{set: true, value: entry.Value, raw: line}
+		v.last[entry.Key] = i
+	}
+	return v
+}
+
+// unionKeys returns the keys of all versions, those of layout first
+func unionKeys(layout *version, others ...*version) []string {
+	keys := append([]string(nil), layout.order...)
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		seen[key] = true
+	}
+	for _, other := range others {
+		for _, key := range other.order {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// render writes v's lines with the merged assignments: each key's
+// effective assignment line is replaced (or dropped when the key is unset),
+// and keys v lacks are appended in the order of keys. Keys in marked are
+// written as their conflict block instead.
+func (v *version) render(keys []string, merged map[string]assignment, marked map[string]string) []byte {
+	var out []string
+	emit := func(key string) {
+		if block, ok := marked[key]; ok {
+			out = append(out, block)
+		} else if a := merged[key]; a.set {
+			out = append(out, a.raw)
+		}
+	}
+
+	for i, line := range v.lines {
+		entry, ok := parseLine(line)
+		switch {
+		case !ok:
+			out = append(out, line)
+		case v.last[entry.Key] == i:
+			if a := merged[entry.Key]; a.set && a.value == v.keys[entry.Key].value && marked[entry.Key] == "" {
+				out = append(out, line) // unchanged, keep this side's formatting
+			} else {
+				emit(entry.Key)
+			}
+		case merged[entry.Key].set:
+			out = append(out, line) // an earlier assignment, overridden as before
+		}
+	}
+	for _, key := range keys {
+		if _, has := v.keys[key]; !has {
+			emit(key)
+		}
+	}
+
+	if len(out) == 0 {
+		return []byte{}
+	}
+	text := strings.Join(out, "\n")
+	if v.newline {
+		text += "\n"
+	}
+	return []byte(text)
+}
+
+// conflictBlock writes both sides of a conflicting key between markers
+func conflictBlock(ours, theirs assignment) string {
+	lines := []string{MarkerOurs}
+	if ours.set {
+		lines = append(lines, ours.raw)
+	}
+	lines = append(lines, MarkerSep)
+	if theirs.set {
+		lines = append(lines, theirs.raw)
+	}
+	return strings.Join(append(lines, MarkerTheirs), "\n")
+}
+
+// HasMarkers reports whether content still has conflict markers
+func HasMarkers(content []byte) bool {
+	for _, line := range strings.Split(string(content), "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if line == MarkerOurs || line == MarkerSep || line == MarkerTheirs {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,101 @@
+package dotenv
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMerge3(t *testing.T) {
+	base := "# App\nHOST=localhost\nPORT=8080\nDEBUG=false\n"
+
+	tests := []struct {
+		name          string
+		ours          string
+		theirs        string
+		wantOK        bool
+		wantContent   string
+		wantMarked    string
+		wantConflicts []string
+	}{
+		{
+			name:        "different keys changed",
+			ours:        "# App\nHOST=example.com\nPORT=8080\nDEBUG=false\n",
+			theirs:      "# App\nHOST=localhost\nPORT=9090\nDEBUG=false\n",
+			wantOK:      true,
+			wantContent: "# App\nHOST=example.com\nPORT=9090\nDEBUG=false\n",
+		},
+		{
+			name:        "keys added and removed on both sides",
+			ours:        "# App\nHOST=localhost\nPORT=8080\nDEBUG=false\nOURS=1\n",
+			theirs:      "# App\nHOST=localhost\nPORT=8080\nTHEIRS=2\n",
+			wantOK:      true,
+			wantContent: "# App\nHOST=localhost\nPORT=8080\nOURS=1\nTHEIRS=2\n",
+		},
+		{
+			name:        "same change on both sides",
+			ours:        "# App\nHOST=localhost\nPORT=1\nDEBUG=false\n",
+			theirs:      "# App\nHOST=localhost\nPORT=\"1\"\nDEBUG=false\n",
+			wantOK:      true,
+			wantContent: "# App\nHOST=localhost\nPORT=1\nDEBUG=false\n",
+		},
+		{
+			name:          "conflicting change",
+			ours:          "# App\nHOST=localhost\nPORT=1\nDEBUG=false\n",
+			theirs:        "# App\nHOST=localhost\nPORT=2\nDEBUG=true\n",
+			wantOK:        true,
+			wantContent:   "# App\nHOST=localhost\nPORT=1\nDEBUG=true\n",
+			wantMarked:    "# App\nHOST=localhost\n<<<<<<< ours\nPORT=1\n=======\nPORT=2\n>>>>>>> theirs\nDEBUG=true\n",
+			wantConflicts: []string{"PORT"},
+		},
+		{
+			name:          "delete against modify",
+			ours:          "# App\nHOST=localhost\nDEBUG=false\n",
+			theirs:        "# App\nHOST=localhost\nPORT=2\nDEBUG=false\n",
+			wantOK:        true,
+			wantContent:   "# App\nHOST=localhost\nDEBUG=false\n",
+			wantMarked:    "# App\nHOST=localhost\nDEBUG=false\n<<<<<<< ours\n=======\nPORT=2\n>>>>>>> theirs\n",
+			wantConflicts: []string{"PORT"},
+		},
+		{
+			name:        "comment changed on one side",
+			ours:        "# App\nHOST=localhost\nPORT=1\nDEBUG=false\n",
+			theirs:      "# Application\nHOST=localhost\nPORT=8080\nDEBUG=false\n",
+			wantOK:      true,
+			wantContent: "# Application\nHOST=localhost\nPORT=1\nDEBUG=false\n",
+		},
+		{
+			name:   "comments changed on both sides",
+			ours:   "# Ours\nHOST=localhost\nPORT=8080\nDEBUG=false\n",
+			theirs: "# Theirs\nHOST=localhost\nPORT=8080\nDEBUG=false\n",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, ok := Merge3([]byte(base), []byte(tt.ours), []byte(tt.theirs))
+			if ok != tt.wantOK {
+				t.Fatalf("Merge3() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if !ok {
+				return
+			}
+			if string(res.Content) != tt.wantContent {
+				t.Errorf("Merge3() content = %q, want %q", res.Content, tt.wantContent)
+			}
+			wantMarked := tt.wantMarked
+			if wantMarked == "" {
+				wantMarked = tt.wantContent
+			}
+			if string(res.Marked) != wantMarked {
+				t.Errorf("Merge3() marked = %q, want %q", res.Marked, wantMarked)
+			}
+			if !reflect.DeepEqual(res.Conflicts, tt.wantConflicts) {
+				t.Errorf("Merge3() conflicts = %v, want %v", res.Conflicts, tt.wantConflicts)
+			}
+			if HasMarkers(res.Content) || HasMarkers(res.Marked) != (len(tt.wantConflicts) > 0) {
+				t.Errorf("HasMarkers() disagrees with conflicts %v", tt.wantConflicts)
+			}
+		})
+	}
+}
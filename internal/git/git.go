@@ -0,0 +1,69 @@
+// Package git connects goingenv archives to git: the diff driver that shows
+// redacted archive contents, and the attribute and config entries that
+// register it.
+package git
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"goingenv/pkg/utils"
+)
+
+// DriverName names the goingenv drivers in .gitattributes and git config
+const DriverName = "goingenv"
+
+// AttributesFile is the git attributes file name
+const AttributesFile = ".gitattributes"
+
+// Run runs git in dir and returns its trimmed standard output
+func Run(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("git %s: %s", strings.Join(args, " "), msg)
+		}
+		return "", fmt.Errorf("git %s: %w", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// TopLevel returns the root of the work tree containing dir
+func TopLevel(dir string) (string, error) {
+	root, err := Run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository: %w", err)
+	}
+	return root, nil
+}
+
+// EnsureLine appends line to the file at path unless the file already has
+// it, creating the file if needed. It reports whether the file changed.
+func EnsureLine(path, line string) (bool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	for _, existing := range strings.Split(string(data), "\n") {
+		if strings.TrimSpace(existing) == line {
+			return false, nil
+		}
+	}
+
+	if len(data) > 0 && !bytes.HasSuffix(data, []byte("\n")) {
+		data = append(data, '\n')
+	}
+	data = append(data, line+"\n"...)
+	if writeErr := utils.WriteFileAtomic(path, data, 0o644); writeErr != nil {
+		return false, fmt.Errorf("failed to write %s: %w", path, writeErr)
+	}
+	return true, nil
+}
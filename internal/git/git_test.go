@@ -0,0 +1,177 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"goingenv/pkg/types"
+)
+
+func TestEnsureLine(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	path := filepath.Join(tmpDir, AttributesFile)
+	if writeErr := os.WriteFile(path, []byte("*.png binary"), 0o644); writeErr != nil {
+		t.Fatalf("Failed to write %s: %v", path, writeErr)
+	}
+
+	tests := []struct {
+		name        string
+		line        string
+		wantChanged bool
+	}{
+		{name: "appends missing line", line: ".goingenv/*.enc diff=goingenv", wantChanged: true},
+		{name: "keeps existing line", line: ".goingenv/*.enc diff=goingenv", wantChanged: false},
+		{name: "matches existing line ignoring spaces", line: "*.png binary", wantChanged: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed, err := EnsureLine(path, tt.line)
+			if err != nil {
+				t.Fatalf("EnsureLine() error = %v", err)
+			}
+			if changed != tt.wantChanged {
+				t.Errorf("EnsureLine() = %v, want %v", changed, tt.wantChanged)
+			}
+		})
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Failed to read %s: %v", path, err)
+	}
+	if want := "*.png binary\n.goingenv/*.enc diff=goingenv\n"; string(data) != want {
+		t.Errorf("file = %q, want %q", data, want)
+	}
+}
+
+func TestTextconv(t *testing.T) {
+	archive := &types.Archive{Files: []types.EnvFile{
+		{RelativePath: "service/.env"},
+		{RelativePath: ".env"},
+		{RelativePath: ".env.link", LinkTarget: ".env"},
+		{RelativePath: "notes.env"},
+	}}
+	contents := map[string][]byte{
+		".env":         []byte("SECRET=hunter2\nAPI_KEY=abc\n"),
+		"service/.env": []byte("SECRET=hunter2\n"),
+		"notes.env":    []byte("just some text\n"),
+	}
+
+	listing := func(password string, contents map[string][]byte) string {
+		var buf bytes.Buffer
+		if err := Textconv(&buf, archive, contents, password); err != nil {
+			t.Fatalf("Textconv() error = %v", err)
+		}
+		return buf.String()
+	}
+
+	got := listing("pass", contents)
+	lines := strings.Split(strings.TrimSuffix(got, "\n"), "\n")
+	wantShape := []string{
+		"goingenv archive: 4 files",
+		".env",
+		"  API_KEY ",
+		"  SECRET ",
+		".env.link -> .env",
+		"notes.env (no keys, content ",
+		"service/.env",
+		"  SECRET ",
+	}
+	if len(lines) != len(wantShape) {
+		t.Fatalf("Textconv() = %q, want %d lines", got, len(wantShape))
+	}
+	for i, prefix := range wantShape {
+		if !strings.HasPrefix(lines[i], prefix) {
+			t.Errorf("line %d = %q, want prefix %q", i, lines[i], prefix)
+		}
+	}
+
+	if strings.Contains(got, "hunter2") || strings.Contains(got, "abc") {
+		t.Errorf("Textconv() leaks a value: %q", got)
+	}
+	if lines[3] == lines[7] {
+		t.Errorf("equal values in different files share a fingerprint: %q", lines[3])
+	}
+	if again := listing("pass", contents); again != got {
+		t.Errorf("Textconv() is not stable: %q then %q", got, again)
+	}
+	if other := listing("other", contents); other == got {
+		t.Error("Textconv() fingerprints do not depend on the password")
+	}
+
+	changed := map[string][]byte{".env": []byte("API_KEY=abc\nSECRET=changed\n"), "service/.env": contents["service/.env"], "notes.env": contents["notes.env"]}
+	changedLines := strings.Split(listing("pass", changed), "\n")
+	if changedLines[2] != lines[2] || changedLines[3] == lines[3] {
+		t.Errorf("changing one value should change exactly its line: %q vs %q", changedLines, lines)
+	}
+}
+
+func TestGuard(t *testing.T) {
+	isEnvFile := func(path string) bool {
+		return strings.HasPrefix(filepath.Base(path), ".env")
+	}
+	paths := []string{"main.go", ".env", "svc/.env.local", ".goingenv/backup.enc", ".goingenv/backup.enc.sig", ".env.enc", "docs/env.md"}
+
+	got := Guard(paths, isEnvFile)
+	want := []string{".env", "svc/.env.local"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Guard() = %v, want %v", got, want)
+	}
+
+	if entries := IgnoreEntries([]string{".env", "a/.env", "b/.env.local"}); strings.Join(entries, ",") != ".env,.env.local" {
+		t.Errorf("IgnoreEntries() = %v, want [.env .env.local]", entries)
+	}
+}
+
+func TestInstallHook(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	hooksDir := filepath.Join(tmpDir, "hooks")
+	path, err := InstallHook(hooksDir, false)
+	if err != nil {
+		t.Fatalf("InstallHook() error = %v", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("hook not written: %v", err)
+	}
+	if info.Mode().Perm()&0o111 == 0 {
+		t.Errorf("hook mode = %v, want executable", info.Mode().Perm())
+	}
+
+	// Reinstalling over our own hook is fine
+	if _, err := InstallHook(hooksDir, false); err != nil {
+		t.Errorf("InstallHook() over own hook error = %v", err)
+	}
+
+	foreign := []byte("#!/bin/sh\nexec lint\n")
+	if err := os.WriteFile(path, foreign, 0o755); err != nil { //nolint:gosec // test hook
+		t.Fatalf("Failed to write hook: %v", err)
+	}
+	if _, err := InstallHook(hooksDir, false); !errors.Is(err, types.ErrConflict) {
+		t.Errorf("InstallHook() over foreign hook error = %v, want ErrConflict", err)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Equal(data, foreign) {
+		t.Error("InstallHook() replaced a foreign hook without force")
+	}
+	if _, err := InstallHook(hooksDir, true); err != nil {
+		t.Errorf("InstallHook() with force error = %v", err)
+	}
+	if data, _ := os.ReadFile(path); !bytes.Contains(data, []byte(HookMarker)) {
+		t.Error("InstallHook() with force did not write the goingenv hook")
+	}
+}
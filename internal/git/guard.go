@@ -0,0 +1,143 @@
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"goingenv/pkg/types"
+)
+
+// HookMarker identifies pre-commit hooks written by InstallHook
+const HookMarker = "# Installed by goingenv"
+
+// hookScript is the pre-commit hook InstallHook writes
+const hookScript = "#!/bin/sh\n" + HookMarker + ": refuse commits that stage plaintext env files.\n" +
+	"# Bypass once with 'git commit --no-verify'.\nexec goingenv guard\n"
+
+// encryptedSuffixes are the goingenv files that are safe to commit even when
+// their names look like env files
+var encryptedSuffixes = []string{".enc", ".enc.sig"}
+
+// Guard returns the paths a commit must not contain: those isEnvFile takes
+// for env files, apart from goingenv archives and their signatures
+func Guard(paths []string, isEnvFile func(path string) bool) []string {
+	var blocked []string
+	for _, path := range paths {
+		if IsEncrypted(path) || !isEnvFile(path) {
+			continue
+		}
+		blocked = append(blocked, path)
+	}
+	return blocked
+}
+
+// IsEncrypted reports whether path names a goingenv archive or signature
+func IsEncrypted(path string) bool {
+	for _, suffix := range encryptedSuffixes {
+		if strings.HasSuffix(path, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// StagedPaths returns the paths added, copied, modified or renamed in the
+// index of the repository containing dir
+func StagedPaths(dir string) ([]string, error) {
+	out, err := Run(dir, "diff", "--cached", "--name-only", "-z", "--diff-filter=ACMR")
+	if err != nil {
+		return nil, err
+	}
+	return splitPaths(out, "\x00"), nil
+}
+
+// IgnoredPaths returns the paths, relative to dir, that git ignores
+func IgnoredPaths(dir string, paths []string) ([]string, error) {
+	if len(paths) == 0 {
+		return nil, nil
+	}
+
+	cmd := exec.Command("git", "check-ignore", "-z", "--stdin")
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(strings.Join(paths, "\x00") + "\x00")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+
+	// check-ignore exits 1 when no path is ignored
+	var exitErr *exec.ExitError
+	if err := cmd.Run(); err != nil && !(errors.As(err, &exitErr) && exitErr.ExitCode() == 1) {
+		return nil, fmt.Errorf("git check-ignore: %s", strings.TrimSpace(stderr.String()))
+	}
+	return splitPaths(stdout.String(), "\x00"), nil
+}
+
+// splitPaths splits git's path list output, dropping empty entries
+func splitPaths(out, sep string) []string {
+	var paths []string
+	for _, path := range strings.Split(out, sep) {
+		if path != "" {
+			paths = append(paths, path)
+		}
+	}
+	return paths
+}
+
+// HooksDir returns the hooks directory of the repository containing dir,
+// honoring core.hooksPath
+func HooksDir(dir string) (string, error) {
+	hooks, err := Run(dir, "rev-parse", "--git-path", "hooks")
+	if err != nil {
+		return "", fmt.Errorf("not in a git repository: %w", err)
+	}
+	if !filepath.IsAbs(hooks) {
+		hooks = filepath.Join(dir, hooks)
+	}
+	return hooks, nil
+}
+
+// InstallHook writes the goingenv pre-commit hook into hooksDir and returns
+// its path. A pre-commit hook goingenv did not write is only replaced when
+// force is set.
+func InstallHook(hooksDir string, force bool) (string, error) {
+	path := filepath.Join(hooksDir, "pre-commit")
+
+	existing, err := os.ReadFile(path)
+	switch {
+	case err == nil && !force && !bytes.Contains(existing, []byte(HookMarker)):
+		return path, fmt.Errorf("%w: %s is not a goingenv hook; use --force to replace it", types.ErrConflict, path)
+	case err != nil && !os.IsNotExist(err):
+		return path, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if mkdirErr := os.MkdirAll(hooksDir, 0o755); mkdirErr != nil {
+		return path, fmt.Errorf("failed to create %s: %w", hooksDir, mkdirErr)
+	}
+	if writeErr := os.WriteFile(path, []byte(hookScript), 0o755); writeErr != nil { //nolint:gosec // G306: hooks must be executable
+		return path, fmt.Errorf("failed to write %s: %w", path, writeErr)
+	}
+	// WriteFile keeps the mode of an existing file
+	if chmodErr := os.Chmod(path, 0o755); chmodErr != nil { //nolint:gosec // G302: hooks must be executable
+		return path, fmt.Errorf("failed to make %s executable: %w", path, chmodErr)
+	}
+	return path, nil
+}
+
+// IgnoreEntries returns the .gitignore lines that ignore env files at
+// paths by name, in first-seen order without duplicates
+func IgnoreEntries(paths []string) []string {
+	seen := make(map[string]bool)
+	var entries []string
+	for _, path := range paths {
+		name := filepath.Base(path)
+		if !seen[name] {
+			seen[name] = true
+			entries = append(entries, name)
+		}
+	}
+	return entries
+}
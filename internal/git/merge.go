@@ -0,0 +1,256 @@
+package git
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"goingenv/internal/dotenv"
+	"goingenv/pkg/types"
+)
+
+// ConflictsDir is the directory, next to an archive, holding the plaintext
+// side files of unresolved merges. It must never be committed.
+const ConflictsDir = "conflicts"
+
+// Version is one side of an archive merge
+type Version struct {
+	Files    map[string]types.EnvFile
+	Contents map[string][]byte
+}
+
+// NewVersion indexes an archive read with Archiver.Read. A nil archive is
+// an empty version, as for a base that doesn't exist.
+func NewVersion(archive *types.Archive, contents map[string][]byte) *Version {
+	v := &Version{Files: make(map[string]types.EnvFile), Contents: contents}
+	if archive != nil {
+		for _, file := range archive.Files {
+			v.Files[file.RelativePath] = file
+		}
+	}
+	return v
+}
+
+// MergeResult is what Merge changes in our version of an archive
+type MergeResult struct {
+	Put       []types.EnvFile   // entries to add or replace
+	Contents  map[string][]byte // content of the Put entries
+	Remove    []string          // entries to remove
+	Conflicts map[string][]byte // entries both sides changed differently, with conflict markers
+	KeptLinks []string          // conflicting symlinks, left as ours
+}
+
+// Merge merges the changes theirs made since base into ours. An entry
+// changed on one side only takes that side's version; an env file changed
+// on both sides is merged key by key (see dotenv.Merge3). Whatever cannot
+// be merged keeps our version and is reported in Conflicts, holding both
+// sides between conflict markers for the user to resolve.
+func Merge(base, ours, theirs *Version) *MergeResult {
+	res := &MergeResult{Contents: make(map[string][]byte), Conflicts: make(map[string][]byte)}
+
+	for _, path := range unionPaths(base, ours, theirs) {
+		b, o, t := base.entry(path), ours.entry(path), theirs.entry(path)
+		switch {
+		case o.same(t), t.same(b):
+		case o.same(b):
+			if t.present {
+				res.put(t.file, t.content)
+			} else {
+				res.Remove = append(res.Remove, path)
+			}
+		case o.file.LinkTarget != "" || t.file.LinkTarget != "":
+			res.KeptLinks = append(res.KeptLinks, path)
+		default:
+			res.mergeFile(path, b, o, t)
+		}
+	}
+	return res
+}
+
+// mergeFile merges an entry both sides changed
+func (res *MergeResult) mergeFile(path string, b, o, t entry) {
+	if o.present && t.present {
+		if merged, ok := dotenv.Merge3(b.content, o.content, t.content); ok {
+			if !bytes.Equal(merged.Content, o.content) {
+				res.put(o.file, merged.Content)
+			}
+			if len(merged.Conflicts) > 0 {
+				res.Conflicts[path] = merged.Marked
+			}
+			return
+		}
+	}
+
+	var marked bytes.Buffer
+	marked.WriteString(dotenv.MarkerOurs + "\n")
+	writeLines(&marked, o.content)
+	marked.WriteString(dotenv.MarkerSep + "\n")
+	writeLines(&marked, t.content)
+	marked.WriteString(dotenv.MarkerTheirs + "\n")
+	res.Conflicts[path] = marked.Bytes()
+}
+
+// put records a changed entry, with its size and checksum updated
+func (res *MergeResult) put(file types.EnvFile, content []byte) {
+	file.Size = int64(len(content))
+	file.Checksum = fmt.Sprintf("%x", sha256.Sum256(content))
+	file.ModTime = time.Now()
+	res.Put = append(res.Put, file)
+	res.Contents[file.RelativePath] = content
+}
+
+// writeLines writes content, ending it with a newline
+func writeLines(buf *bytes.Buffer, content []byte) {
+	buf.Write(content)
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+}
+
+// entry is one entry in one version
+type entry struct {
+	present bool
+	file    types.EnvFile
+	content []byte
+}
+
+// entry returns the entry at path
+func (v *Version) entry(path string) entry {
+	file, ok := v.Files[path]
+	return entry{present: ok, file: file, content: v.Contents[path]}
+}
+
+// same reports whether two versions of an entry are identical
+func (e entry) same(other entry) bool {
+	if e.present != other.present {
+		return false
+	}
+	return !e.present || (e.file.LinkTarget == other.file.LinkTarget && bytes.Equal(e.content, other.content))
+}
+
+// unionPaths returns every entry path in the versions, sorted
+func unionPaths(versions ...*Version) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	for _, v := range versions {
+		for path := range v.Files {
+			if !seen[path] {
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// ConflictDir returns the side file directory for the archive at
+// archivePath
+func ConflictDir(archivePath string) string {
+	return filepath.Join(filepath.Dir(archivePath), ConflictsDir, filepath.Base(archivePath))
+}
+
+// conflictList is the file, next to a side file directory, listing the
+// entries in conflict, so a side file the user deletes removes its entry
+func conflictList(dir string) string {
+	return dir + ".list"
+}
+
+// WriteConflicts replaces the side files in dir with conflicts. Side files
+// hold plaintext secrets and are only readable by the owner.
+func WriteConflicts(dir string, conflicts map[string][]byte) error {
+	if err := ClearConflicts(dir); err != nil {
+		return err
+	}
+
+	dir = filepath.Clean(dir)
+	paths := make([]string, 0, len(conflicts))
+	for path, content := range conflicts {
+		target := filepath.Join(dir, filepath.FromSlash(path))
+		if !strings.HasPrefix(target, dir+string(filepath.Separator)) {
+			return fmt.Errorf("invalid entry path %s", path)
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0o700); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(target), err)
+		}
+		if err := os.WriteFile(target, content, 0o600); err != nil {
+			return fmt.Errorf("failed to write %s: %w", target, err)
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	list := strings.Join(paths, "\n") + "\n"
+	if err := os.WriteFile(conflictList(dir), []byte(list), 0o600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", conflictList(dir), err)
+	}
+	return nil
+}
+
+// ClearConflicts removes the side files in dir and their list, and the
+// conflicts directory once no archive has any left
+func ClearConflicts(dir string) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to remove %s: %w", dir, err)
+	}
+	if err := os.Remove(conflictList(dir)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove %s: %w", conflictList(dir), err)
+	}
+	_ = os.Remove(filepath.Dir(dir)) //nolint:errcheck // fails while other archives have conflicts
+	return nil
+}
+
+// Resolution is the state of the side files in a conflict directory
+type Resolution struct {
+	Resolved   map[string]string // entry -> side file with its resolved content
+	Removed    []string          // entries whose side file was deleted
+	Unresolved []string          // entries whose side file still has conflict markers
+}
+
+// ReadConflicts reads the side files in dir
+func ReadConflicts(dir string) (*Resolution, error) {
+	list, err := os.ReadFile(conflictList(dir))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("no merge conflicts recorded in %s", dir)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", conflictList(dir), err)
+	}
+
+	res := &Resolution{Resolved: make(map[string]string)}
+	for _, path := range splitPaths(string(list), "\n") {
+		sideFile := filepath.Join(dir, filepath.FromSlash(path))
+		content, readErr := os.ReadFile(sideFile)
+		switch {
+		case os.IsNotExist(readErr):
+			res.Removed = append(res.Removed, path)
+		case readErr != nil:
+			return nil, fmt.Errorf("failed to read %s: %w", sideFile, readErr)
+		case dotenv.HasMarkers(content):
+			res.Unresolved = append(res.Unresolved, path)
+		default:
+			res.Resolved[path] = sideFile
+		}
+	}
+	return res, nil
+}
+
+// PendingConflicts returns the archives in dir that have side files
+// waiting for goingenv resolve
+func PendingConflicts(dir string) ([]string, error) {
+	lists, err := filepath.Glob(filepath.Join(dir, ConflictsDir, "*.list"))
+	if err != nil {
+		return nil, err
+	}
+	archives := make([]string, 0, len(lists))
+	for _, list := range lists {
+		name := strings.TrimSuffix(filepath.Base(list), ".list")
+		archives = append(archives, filepath.Join(dir, name))
+	}
+	return archives, nil
+}
@@ -0,0 +1,103 @@
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"goingenv/pkg/types"
+)
+
+func TestMerge(t *testing.T) {
+	version := func(files map[string]string) *Version {
+		archive := &types.Archive{}
+		contents := make(map[string][]byte)
+		for path, content := range files {
+			archive.Files = append(archive.Files, types.EnvFile{RelativePath: path})
+			contents[path] = []byte(content)
+		}
+		return NewVersion(archive, contents)
+	}
+
+	base := version(map[string]string{".env": "A=1\nB=1\n", ".env.test": "T=1\n", ".env.old": "O=1\n", "cert.pem": "base"})
+	ours := version(map[string]string{".env": "A=2\nB=1\n", ".env.test": "T=1\n", ".env.old": "O=1\n", "cert.pem": "ours", ".env.ours": "X=1\n"})
+	theirs := version(map[string]string{".env": "A=1\nB=2\n", ".env.test": "T=2\n", "cert.pem": "theirs", ".env.theirs": "Y=1\n"})
+
+	res := Merge(base, ours, theirs)
+
+	put := make(map[string]string)
+	for _, file := range res.Put {
+		put[file.RelativePath] = string(res.Contents[file.RelativePath])
+		if file.Size != int64(len(res.Contents[file.RelativePath])) || file.Checksum == "" {
+			t.Errorf("Merge() put %s with size %d and checksum %q", file.RelativePath, file.Size, file.Checksum)
+		}
+	}
+	wantPut := map[string]string{".env": "A=2\nB=2\n", ".env.test": "T=2\n", ".env.theirs": "Y=1\n"}
+	if !reflect.DeepEqual(put, wantPut) {
+		t.Errorf("Merge() put = %v, want %v", put, wantPut)
+	}
+	if !reflect.DeepEqual(res.Remove, []string{".env.old"}) {
+		t.Errorf("Merge() remove = %v, want [.env.old]", res.Remove)
+	}
+	wantConflict := "<<<<<<< ours\nours\n=======\ntheirs\n>>>>>>> theirs\n"
+	if len(res.Conflicts) != 1 || string(res.Conflicts["cert.pem"]) != wantConflict {
+		t.Errorf("Merge() conflicts = %q, want cert.pem with both sides", res.Conflicts)
+	}
+
+	// A missing base merges like two additions
+	res = Merge(NewVersion(nil, nil), version(map[string]string{".env": "A=1\n"}), version(map[string]string{".env": "B=1\n"}))
+	if len(res.Conflicts) != 0 || string(res.Contents[".env"]) != "A=1\nB=1\n" {
+		t.Errorf("Merge() without base = %q, conflicts %q; want A and B merged", res.Contents, res.Conflicts)
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	dir := ConflictDir(filepath.Join(tmpDir, "backup.enc"))
+	conflicts := map[string][]byte{
+		".env":         []byte("<<<<<<< ours\nA=1\n=======\nA=2\n>>>>>>> theirs\n"),
+		"api/.env":     []byte("<<<<<<< ours\nB=1\n=======\n>>>>>>> theirs\n"),
+		"api/.env.dev": []byte("<<<<<<< ours\n=======\nC=1\n>>>>>>> theirs\n"),
+	}
+	if err := WriteConflicts(dir, conflicts); err != nil {
+		t.Fatalf("WriteConflicts() error = %v", err)
+	}
+	pending, err := PendingConflicts(tmpDir)
+	if err != nil || len(pending) != 1 || pending[0] != filepath.Join(tmpDir, "backup.enc") {
+		t.Errorf("PendingConflicts() = %v, %v; want [backup.enc]", pending, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ".env"), []byte("A=2\n"), 0o600); err != nil {
+		t.Fatalf("Failed to resolve .env: %v", err)
+	}
+	if err := os.Remove(filepath.Join(dir, "api", ".env")); err != nil {
+		t.Fatalf("Failed to remove api/.env: %v", err)
+	}
+
+	res, err := ReadConflicts(dir)
+	if err != nil {
+		t.Fatalf("ReadConflicts() error = %v", err)
+	}
+	if len(res.Resolved) != 1 || res.Resolved[".env"] != filepath.Join(dir, ".env") {
+		t.Errorf("ReadConflicts() resolved = %v, want .env", res.Resolved)
+	}
+	if !reflect.DeepEqual(res.Removed, []string{"api/.env"}) || !reflect.DeepEqual(res.Unresolved, []string{"api/.env.dev"}) {
+		t.Errorf("ReadConflicts() removed = %v, unresolved = %v", res.Removed, res.Unresolved)
+	}
+
+	if err := ClearConflicts(dir); err != nil {
+		t.Fatalf("ClearConflicts() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, ConflictsDir)); !os.IsNotExist(err) {
+		t.Errorf("ClearConflicts() left %s behind", ConflictsDir)
+	}
+	if _, err := ReadConflicts(dir); err == nil {
+		t.Error("ReadConflicts() after ClearConflicts() error = nil, want error")
+	}
+}
@@ -0,0 +1,98 @@
+package git
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+
+	"goingenv/internal/crypto"
+	"goingenv/internal/dotenv"
+	"goingenv/pkg/types"
+)
+
+// fingerprintLen is the number of hex digits shown for a value
+const fingerprintLen = 8
+
+// fingerprintSalt separates the fingerprint key from archive keys, which
+// use a random salt per archive
+var fingerprintSalt = []byte("goingenv textconv fingerprint v2")
+
+// Textconv writes a redacted, stable listing of an archive for git diff:
+// each file with the keys it sets, sorted, and a short fingerprint of each
+// value in place of the value. Fingerprints are keyed by the archive
+// password, so a changed value shows up as a changed line without the value
+// being guessable from the diff; they change when the password does.
+func Textconv(w io.Writer, archive *types.Archive, contents map[string][]byte, password string) error {
+	mac := fingerprinter(password)
+
+	files := append([]types.EnvFile(nil), archive.Files...)
+	sort.Slice(files, func(i, j int) bool { return files[i].RelativePath < files[j].RelativePath })
+
+	if _, err := fmt.Fprintf(w, "goingenv archive: %d files\n", len(files)); err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := writeFile(w, &file, contents[file.RelativePath], mac); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// TextconvUnreadable writes the listing used when an archive cannot be
+// decrypted, so git diff still shows that the archive changed
+func TextconvUnreadable(w io.Writer, archiveHash string) error {
+	_, err := fmt.Fprintf(w, "goingenv archive: cannot decrypt\nsha256 %s\n", archiveHash)
+	return err
+}
+
+// writeFile writes one file's section of the listing
+func writeFile(w io.Writer, file *types.EnvFile, content []byte, mac func(...string) string) error {
+	if file.LinkTarget != "" {
+		_, err := fmt.Fprintf(w, "%s -> %s\n", file.RelativePath, file.LinkTarget)
+		return err
+	}
+
+	values := dotenv.Map(content)
+	if len(values) == 0 {
+		_, err := fmt.Fprintf(w, "%s (no keys, content %s)\n", file.RelativePath, mac(file.RelativePath, string(content)))
+		return err
+	}
+
+	if _, err := fmt.Fprintln(w, file.RelativePath); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if _, err := fmt.Fprintf(w, "  %s %s\n", key, mac(file.RelativePath, key, values[key])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fingerprinter returns a function computing short HMAC-SHA256
+// fingerprints under a key derived from password with the archive KDF, so
+// each password guess against a published diff costs as much as one
+// against the archive. The salt is fixed so fingerprints stay comparable
+// across revisions; a guess therefore works for every archive sharing the
+// password.
+func fingerprinter(password string) func(parts ...string) string {
+	key := crypto.DeriveKey(password, fingerprintSalt)
+
+	return func(parts ...string) string {
+		mac := hmac.New(sha256.New, key)
+		for _, part := range parts {
+			mac.Write([]byte(part))
+			mac.Write([]byte{0})
+		}
+		return hex.EncodeToString(mac.Sum(nil))[:fingerprintLen]
+	}
+}
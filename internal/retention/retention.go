@@ -0,0 +1,139 @@
+// Package retention decides which archives 'goingenv prune' keeps.
+//
+// Archives are ordered by the CreatedAt time recorded inside them, newest
+// first. An archive is kept when any rule selects it: keep_last keeps the N
+// newest, keep_daily and keep_weekly keep the newest archive of each of the
+// N most recent days and ISO weeks that have one, and older_than keeps every
+// archive younger than the given age. The newest archive is always kept.
+package retention
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"goingenv/pkg/types"
+)
+
+// Reasons an archive is kept
+const (
+	ReasonNewest = "newest"
+	ReasonLast   = "last"
+	ReasonDaily  = "daily"
+	ReasonWeekly = "weekly"
+	ReasonRecent = "recent"
+)
+
+// Item is an archive considered for pruning
+type Item struct {
+	Path      string
+	CreatedAt time.Time
+}
+
+// Decision records whether an archive is kept and which rules kept it
+type Decision struct {
+	Item
+	Keep    bool
+	Reasons []string
+}
+
+// IsEmpty reports whether a policy has no rules
+func IsEmpty(policy *types.Retention) bool {
+	return policy == nil || *policy == types.Retention{}
+}
+
+// Validate checks that a policy's counts are not negative and its age parses
+func Validate(policy *types.Retention) error {
+	if policy == nil {
+		return nil
+	}
+	if policy.KeepLast < 0 || policy.KeepDaily < 0 || policy.KeepWeekly < 0 {
+		return fmt.Errorf("keep counts must not be negative")
+	}
+	if policy.OlderThan != "" {
+		if _, err := ParseAge(policy.OlderThan); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ParseAge parses an age such as "90d", "12w" or any time.ParseDuration value
+func ParseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if n, err := strconv.Atoi(strings.TrimSuffix(s, suffix)); err == nil && strings.HasSuffix(s, suffix) {
+			if n <= 0 {
+				return 0, fmt.Errorf("invalid age %q: must be positive", s)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid age %q: use a number of days (90d), weeks (12w) or a duration (36h)", s)
+	}
+	return d, nil
+}
+
+// Apply decides which items a policy keeps. Decisions are returned newest
+// first. An empty policy keeps everything.
+func Apply(items []Item, policy *types.Retention, now time.Time) ([]Decision, error) {
+	if err := Validate(policy); err != nil {
+		return nil, err
+	}
+
+	decisions := make([]Decision, len(items))
+	for i, item := range items {
+		decisions[i] = Decision{Item: item}
+	}
+	sort.SliceStable(decisions, func(i, j int) bool {
+		return decisions[i].CreatedAt.After(decisions[j].CreatedAt)
+	})
+
+	if IsEmpty(policy) {
+		for i := range decisions {
+			decisions[i].Keep = true
+		}
+		return decisions, nil
+	}
+
+	var maxAge time.Duration
+	if policy.OlderThan != "" {
+		maxAge, _ = ParseAge(policy.OlderThan) //nolint:errcheck // checked by Validate
+	}
+
+	days := make(map[string]bool)
+	weeks := make(map[string]bool)
+	for i := range decisions {
+		d := &decisions[i]
+		created := d.CreatedAt.Local()
+
+		if i == 0 {
+			d.Reasons = append(d.Reasons, ReasonNewest)
+		}
+		if i < policy.KeepLast {
+			d.Reasons = append(d.Reasons, ReasonLast)
+		}
+		if day := created.Format("2006-01-02"); !days[day] && len(days) < policy.KeepDaily {
+			days[day] = true
+			d.Reasons = append(d.Reasons, ReasonDaily)
+		}
+		year, week := created.ISOWeek()
+		if key := fmt.Sprintf("%d-W%02d", year, week); !weeks[key] && len(weeks) < policy.KeepWeekly {
+			weeks[key] = true
+			d.Reasons = append(d.Reasons, ReasonWeekly)
+		}
+		if maxAge > 0 && now.Sub(d.CreatedAt) < maxAge {
+			d.Reasons = append(d.Reasons, ReasonRecent)
+		}
+
+		d.Keep = len(d.Reasons) > 0
+	}
+
+	return decisions, nil
+}
@@ -0,0 +1,100 @@
+package retention
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"goingenv/pkg/types"
+)
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{input: "90d", want: 90 * 24 * time.Hour},
+		{input: "2w", want: 14 * 24 * time.Hour},
+		{input: "36h", want: 36 * time.Hour},
+		{input: "0d", wantErr: true},
+		{input: "-1w", wantErr: true},
+		{input: "soon", wantErr: true},
+		{input: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			got, err := ParseAge(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseAge() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ParseAge() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApply(t *testing.T) {
+	now := time.Date(2024, 3, 20, 12, 0, 0, 0, time.Local)
+	at := func(days, hours int) time.Time {
+		return now.Add(-time.Duration(days)*24*time.Hour - time.Duration(hours)*time.Hour)
+	}
+
+	// Two archives a day for the last ten days, newest first: d0a, d0b, d1a, ...
+	var items []Item
+	for day := 0; day < 10; day++ {
+		items = append(items,
+			Item{Path: fmt.Sprintf("d%da", day), CreatedAt: at(day, 1)},
+			Item{Path: fmt.Sprintf("d%db", day), CreatedAt: at(day, 2)},
+		)
+	}
+
+	tests := []struct {
+		name   string
+		policy *types.Retention
+		want   []string
+	}{
+		{name: "keep last", policy: &types.Retention{KeepLast: 3}, want: []string{"d0a", "d0b", "d1a"}},
+		{name: "keep daily", policy: &types.Retention{KeepDaily: 3}, want: []string{"d0a", "d1a", "d2a"}},
+		// 2024-03-20 is a Wednesday, so d3 (Sunday) starts the previous ISO week
+		{name: "keep weekly", policy: &types.Retention{KeepWeekly: 2}, want: []string{"d0a", "d3a"}},
+		{name: "older than", policy: &types.Retention{OlderThan: "2d"}, want: []string{"d0a", "d0b", "d1a", "d1b"}},
+		{name: "rules combine", policy: &types.Retention{KeepLast: 1, KeepDaily: 2, OlderThan: "1d"}, want: []string{"d0a", "d0b", "d1a"}},
+		{name: "newest always kept", policy: &types.Retention{OlderThan: "1h"}, want: []string{"d0a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			decisions, err := Apply(items, tt.policy, now)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			var kept []string
+			for _, d := range decisions {
+				if d.Keep {
+					kept = append(kept, d.Path)
+				}
+			}
+			if !reflect.DeepEqual(kept, tt.want) {
+				t.Errorf("Apply() kept %v, want %v", kept, tt.want)
+			}
+		})
+	}
+
+	decisions, err := Apply(items, nil, now)
+	if err != nil {
+		t.Fatalf("Apply() error = %v", err)
+	}
+	for _, d := range decisions {
+		if !d.Keep {
+			t.Errorf("Apply() with no rules removes %s", d.Path)
+		}
+	}
+
+	if _, err := Apply(items, &types.Retention{KeepLast: -1}, now); err == nil {
+		t.Error("Apply() with a negative count should fail")
+	}
+}
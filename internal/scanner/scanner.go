@@ -32,6 +32,7 @@ type scanContext struct {
 	include     []*regexp.Regexp
 	exclude     []*regexp.Regexp
 	envExclude  []*regexp.Regexp
+	symlinks    string
 }
 
 // newScanContext creates a scan context with compiled patterns
@@ -58,6 +59,7 @@ func newScanContext(opts *types.ScanOptions, cfg *types.Config) (*scanContext, e
 		include:     include,
 		exclude:     exclude,
 		envExclude:  envExclude,
+		symlinks:    opts.SymlinkPolicy,
 	}, nil
 }
 
@@ -71,6 +73,31 @@ func matchesAny(name string, patterns []*regexp.Regexp) bool {
 	return false
 }
 
+// EnvMatcher tells env files apart by base name, as the scanner does
+type EnvMatcher struct {
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// NewEnvMatcher compiles the env patterns and env exclude patterns
+func NewEnvMatcher(patterns, excludePatterns []string) (*EnvMatcher, error) {
+	include, err := compilePatterns(patterns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile env patterns: %w", err)
+	}
+	exclude, err := compilePatterns(excludePatterns)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile env exclude patterns: %w", err)
+	}
+	return &EnvMatcher{include: include, exclude: exclude}, nil
+}
+
+// Match reports whether the file at path would be scanned as an env file
+func (m *EnvMatcher) Match(path string) bool {
+	name := filepath.Base(path)
+	return matchesAny(name, m.include) && !matchesAny(name, m.exclude)
+}
+
 // exceedsDepth returns true if path exceeds max depth (pure function)
 func exceedsDepth(relPath string, maxDepth int) bool {
 	return strings.Count(relPath, string(filepath.Separator)) > maxDepth
@@ -112,69 +139,208 @@ func applyDefaults(opts *types.ScanOptions, cfg *types.Config) {
 	if len(opts.ExcludePatterns) == 0 {
 		opts.ExcludePatterns = cfg.ExcludePatterns
 	}
+	if opts.SymlinkPolicy == "" {
+		opts.SymlinkPolicy = cfg.SymlinkPolicy
+	}
+	// Following keeps symlinked env files in archives, as before policies
+	// existed
+	if opts.SymlinkPolicy == "" {
+		opts.SymlinkPolicy = types.SymlinkFollow
+	}
 }
 
 // ScanFiles scans for environment files based on the provided options
 func (s *Service) ScanFiles(opts *types.ScanOptions) ([]types.EnvFile, error) {
 	applyDefaults(opts, s.config)
 
+	switch opts.SymlinkPolicy {
+	case types.SymlinkSkip, types.SymlinkFollow, types.SymlinkPreserve:
+	default:
+		return nil, fmt.Errorf("unknown symlink policy %q (expected one of: %s)",
+			opts.SymlinkPolicy, strings.Join(types.SymlinkPolicies, ", "))
+	}
+
 	sc, err := newScanContext(opts, s.config)
 	if err != nil {
 		return nil, err
 	}
 
-	var files []types.EnvFile
-	err = filepath.Walk(opts.RootPath, func(path string, info os.FileInfo, walkErr error) error {
-		if walkErr != nil {
-			return &types.ScanError{Path: path, Err: walkErr}
+	w := &walker{svc: s, sc: sc}
+
+	rootInfo, err := os.Stat(opts.RootPath)
+	if err != nil {
+		return nil, &types.ScanError{Path: opts.RootPath, Err: err}
+	}
+	if !rootInfo.IsDir() {
+		if err := w.visitFile(opts.RootPath, filepath.Base(opts.RootPath), rootInfo); err != nil {
+			return nil, err
 		}
+		return w.files, nil
+	}
+	if sc.shouldSkipDir(opts.RootPath) {
+		return nil, nil
+	}
 
-		relPath, relErr := filepath.Rel(sc.root, path)
-		if relErr != nil {
-			return &types.ScanError{Path: path, Err: relErr}
+	if err := w.walkDir(opts.RootPath, nil); err != nil {
+		return nil, err
+	}
+
+	return w.files, nil
+}
+
+// walker accumulates matching files while traversing the tree
+type walker struct {
+	svc   *Service
+	sc    *scanContext
+	files []types.EnvFile
+}
+
+// walkDir visits every entry of dir in lexical order. ancestors holds the
+// resolved paths of the directories above dir and is only tracked when
+// following symlinks, where it is used to break cycles.
+func (w *walker) walkDir(dir string, ancestors map[string]bool) error {
+	if w.sc.symlinks == types.SymlinkFollow {
+		realDir, err := filepath.EvalSymlinks(dir)
+		if err != nil {
+			return &types.ScanError{Path: dir, Err: err}
+		}
+		if ancestors[realDir] {
+			return nil // symlink loop
+		}
+		next := make(map[string]bool, len(ancestors)+1)
+		for k := range ancestors {
+			next[k] = true
 		}
+		next[realDir] = true
+		ancestors = next
+	}
 
-		if exceedsDepth(relPath, sc.maxDepth) {
-			if info.IsDir() {
-				return filepath.SkipDir
-			}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return &types.ScanError{Path: dir, Err: err}
+	}
+
+	for _, entry := range entries {
+		if err := w.visit(filepath.Join(dir, entry.Name()), ancestors); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// visit handles a single path below the scan root
+func (w *walker) visit(path string, ancestors map[string]bool) error {
+	info, err := os.Lstat(path)
+	if err != nil {
+		return &types.ScanError{Path: path, Err: err}
+	}
+
+	relPath, err := filepath.Rel(w.sc.root, path)
+	if err != nil {
+		return &types.ScanError{Path: path, Err: err}
+	}
+
+	if exceedsDepth(relPath, w.sc.maxDepth) {
+		return nil
+	}
+
+	if info.Mode()&os.ModeSymlink != 0 {
+		return w.visitSymlink(path, relPath, info, ancestors)
+	}
+
+	if info.IsDir() {
+		if w.sc.shouldSkipDir(path) {
 			return nil
 		}
+		return w.walkDir(path, ancestors)
+	}
 
+	return w.visitFile(path, relPath, info)
+}
+
+// visitSymlink applies the configured symlink policy to a link
+func (w *walker) visitSymlink(path, relPath string, linkInfo os.FileInfo, ancestors map[string]bool) error {
+	switch w.sc.symlinks {
+	case types.SymlinkFollow:
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil // dangling link, nothing to follow
+		}
 		if info.IsDir() {
-			if sc.shouldSkipDir(path) {
-				return filepath.SkipDir
+			if w.sc.shouldSkipDir(path) {
+				return nil
 			}
-			return nil
+			return w.walkDir(path, ancestors)
 		}
+		return w.visitFile(path, relPath, info)
 
-		if !sc.shouldInclude(info.Name(), info.Size()) {
+	case types.SymlinkPreserve:
+		if !w.sc.shouldInclude(linkInfo.Name(), 0) {
 			return nil
 		}
-
-		checksum, checksumErr := s.calculateChecksum(path)
-		if checksumErr != nil {
-			return &types.ScanError{
-				Path: path,
-				Err:  fmt.Errorf("failed to calculate checksum: %w", checksumErr),
-			}
+		target, err := os.Readlink(path)
+		if err != nil {
+			return &types.ScanError{Path: path, Err: fmt.Errorf("failed to read link: %w", err)}
 		}
-
-		files = append(files, types.EnvFile{
+		if err := checkLinkContained(w.sc.root, relPath, target); err != nil {
+			return &types.ScanError{Path: path, Err: err}
+		}
+		w.files = append(w.files, types.EnvFile{
 			Path:         path,
 			RelativePath: relPath,
-			Size:         info.Size(),
-			ModTime:      info.ModTime(),
-			Checksum:     checksum,
+			ModTime:      linkInfo.ModTime(),
+			Checksum:     LinkChecksum(target),
+			LinkTarget:   target,
 		})
 		return nil
-	})
 
+	default:
+		return nil
+	}
+}
+
+// visitFile records a regular file if it matches the scan patterns
+func (w *walker) visitFile(path, relPath string, info os.FileInfo) error {
+	if !w.sc.shouldInclude(info.Name(), info.Size()) {
+		return nil
+	}
+
+	checksum, err := w.svc.calculateChecksum(path)
 	if err != nil {
-		return nil, err
+		return &types.ScanError{
+			Path: path,
+			Err:  fmt.Errorf("failed to calculate checksum: %w", err),
+		}
+	}
+
+	w.files = append(w.files, types.EnvFile{
+		Path:         path,
+		RelativePath: relPath,
+		Size:         info.Size(),
+		ModTime:      info.ModTime(),
+		Checksum:     checksum,
+	})
+	return nil
+}
+
+// checkLinkContained rejects link targets that resolve outside the scan root.
+// Preserved links are validated again on unpack, so catching them here gives
+// an early, clearer error.
+func checkLinkContained(root, relPath, target string) error {
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("link target %s is absolute; use the follow symlink policy to pack its content", target)
+	}
+	resolved := filepath.Clean(filepath.Join(filepath.Dir(relPath), target))
+	if resolved == ".." || strings.HasPrefix(resolved, ".."+string(filepath.Separator)) {
+		return fmt.Errorf("link target %s points outside %s; use the follow symlink policy to pack its content", target, root)
 	}
+	return nil
+}
 
-	return files, nil
+// LinkChecksum returns the checksum recorded for a preserved symlink, which
+// is the SHA-256 of its target path
+func LinkChecksum(target string) string {
+	return fmt.Sprintf("%x", sha256.Sum256([]byte(target)))
 }
 
 // ValidateFile validates if a file is accessible and readable
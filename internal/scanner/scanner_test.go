@@ -391,6 +391,115 @@ func TestService_ErrorHandling(t *testing.T) {
 	}
 }
 
+func TestService_SymlinkPolicy(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	sharedDir := filepath.Join(tmpDir, "shared")
+	if err := os.MkdirAll(sharedDir, 0o700); err != nil {
+		t.Fatalf("Failed to create shared dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sharedDir, ".env.shared"), []byte("SHARED=1"), 0o600); err != nil {
+		t.Fatalf("Failed to create shared file: %v", err)
+	}
+
+	links := map[string]string{
+		".env":            "shared/.env.shared", // symlinked file
+		"linked":          "shared",             // symlinked directory
+		"shared/loop":     "..",                 // cycle back to the root
+		".env.dangling":   "missing",            // dangling link
+		"shared/.env.out": "../../outside",      // escapes the root
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(tmpDir, name)); err != nil {
+			t.Skipf("Symlink creation not supported: %v", err)
+		}
+	}
+
+	config := &types.Config{
+		DefaultDepth: 10,
+		EnvPatterns:  []string{`^\.env`},
+		MaxFileSize:  1024,
+	}
+	service := NewService(config)
+
+	t.Run("skip", func(t *testing.T) {
+		files, err := service.ScanFiles(&types.ScanOptions{RootPath: tmpDir, SymlinkPolicy: types.SymlinkSkip})
+		if err != nil {
+			t.Fatalf("ScanFiles() error = %v", err)
+		}
+		assertRelPaths(t, files, []string{"shared/.env.shared"})
+	})
+
+	t.Run("follow", func(t *testing.T) {
+		files, err := service.ScanFiles(&types.ScanOptions{RootPath: tmpDir, SymlinkPolicy: types.SymlinkFollow})
+		if err != nil {
+			t.Fatalf("ScanFiles() error = %v", err)
+		}
+		assertRelPaths(t, files, []string{".env", "linked/.env.shared", "shared/.env.shared"})
+		for _, f := range files {
+			if f.LinkTarget != "" {
+				t.Errorf("%s: LinkTarget = %q, want empty when following", f.RelativePath, f.LinkTarget)
+			}
+			if f.Size != int64(len("SHARED=1")) {
+				t.Errorf("%s: Size = %d, want target size", f.RelativePath, f.Size)
+			}
+		}
+	})
+
+	t.Run("default follows", func(t *testing.T) {
+		files, err := service.ScanFiles(&types.ScanOptions{RootPath: tmpDir})
+		if err != nil {
+			t.Fatalf("ScanFiles() error = %v", err)
+		}
+		assertRelPaths(t, files, []string{".env", "linked/.env.shared", "shared/.env.shared"})
+	})
+
+	t.Run("preserve rejects escaping links", func(t *testing.T) {
+		_, err := service.ScanFiles(&types.ScanOptions{RootPath: tmpDir, SymlinkPolicy: types.SymlinkPreserve})
+		if err == nil {
+			t.Fatal("ScanFiles() should reject a preserved link pointing outside the root")
+		}
+	})
+
+	t.Run("preserve", func(t *testing.T) {
+		if err := os.Remove(filepath.Join(tmpDir, "shared/.env.out")); err != nil {
+			t.Fatalf("Failed to remove link: %v", err)
+		}
+		files, err := service.ScanFiles(&types.ScanOptions{RootPath: tmpDir, SymlinkPolicy: types.SymlinkPreserve})
+		if err != nil {
+			t.Fatalf("ScanFiles() error = %v", err)
+		}
+		assertRelPaths(t, files, []string{".env", ".env.dangling", "shared/.env.shared"})
+		if files[0].LinkTarget != "shared/.env.shared" {
+			t.Errorf("LinkTarget = %q, want %q", files[0].LinkTarget, "shared/.env.shared")
+		}
+		if files[0].Checksum != LinkChecksum("shared/.env.shared") {
+			t.Errorf("Checksum = %q, want checksum of link target", files[0].Checksum)
+		}
+	})
+
+	t.Run("unknown policy", func(t *testing.T) {
+		if _, err := service.ScanFiles(&types.ScanOptions{RootPath: tmpDir, SymlinkPolicy: "copy"}); err == nil {
+			t.Error("ScanFiles() should reject an unknown symlink policy")
+		}
+	})
+}
+
+func assertRelPaths(t *testing.T, files []types.EnvFile, want []string) {
+	t.Helper()
+	var got []string
+	for _, f := range files {
+		got = append(got, filepath.ToSlash(f.RelativePath))
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
 // Helper function to create test directory structure
 func createTestDir(t *testing.T) string {
 	tmpDir, err := os.MkdirTemp("", "goingenv-test-*")
@@ -499,3 +608,31 @@ func BenchmarkScanFiles(b *testing.B) {
 		}
 	}
 }
+
+func TestEnvMatcher(t *testing.T) {
+	matcher, err := NewEnvMatcher([]string{`\.env.*`}, []string{`\.env\.example$`})
+	if err != nil {
+		t.Fatalf("NewEnvMatcher() error = %v", err)
+	}
+
+	tests := []struct {
+		path string
+		want bool
+	}{
+		{".env", true},
+		{"services/api/.env.local", true},
+		{".env.example", false},
+		{"main.go", false},
+		{".envoy/config.yaml", false},
+	}
+
+	for _, tt := range tests {
+		if got := matcher.Match(tt.path); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+
+	if _, err := NewEnvMatcher([]string{`(`}, nil); err == nil {
+		t.Error("NewEnvMatcher() with an invalid pattern error = nil, want error")
+	}
+}
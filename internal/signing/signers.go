@@ -0,0 +1,102 @@
+package signing
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"goingenv/pkg/utils"
+)
+
+// SignersFileName is the trusted signers file inside .goingenv
+const SignersFileName = "signers"
+
+// signersHeader starts every signers file written by SaveSigners
+const signersHeader = `# Trusted archive signers, one "<name> ed25519:<public key>" per line.
+# Commit this file; unpack and list check archive signatures against it.
+`
+
+// LoadSigners reads a trusted signers file. A missing file means no signers.
+func LoadSigners(path string) ([]Signer, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signers file: %w", err)
+	}
+
+	var signers []Signer
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.Fields(text)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: want \"<name> %s:<public key>\"", path, line, Algorithm)
+		}
+		if nameErr := ValidateName(fields[0]); nameErr != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, nameErr)
+		}
+		pub, parseErr := ParsePublicKey(fields[1])
+		if parseErr != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, parseErr)
+		}
+		signers = append(signers, Signer{Name: fields[0], PublicKey: pub})
+	}
+	return signers, nil
+}
+
+// SaveSigners writes the trusted signers file
+func SaveSigners(path string, signers []Signer) error {
+	var buf strings.Builder
+	buf.WriteString(signersHeader)
+	for _, s := range signers {
+		buf.WriteString(s.String())
+		buf.WriteByte('\n')
+	}
+	if err := utils.WriteFileAtomic(path, []byte(buf.String()), 0o644); err != nil {
+		return fmt.Errorf("failed to write signers file: %w", err)
+	}
+	return nil
+}
+
+// AddSigner adds s to signers, refusing duplicate names and keys
+func AddSigner(signers []Signer, s Signer) ([]Signer, error) {
+	for _, existing := range signers {
+		if existing.Name == s.Name {
+			return nil, fmt.Errorf("signer %q already exists", s.Name)
+		}
+		if existing.PublicKey.Equal(s.PublicKey) {
+			return nil, fmt.Errorf("key is already trusted as %q", existing.Name)
+		}
+	}
+	return append(signers, s), nil
+}
+
+// RemoveSigner removes the signer called name
+func RemoveSigner(signers []Signer, name string) ([]Signer, error) {
+	for i, s := range signers {
+		if s.Name == name {
+			return append(signers[:i:i], signers[i+1:]...), nil
+		}
+	}
+	return nil, fmt.Errorf("no signer named %q", name)
+}
+
+// FindSigner returns the trusted signer with the given public key
+func FindSigner(signers []Signer, pub ed25519.PublicKey) (Signer, bool) {
+	for _, s := range signers {
+		if s.PublicKey.Equal(pub) {
+			return s, true
+		}
+	}
+	return Signer{}, false
+}
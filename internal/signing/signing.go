@@ -0,0 +1,349 @@
+// Package signing creates and checks Ed25519 signatures over encrypted
+// archives. The archive password proves an archive wasn't corrupted; a
+// signature proves which key made it.
+//
+// Signatures are detached: <archive>.sig sits next to the archive and covers
+// every byte of it, so the salt, nonce, encrypted metadata and files are all
+// bound to the signer. Projects list the public keys they trust in
+// .goingenv/signers.
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"goingenv/pkg/types"
+	"goingenv/pkg/utils"
+)
+
+// Algorithm is the only supported signature algorithm
+const Algorithm = "ed25519"
+
+// SignatureExt is appended to an archive path to name its signature
+const SignatureExt = ".sig"
+
+// signatureVersion is the current signature file format
+const signatureVersion = 1
+
+// signatureContext prefixes the archive digest so a signature can't be
+// replayed as a signature over anything else
+const signatureContext = "goingenv-archive-signature-v1\n"
+
+// keyPEMType is the PEM block type of a private signing key
+const keyPEMType = "GOINGENV SIGNING KEY"
+
+// validName restricts signer names to something safe in files and logs
+var validName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._@-]*$`)
+
+// Key is a named private signing key
+type Key struct {
+	Name    string
+	Private ed25519.PrivateKey
+}
+
+// Signer is a named public key
+type Signer struct {
+	Name      string
+	PublicKey ed25519.PublicKey
+}
+
+// Signature is the content of an archive's .sig file
+type Signature struct {
+	Version   int       `json:"version"`
+	Algorithm string    `json:"algorithm"`
+	Signer    string    `json:"signer"`
+	PublicKey string    `json:"public_key"`
+	Signature string    `json:"signature"`
+	SignedAt  time.Time `json:"signed_at"`
+}
+
+// ValidateName checks that name can be used as a signer name
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("invalid signer name %q: use letters, digits and . _ @ -", name)
+	}
+	return nil
+}
+
+// GenerateKey creates a new signing key
+func GenerateKey(name string) (*Key, error) {
+	if err := ValidateName(name); err != nil {
+		return nil, err
+	}
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate signing key: %w", err)
+	}
+	return &Key{Name: name, Private: priv}, nil
+}
+
+// Signer returns the public half of the key
+func (k *Key) Signer() Signer {
+	pub, _ := k.Private.Public().(ed25519.PublicKey) //nolint:errcheck // always ed25519
+	return Signer{Name: k.Name, PublicKey: pub}
+}
+
+// DefaultKeyPath returns where keygen stores the key called name
+func DefaultKeyPath(name string) (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "goingenv", "signing", name+".key"), nil
+}
+
+// ResolveKeyPath accepts either a key file path or the name of a key in the
+// default location
+func ResolveKeyPath(nameOrPath string) (string, error) {
+	if strings.ContainsRune(nameOrPath, filepath.Separator) || !validName.MatchString(nameOrPath) {
+		return nameOrPath, nil
+	}
+	if _, err := os.Stat(nameOrPath); err == nil {
+		return nameOrPath, nil
+	}
+	return DefaultKeyPath(nameOrPath)
+}
+
+// SaveKey writes the key to path, readable only by the owner. An existing
+// file is never overwritten.
+func SaveKey(path string, key *Key) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("failed to create key directory: %w", err)
+	}
+
+	block := &pem.Block{
+		Type:    keyPEMType,
+		Headers: map[string]string{"Name": key.Name},
+		Bytes:   key.Private.Seed(),
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create key file: %w", err)
+	}
+	if encErr := pem.Encode(f, block); encErr != nil {
+		_ = f.Close() //nolint:errcheck // the write error is reported
+		return fmt.Errorf("failed to write key file: %w", encErr)
+	}
+	return f.Close()
+}
+
+// LoadKey reads a key written by SaveKey. Keys readable by other users are
+// refused.
+func LoadKey(path string) (*Key, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0o077 != 0 {
+		return nil, fmt.Errorf("signing key %s is accessible by other users (mode %04o); run chmod 600 on it",
+			path, info.Mode().Perm())
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key: %w", err)
+	}
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != keyPEMType || len(block.Bytes) != ed25519.SeedSize {
+		return nil, fmt.Errorf("%s is not a goingenv signing key", path)
+	}
+	name := block.Headers["Name"]
+	if nameErr := ValidateName(name); nameErr != nil {
+		return nil, fmt.Errorf("%s: %w", path, nameErr)
+	}
+
+	return &Key{Name: name, Private: ed25519.NewKeyFromSeed(block.Bytes)}, nil
+}
+
+// FormatPublicKey renders a public key as "ed25519:<base64>"
+func FormatPublicKey(pub ed25519.PublicKey) string {
+	return Algorithm + ":" + base64.StdEncoding.EncodeToString(pub)
+}
+
+// ParsePublicKey parses a key rendered by FormatPublicKey
+func ParsePublicKey(text string) (ed25519.PublicKey, error) {
+	encoded, ok := strings.CutPrefix(text, Algorithm+":")
+	if !ok {
+		return nil, fmt.Errorf("unsupported public key %q: want %s:<base64>", text, Algorithm)
+	}
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("invalid %s public key %q", Algorithm, text)
+	}
+	return ed25519.PublicKey(raw), nil
+}
+
+// String renders the signer as a line of the signers file
+func (s Signer) String() string {
+	return s.Name + " " + FormatPublicKey(s.PublicKey)
+}
+
+// SignaturePath returns the path of an archive's signature file
+func SignaturePath(archivePath string) string {
+	return archivePath + SignatureExt
+}
+
+// SignArchive signs the archive at path and writes its .sig file
+func SignArchive(archivePath string, key *Key) (*Signature, error) {
+	message, err := archiveMessage(archivePath)
+	if err != nil {
+		return nil, err
+	}
+
+	sig := &Signature{
+		Version:   signatureVersion,
+		Algorithm: Algorithm,
+		Signer:    key.Name,
+		PublicKey: FormatPublicKey(key.Signer().PublicKey),
+		Signature: base64.StdEncoding.EncodeToString(ed25519.Sign(key.Private, message)),
+		SignedAt:  time.Now().UTC(),
+	}
+
+	data, err := json.MarshalIndent(sig, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode signature: %w", err)
+	}
+	if writeErr := utils.WriteFileAtomic(SignaturePath(archivePath), append(data, '\n'), 0o644); writeErr != nil {
+		return nil, fmt.Errorf("failed to write signature: %w", writeErr)
+	}
+	return sig, nil
+}
+
+// ReadSignature reads an archive's .sig file. A missing signature returns an
+// error matching os.ErrNotExist.
+func ReadSignature(archivePath string) (*Signature, error) {
+	data, err := os.ReadFile(SignaturePath(archivePath))
+	if err != nil {
+		return nil, err
+	}
+
+	var sig Signature
+	if jsonErr := json.Unmarshal(data, &sig); jsonErr != nil {
+		return nil, fmt.Errorf("%w: %s is not valid JSON", types.ErrBadSignature, SignaturePath(archivePath))
+	}
+	if sig.Version != signatureVersion || sig.Algorithm != Algorithm {
+		return nil, fmt.Errorf("%w: unsupported signature version %d (%s)",
+			types.ErrBadSignature, sig.Version, sig.Algorithm)
+	}
+	return &sig, nil
+}
+
+// Verify checks that sig is a valid signature over the archive and returns
+// the public key that made it
+func Verify(archivePath string, sig *Signature) (ed25519.PublicKey, error) {
+	pub, err := ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", types.ErrBadSignature, err)
+	}
+	raw, err := base64.StdEncoding.DecodeString(sig.Signature)
+	if err != nil {
+		return nil, fmt.Errorf("%w: signature is not valid base64", types.ErrBadSignature)
+	}
+
+	message, err := archiveMessage(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	if !ed25519.Verify(pub, message, raw) {
+		return nil, fmt.Errorf("%w: %s was modified after %s signed it",
+			types.ErrBadSignature, filepath.Base(archivePath), sig.Signer)
+	}
+	return pub, nil
+}
+
+// Verification is the outcome of checking an archive against trusted signers
+type Verification struct {
+	Signed  bool       // a .sig file exists and matches the archive
+	Trusted bool       // the signing key is in the trusted signers list
+	Signer  string     // trusted name, or the name claimed by the signature
+	Sig     *Signature // nil when unsigned
+}
+
+// VerifyArchive checks the archive's signature, if any, against trusted.
+// Only a signature that doesn't match the archive is an error; whether an
+// unsigned or untrusted archive is acceptable is up to the caller.
+func VerifyArchive(archivePath string, trusted []Signer) (*Verification, error) {
+	sig, err := ReadSignature(archivePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return &Verification{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pub, err := Verify(archivePath, sig)
+	if err != nil {
+		return nil, err
+	}
+
+	v := &Verification{Signed: true, Signer: sig.Signer, Sig: sig}
+	if s, ok := FindSigner(trusted, pub); ok {
+		v.Trusted = true
+		v.Signer = s.Name
+	}
+	return v, nil
+}
+
+// CheckPolicy verifies the archive and applies a signature policy (one of
+// types.SignaturePolicies). A signature that doesn't match the archive is
+// always an error. An unsigned archive or unknown signer is an error
+// matching types.ErrUntrustedSigner under "require" and a returned warning
+// under "warn"; unsigned archives aren't flagged while no signers are
+// trusted, unless signatures are required.
+func CheckPolicy(archivePath string, trusted []Signer, policy string) (v *Verification, warning string, err error) {
+	if policy == types.SignatureOff {
+		return &Verification{}, "", nil
+	}
+
+	v, err = VerifyArchive(archivePath, trusted)
+	if err != nil {
+		return nil, "", err
+	}
+
+	name := filepath.Base(archivePath)
+	var problem string
+	switch {
+	case v.Trusted:
+		return v, "", nil
+	case v.Signed:
+		problem = fmt.Sprintf("%s is signed by an unknown key claiming to be %q", name, v.Signer)
+	case len(trusted) > 0 || policy == types.SignatureRequire:
+		problem = fmt.Sprintf("%s is not signed", name)
+	default:
+		return v, "", nil
+	}
+
+	if policy == types.SignatureRequire {
+		return nil, "", fmt.Errorf("%w: %s", types.ErrUntrustedSigner, problem)
+	}
+	return v, problem, nil
+}
+
+// archiveMessage is the byte string signed for an archive
+func archiveMessage(archivePath string) ([]byte, error) {
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, copyErr := io.Copy(hash, f); copyErr != nil {
+		return nil, fmt.Errorf("failed to read archive: %w", copyErr)
+	}
+	return hash.Sum([]byte(signatureContext)), nil
+}
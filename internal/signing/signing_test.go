@@ -0,0 +1,184 @@
+package signing
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"goingenv/pkg/types"
+)
+
+// writeArchive creates a fake archive in a temporary directory
+func writeArchive(t *testing.T) (dir, path string) {
+	t.Helper()
+	tmpDir, err := os.MkdirTemp("", "goingenv-signing-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(tmpDir) })
+
+	path = filepath.Join(tmpDir, "archive.enc")
+	if err := os.WriteFile(path, []byte("salt nonce ciphertext"), 0o600); err != nil {
+		t.Fatalf("Failed to write archive: %v", err)
+	}
+	return tmpDir, path
+}
+
+func TestKeyRoundTrip(t *testing.T) {
+	tmpDir, _ := writeArchive(t)
+	path := filepath.Join(tmpDir, "keys", "alice.key")
+
+	key, err := GenerateKey("alice")
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	if err := SaveKey(path, key); err != nil {
+		t.Fatalf("SaveKey() error = %v", err)
+	}
+	if err := SaveKey(path, key); err == nil {
+		t.Error("SaveKey() overwrote an existing key")
+	}
+
+	loaded, err := LoadKey(path)
+	if err != nil {
+		t.Fatalf("LoadKey() error = %v", err)
+	}
+	if loaded.Name != "alice" || !loaded.Private.Equal(key.Private) {
+		t.Errorf("LoadKey() = %q, want the saved key", loaded.Name)
+	}
+
+	if err := os.Chmod(path, 0o644); err != nil {
+		t.Fatalf("Failed to chmod: %v", err)
+	}
+	if _, err := LoadKey(path); err == nil || !strings.Contains(err.Error(), "other users") {
+		t.Errorf("LoadKey() of a world-readable key error = %v, want a permission error", err)
+	}
+
+	if _, err := GenerateKey("bad name"); err == nil {
+		t.Error("GenerateKey() accepted a name with a space")
+	}
+}
+
+func TestVerifyArchive(t *testing.T) {
+	alice, err := GenerateKey("alice")
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	mallory, err := GenerateKey("alice") // claims to be alice
+	if err != nil {
+		t.Fatalf("GenerateKey() error = %v", err)
+	}
+	trusted := []Signer{alice.Signer()}
+
+	tests := []struct {
+		name        string
+		key         *Key
+		tamper      func(path string) error
+		wantSigned  bool
+		wantTrusted bool
+		wantErr     error
+	}{
+		{name: "unsigned"},
+		{name: "trusted signer", key: alice, wantSigned: true, wantTrusted: true},
+		{name: "unknown signer", key: mallory, wantSigned: true},
+		{
+			name: "modified archive",
+			key:  alice,
+			tamper: func(path string) error {
+				return os.WriteFile(path, []byte("salt nonce ciphertexT"), 0o600)
+			},
+			wantErr: types.ErrBadSignature,
+		},
+		{
+			name: "corrupt signature file",
+			key:  alice,
+			tamper: func(path string) error {
+				return os.WriteFile(SignaturePath(path), []byte("{"), 0o600)
+			},
+			wantErr: types.ErrBadSignature,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, path := writeArchive(t)
+			if tt.key != nil {
+				if _, err := SignArchive(path, tt.key); err != nil {
+					t.Fatalf("SignArchive() error = %v", err)
+				}
+			}
+			if tt.tamper != nil {
+				if err := tt.tamper(path); err != nil {
+					t.Fatalf("tamper: %v", err)
+				}
+			}
+
+			v, err := VerifyArchive(path, trusted)
+			if !errors.Is(err, tt.wantErr) || (err != nil) != (tt.wantErr != nil) {
+				t.Fatalf("VerifyArchive() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if v.Signed != tt.wantSigned || v.Trusted != tt.wantTrusted {
+				t.Errorf("VerifyArchive() = signed %v trusted %v, want %v %v",
+					v.Signed, v.Trusted, tt.wantSigned, tt.wantTrusted)
+			}
+		})
+	}
+}
+
+func TestSignersFile(t *testing.T) {
+	tmpDir, _ := writeArchive(t)
+	path := filepath.Join(tmpDir, SignersFileName)
+
+	signers, err := LoadSigners(path)
+	if err != nil || signers != nil {
+		t.Fatalf("LoadSigners() of a missing file = %v, %v, want no signers", signers, err)
+	}
+
+	alice, _ := GenerateKey("alice") //nolint:errcheck // checked by TestKeyRoundTrip
+	bob, _ := GenerateKey("bob")     //nolint:errcheck // checked by TestKeyRoundTrip
+
+	signers, err = AddSigner(signers, alice.Signer())
+	if err != nil {
+		t.Fatalf("AddSigner() error = %v", err)
+	}
+	if signers, err = AddSigner(signers, bob.Signer()); err != nil {
+		t.Fatalf("AddSigner() error = %v", err)
+	}
+	if _, err := AddSigner(signers, Signer{Name: "alice", PublicKey: bob.Signer().PublicKey}); err == nil {
+		t.Error("AddSigner() accepted a duplicate name")
+	}
+	if _, err := AddSigner(signers, Signer{Name: "carol", PublicKey: bob.Signer().PublicKey}); err == nil {
+		t.Error("AddSigner() accepted a duplicate key")
+	}
+
+	if err := SaveSigners(path, signers); err != nil {
+		t.Fatalf("SaveSigners() error = %v", err)
+	}
+	loaded, err := LoadSigners(path)
+	if err != nil {
+		t.Fatalf("LoadSigners() error = %v", err)
+	}
+	if len(loaded) != 2 || loaded[1].String() != bob.Signer().String() {
+		t.Errorf("LoadSigners() = %v, want alice and bob", loaded)
+	}
+
+	loaded, err = RemoveSigner(loaded, "alice")
+	if err != nil || len(loaded) != 1 || loaded[0].Name != "bob" {
+		t.Errorf("RemoveSigner() = %v, %v, want only bob", loaded, err)
+	}
+	if _, err := RemoveSigner(loaded, "alice"); err == nil {
+		t.Error("RemoveSigner() of a missing signer should fail")
+	}
+
+	if err := os.WriteFile(path, []byte("alice ssh-rsa AAAA\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write signers: %v", err)
+	}
+	if _, err := LoadSigners(path); err == nil || !strings.Contains(err.Error(), ":1:") {
+		t.Errorf("LoadSigners() error = %v, want a line-numbered parse error", err)
+	}
+}
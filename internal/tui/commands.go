@@ -2,11 +2,18 @@ package tui
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"goingenv/internal/audit"
+	"goingenv/internal/catalog"
 	"goingenv/internal/config"
+	"goingenv/internal/signing"
+	"goingenv/internal/workspace"
 	"goingenv/pkg/types"
 	"goingenv/pkg/utils"
 )
@@ -52,13 +59,23 @@ func PackFilesCmd(app *types.App, files []types.EnvFile, password string) tea.Cm
 			return ErrorMsg(fmt.Sprintf("Error packing files: %v", err))
 		}
 
-		return PackCompleteMsg(fmt.Sprintf("Successfully packed %d files to %s", len(files), outputPath))
+		message := fmt.Sprintf("Successfully packed %d files to %s", len(files), outputPath)
+		message += auditNote(app, audit.OpPack, outputPath, files)
+		indexPath := catalog.Path(config.GetGoingEnvDir())
+		if indexErr := catalog.Record(indexPath, catalog.NewEntry(outputPath, len(files))); indexErr != nil {
+			message += fmt.Sprintf(" (archive index not updated: %v)", indexErr)
+		}
+		return PackCompleteMsg(message)
 	}
 }
 
 // UnpackFilesCmd unpacks files from an encrypted archive asynchronously
 func UnpackFilesCmd(app *types.App, password, archivePath string) tea.Cmd {
 	return func() tea.Msg {
+		if err := checkSignature(app, archivePath); err != nil {
+			return ErrorMsg(fmt.Sprintf("Refusing to unpack: %v", err))
+		}
+
 		// Create unpack options
 		unpackOpts := types.UnpackOptions{
 			ArchivePath: archivePath,
@@ -74,13 +91,22 @@ func UnpackFilesCmd(app *types.App, password, archivePath string) tea.Cmd {
 			return ErrorMsg(fmt.Sprintf("Error unpacking files: %v", err))
 		}
 
-		return UnpackCompleteMsg("Files successfully unpacked to current directory")
+		var files []types.EnvFile
+		if archive, listErr := app.Archiver.List(archivePath, password); listErr == nil {
+			files = archive.Files
+		}
+		message := "Files successfully unpacked to current directory"
+		return UnpackCompleteMsg(message + auditNote(app, audit.OpUnpack, archivePath, files))
 	}
 }
 
 // ListFilesCmd lists archive contents asynchronously
 func ListFilesCmd(app *types.App, password, archivePath string) tea.Cmd {
 	return func() tea.Msg {
+		if err := checkSignature(app, archivePath); err != nil {
+			return ErrorMsg(fmt.Sprintf("Refusing to list: %v", err))
+		}
+
 		archive, err := app.Archiver.List(archivePath, password)
 		if err != nil {
 			return ErrorMsg(fmt.Sprintf("Error listing archive: %v", err))
@@ -92,6 +118,17 @@ func ListFilesCmd(app *types.App, password, archivePath string) tea.Cmd {
 	}
 }
 
+// checkSignature applies the signature policy like the CLI does. Warnings
+// are dropped; only archives the policy refuses stop the operation.
+func checkSignature(app *types.App, archivePath string) error {
+	trusted, err := signing.LoadSigners(filepath.Join(config.GetGoingEnvDir(), signing.SignersFileName))
+	if err != nil {
+		return err
+	}
+	_, _, err = signing.CheckPolicy(archivePath, trusted, app.Config.SignaturePolicy)
+	return err
+}
+
 // ProgressCmd simulates progress updates for long-running operations
 func ProgressCmd(duration time.Duration) tea.Cmd {
 	return tea.Tick(duration/20, func(t time.Time) tea.Msg {
@@ -116,15 +153,16 @@ func ValidatePasswordCmd(app *types.App, archivePath, password string) tea.Cmd {
 // CheckArchiveIntegrityCmd checks the integrity of an archive
 func CheckArchiveIntegrityCmd(app *types.App, archivePath, password string) tea.Cmd {
 	return func() tea.Msg {
-		archive, err := app.Archiver.List(archivePath, password)
+		result, err := app.Archiver.Verify(archivePath, password)
 		if err != nil {
 			return ErrorMsg(fmt.Sprintf("Archive integrity check failed: %v", err))
 		}
+		if !result.OK() {
+			return ErrorMsg(fmt.Sprintf("Archive integrity check failed: %s", strings.Join(result.Problems, "; ")))
+		}
 
-		// Additional integrity checks could be performed here
-		// For now, we just verify we can read the metadata
 		message := fmt.Sprintf("Archive integrity verified - %d files, created %s",
-			len(archive.Files), archive.CreatedAt.Format("2006-01-02 15:04:05"))
+			len(result.Archive.Files), result.Archive.CreatedAt.Format("2006-01-02 15:04:05"))
 
 		return SuccessMsg(message)
 	}
@@ -151,13 +189,20 @@ func DeleteArchiveCmd(archivePath string) tea.Cmd {
 	}
 }
 
-// SaveConfigCmd saves configuration changes
-func SaveConfigCmd(app *types.App) tea.Cmd {
+// SaveConfigCmd applies a configuration change to the user config file and
+// to the running app. Only the user layer is loaded and saved, so project
+// settings and GOINGENV_* overrides are never written to it.
+func SaveConfigCmd(app *types.App, change func(cfg *types.Config)) tea.Cmd {
 	return func() tea.Msg {
-		err := app.ConfigMgr.Save(app.Config)
+		cfg, err := app.ConfigMgr.LoadUser()
 		if err != nil {
+			return ErrorMsg(fmt.Sprintf("Error loading configuration: %v", err))
+		}
+		change(cfg)
+		if err := app.ConfigMgr.Save(cfg); err != nil {
 			return ErrorMsg(fmt.Sprintf("Error saving configuration: %v", err))
 		}
+		change(app.Config)
 		return SuccessMsg("Configuration saved successfully")
 	}
 }
@@ -213,52 +258,78 @@ func formatArchiveContents(archive *types.Archive) string {
 
 // Batch operations commands
 
-// BatchPackCmd packs multiple directories in sequence
+// BatchPackCmd packs multiple directories in sequence and links the
+// resulting archives with a workspace manifest
 func BatchPackCmd(app *types.App, directories []string, password string) tea.Cmd {
 	return func() tea.Msg {
 		var results []string
 
+		workspaces := make([]workspace.Workspace, 0, len(directories))
 		for _, dir := range directories {
-			scanOpts := types.ScanOptions{
-				RootPath: dir,
-				MaxDepth: app.Config.DefaultDepth,
-			}
-
-			files, err := app.Scanner.ScanFiles(&scanOpts)
+			path, err := batchDir(dir)
 			if err != nil {
 				results = append(results, fmt.Sprintf("Error scanning %s: %v", dir, err))
 				continue
 			}
+			workspaces = append(workspaces, workspace.Workspace{Name: path, Path: path, Source: workspace.SourceConfig})
+		}
 
-			if len(files) == 0 {
-				results = append(results, fmt.Sprintf("No files found in %s", dir))
+		scanOpts := types.ScanOptions{MaxDepth: app.Config.DefaultDepth}
+		timestamp := time.Now().Format("20060102-150405")
+
+		var plans []workspace.Plan
+		for _, ws := range workspaces {
+			plan, err := workspace.ScanAll(app, ".", []workspace.Workspace{ws}, &scanOpts, config.GetGoingEnvDir(), timestamp)
+			if err != nil {
+				results = append(results, fmt.Sprintf("Error scanning %s: %v", ws.Path, err))
 				continue
 			}
-
-			outputPath := fmt.Sprintf("%s/archive-%s-%s.enc",
-				config.GetGoingEnvDir(),
-				utils.SanitizeFilename(dir),
-				time.Now().Format("20060102-150405"))
-
-			packOpts := types.PackOptions{
-				Files:       files,
-				OutputPath:  outputPath,
-				Password:    password,
-				Description: fmt.Sprintf("Batch archive from %s", dir),
+			if len(plan[0].Files) == 0 {
+				results = append(results, fmt.Sprintf("No files found in %s", ws.Path))
+				continue
 			}
+			plans = append(plans, plan[0])
+		}
 
-			err = app.Archiver.Pack(packOpts)
-			if err != nil {
-				results = append(results, fmt.Sprintf("Error packing %s: %v", dir, err))
+		packOpts := types.PackOptions{Password: password, Description: "Batch archive"}
+		_, manifestPath, packed, err := workspace.PackAll(app, plans, packOpts, config.GetGoingEnvDir())
+		for _, r := range packed {
+			if r.Err != nil {
+				results = append(results, fmt.Sprintf("Error packing %s: %v", r.Plan.Workspace.Path, r.Err))
 			} else {
-				results = append(results, fmt.Sprintf("Packed %s (%d files)", dir, len(files)))
+				message := fmt.Sprintf("Packed %s (%d files)", r.Plan.Workspace.Path, len(r.Plan.Files))
+				results = append(results, message+auditNote(app, audit.OpPack, r.Plan.Output, r.Plan.Files))
 			}
 		}
+		if err != nil {
+			results = append(results, fmt.Sprintf("Error writing manifest: %v", err))
+		} else if manifestPath != "" {
+			results = append(results, fmt.Sprintf("Index written to %s", manifestPath))
+		}
 
 		return SuccessMsg("Batch operation completed:\n" + utils.JoinResults(results))
 	}
 }
 
+// batchDir returns a batch directory relative to the project, which the
+// workspace manifest requires
+func batchDir(dir string) (string, error) {
+	rel := filepath.Clean(dir)
+	if filepath.IsAbs(rel) {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve the project directory: %w", err)
+		}
+		if rel, err = filepath.Rel(cwd, rel); err != nil {
+			return "", fmt.Errorf("directory is outside the project")
+		}
+	}
+	if rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("directory is outside the project")
+	}
+	return rel, nil
+}
+
 // Utility commands for common operations
 
 // QuickPackCmd performs a quick pack operation with default settings
@@ -293,8 +364,19 @@ func QuickPackCmd(app *types.App, password string) tea.Cmd {
 			return ErrorMsg(fmt.Sprintf("Quick pack failed: %v", err))
 		}
 
-		return SuccessMsg(fmt.Sprintf("Quick pack completed: %d files archived", len(files)))
+		message := fmt.Sprintf("Quick pack completed: %d files archived", len(files))
+		return SuccessMsg(message + auditNote(app, audit.OpPack, outputPath, files))
+	}
+}
+
+// auditNote records an archive operation in the audit log, returning a note
+// for the status message if the log cannot be written
+func auditNote(app *types.App, op, archivePath string, files []types.EnvFile) string {
+	entry := audit.NewEntry(op, archivePath, files)
+	if err := audit.Record(config.GetGoingEnvDir(), app.Config.AuditLog, entry); err != nil {
+		return fmt.Sprintf(" (audit log not updated: %v)", err)
 	}
+	return ""
 }
 
 // InitProjectCmd initializes goingenv in the current directory
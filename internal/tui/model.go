@@ -10,6 +10,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 
 	"goingenv/internal/config"
+	"goingenv/pkg/password"
 	"goingenv/pkg/types"
 )
 
@@ -54,6 +55,9 @@ type Model struct {
 	// Data
 	scannedFiles []types.EnvFile
 
+	// First entry of a new pack password, awaiting confirmation
+	passwordDraft string
+
 	// Debug logging
 	debugLogger *DebugLogger
 
@@ -220,6 +224,7 @@ func (m *Model) SetScreen(screen Screen) {
 	// Reset state when changing screens
 	m.message = ""
 	m.error = ""
+	password.ClearPassword(&m.passwordDraft)
 
 	// Focus/blur components as needed
 	switch screen {
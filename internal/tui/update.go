@@ -1,8 +1,10 @@
 package tui
 
 import (
+	"errors"
 	"fmt"
 
+	"goingenv/pkg/password"
 	"goingenv/pkg/types"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -163,7 +165,8 @@ func (m *Model) handleMenuKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-// handlePackPasswordKeys handles keyboard input during pack password entry
+// handlePackPasswordKeys handles keyboard input during pack password entry.
+// The password must meet the configured strength policy and is entered twice.
 func (m *Model) handlePackPasswordKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	switch msg.String() {
@@ -172,21 +175,50 @@ func (m *Model) handlePackPasswordKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.SetScreen(ScreenMenu)
 		return m, nil
 	case "enter":
-		password := m.textInput.Value()
-		if password == "" {
+		pw := m.textInput.Value()
+		m.textInput.SetValue("")
+		if pw == "" {
 			m.debugLogger.LogError("pack_password", fmt.Errorf("empty password"))
 			m.SetError("Password cannot be empty")
 			return m, nil
 		}
+
+		if m.passwordDraft == "" {
+			if strengthErr := password.CheckStrength(pw, m.app.Config.MinPasswordScore); strengthErr != nil {
+				m.debugLogger.LogError("pack_password", strengthErr)
+				m.error = "Password " + strengthMessage(strengthErr)
+				return m, nil
+			}
+			m.passwordDraft = pw
+			m.error = ""
+			return m, nil
+		}
+
+		if pw != m.passwordDraft {
+			m.debugLogger.LogError("pack_password", password.ErrPasswordMismatch)
+			password.ClearPassword(&m.passwordDraft)
+			m.error = "Passwords do not match, enter the password again"
+			return m, nil
+		}
+
 		m.debugLogger.LogOperation("pack_execute", fmt.Sprintf("starting pack operation with %d files", len(m.scannedFiles)))
 		m.SetScreen(ScreenPacking)
-		return m, PackFilesCmd(m.app, m.scannedFiles, password)
+		return m, PackFilesCmd(m.app, m.scannedFiles, pw)
 	default:
 		m.textInput, cmd = m.textInput.Update(msg)
 		return m, cmd
 	}
 }
 
+// strengthMessage extracts the reason from a password strength error
+func strengthMessage(err error) string {
+	var validationErr *types.ValidationError
+	if errors.As(err, &validationErr) {
+		return validationErr.Message
+	}
+	return err.Error()
+}
+
 // handleUnpackPasswordKeys handles keyboard input during unpack password entry
 func (m *Model) handleUnpackPasswordKeys(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
@@ -5,8 +5,10 @@ import (
 	"os"
 	"path/filepath"
 
+	"goingenv/internal/catalog"
 	"goingenv/internal/config"
 	"goingenv/internal/scanner"
+	"goingenv/pkg/password"
 	"goingenv/pkg/types"
 	"goingenv/pkg/utils"
 )
@@ -96,7 +98,14 @@ func (m *Model) renderPackPassword() string {
 		view += "\n"
 	}
 
-	view += "Password: " + m.textInput.View() + "\n"
+	if m.passwordDraft == "" {
+		view += "Password: " + m.textInput.View() + "\n"
+		if value := m.textInput.Value(); value != "" {
+			view += MutedStyle.Render("Strength: "+password.Estimate(value).Label()) + "\n"
+		}
+	} else {
+		view += "Confirm password: " + m.textInput.View() + "\n"
+	}
 
 	if m.error != "" {
 		view += "\n" + ErrorStyle.Render("Error: "+m.error)
@@ -112,7 +121,7 @@ func (m *Model) renderUnpackPassword() string {
 	view := RenderHeader(m.version) + "\n\n"
 
 	view += RenderSectionHeader("Unpacking archive") + "\n\n"
-	view += fmt.Sprintf("  %s\n\n", filepath.Base(m.selectedArchive))
+	view += fmt.Sprintf("  %s%s\n\n", filepath.Base(m.selectedArchive), archiveLabel(loadIndex(), m.selectedArchive))
 
 	view += "Password: " + m.textInput.View() + "\n"
 
@@ -130,7 +139,7 @@ func (m *Model) renderListPassword() string {
 	view := RenderHeader(m.version) + "\n\n"
 
 	view += RenderSectionHeader("List archive contents") + "\n\n"
-	view += fmt.Sprintf("  %s\n\n", filepath.Base(m.selectedArchive))
+	view += fmt.Sprintf("  %s%s\n\n", filepath.Base(m.selectedArchive), archiveLabel(loadIndex(), m.selectedArchive))
 
 	view += "Password: " + m.textInput.View() + "\n"
 
@@ -149,6 +158,7 @@ func (m *Model) renderUnpackSelect() string {
 
 	view += RenderSectionHeader("Select archive to unpack") + "\n\n"
 	view += m.filepicker.View() + "\n"
+	view += renderArchiveIndex()
 
 	view += "\n" + RenderFooter("[up/down] navigate", "[enter] select", "[esc] back")
 
@@ -161,12 +171,53 @@ func (m *Model) renderListSelect() string {
 
 	view += RenderSectionHeader("Select archive to list") + "\n\n"
 	view += m.filepicker.View() + "\n"
+	view += renderArchiveIndex()
 
 	view += "\n" + RenderFooter("[up/down] navigate", "[enter] select", "[esc] back")
 
 	return view
 }
 
+// loadIndex reads the archive index; an unreadable index shows as empty
+func loadIndex() *catalog.Catalog {
+	index, err := catalog.Load(catalog.Path(config.GetGoingEnvDir()))
+	if err != nil {
+		return &catalog.Catalog{}
+	}
+	return index
+}
+
+// archiveLabel returns an archive's name and tags from the archive index,
+// prefixed with a separator, or "" when it has none
+func archiveLabel(index *catalog.Catalog, archivePath string) string {
+	if entry, ok := index.Lookup(archivePath); ok && entry.Label() != "" {
+		return "    " + entry.Label()
+	}
+	return ""
+}
+
+// renderArchiveIndex lists the named, tagged or annotated archives below an
+// archive picker, newest first
+func renderArchiveIndex() string {
+	index := loadIndex()
+	view := ""
+	for i := len(index.Archives) - 1; i >= 0; i-- {
+		entry := index.Archives[i]
+		if entry.Label() == "" && entry.Note == "" {
+			continue
+		}
+		line := fmt.Sprintf("  %s  %s", entry.Archive, entry.Label())
+		if entry.Note != "" {
+			line += "  " + entry.Note
+		}
+		view += MutedStyle.Render(line) + "\n"
+	}
+	if view == "" {
+		return ""
+	}
+	return "\n" + RenderSectionHeader("Archive index") + "\n" + view
+}
+
 // renderPacking renders the packing progress screen
 func (m *Model) renderPacking() string {
 	view := RenderHeader(m.version) + "\n\n"
@@ -262,13 +313,15 @@ func (m *Model) renderStatus() string {
 		view += MutedStyle.Render("  No archives found") + "\n"
 	default:
 		view += RenderSectionHeader(fmt.Sprintf("Archives (%d)", len(archives))) + "\n"
+		index := loadIndex()
 		for _, archive := range archives {
 			info, statErr := os.Stat(archive)
 			if statErr == nil {
-				view += fmt.Sprintf("  %s    %s    %s\n",
+				view += fmt.Sprintf("  %s    %s    %s%s\n",
 					filepath.Base(archive),
 					utils.FormatSize(info.Size()),
-					utils.FormatTimeAgo(info.ModTime()))
+					utils.FormatTimeAgo(info.ModTime()),
+					archiveLabel(index, archive))
 			}
 		}
 	}
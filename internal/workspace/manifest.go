@@ -0,0 +1,192 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"goingenv/internal/constants"
+	"goingenv/pkg/types"
+	"goingenv/pkg/utils"
+)
+
+// manifestPrefix is the file name prefix of workspace index manifests
+const manifestPrefix = "workspaces-"
+
+// Manifest links the per-workspace archives produced by one pack run
+type Manifest struct {
+	CreatedAt time.Time `json:"created_at"`
+	Archives  []Entry   `json:"archives"`
+}
+
+// Entry records the archive created for a single workspace
+type Entry struct {
+	Workspace string `json:"workspace"`
+	Path      string `json:"path"`
+	Archive   string `json:"archive"` // file name inside the .goingenv directory
+	Files     int    `json:"files"`
+}
+
+// Find returns the entry for the workspace whose name or path matches ref
+func (m *Manifest) Find(ref string) (Entry, bool) {
+	ref = normalize(ref)
+	for _, e := range m.Archives {
+		if e.Workspace == ref || e.Path == ref {
+			return e, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Validate checks that the entry's paths stay inside the project. Manifests
+// are not authenticated, so an edited one must not point unpack elsewhere.
+func (e Entry) Validate() error {
+	for _, f := range []struct{ field, path string }{{"path", e.Path}, {"archive", e.Archive}} {
+		field, p := f.field, f.path
+		if p == "" || filepath.IsAbs(p) || strings.HasPrefix(filepath.ToSlash(p), "/") {
+			return fmt.Errorf("workspace %q: %s %q must be a relative path", e.Workspace, field, p)
+		}
+		for _, part := range strings.Split(filepath.ToSlash(p), "/") {
+			if part == ".." {
+				return fmt.Errorf("workspace %q: %s %q must not contain '..'", e.Workspace, field, p)
+			}
+		}
+	}
+	return nil
+}
+
+// Plan is a workspace together with the files that will be packed for it
+type Plan struct {
+	Workspace Workspace
+	Files     []types.EnvFile
+	Output    string
+}
+
+// Result describes the outcome of packing one workspace
+type Result struct {
+	Plan Plan
+	Err  error
+}
+
+// ScanAll scans every workspace below root. base supplies depth, patterns and
+// symlink policy; its RootPath is replaced per workspace.
+func ScanAll(app *types.App, root string, workspaces []Workspace, base *types.ScanOptions, archiveDir, timestamp string) ([]Plan, error) {
+	plans := make([]Plan, 0, len(workspaces))
+	for _, ws := range workspaces {
+		opts := *base
+		opts.RootPath = filepath.Join(root, filepath.FromSlash(ws.Path))
+
+		files, err := app.Scanner.ScanFiles(&opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan workspace %s: %w", ws.Name, err)
+		}
+
+		plans = append(plans, Plan{
+			Workspace: ws,
+			Files:     files,
+			Output: filepath.Join(archiveDir, fmt.Sprintf("archive-%s-%s%s",
+				utils.SanitizeFilename(ws.Name), timestamp, constants.ArchiveExtension)),
+		})
+	}
+	return plans, nil
+}
+
+// PackAll packs each plan that has files and writes a manifest linking the
+// resulting archives. Workspaces without files are skipped. The manifest is
+// written even when some workspaces fail, so that successful archives stay
+// reachable; the returned results carry per-workspace errors. opts holds
+// the password, description and validity period shared by every archive.
+func PackAll(app *types.App, plans []Plan, opts types.PackOptions, archiveDir string) (*Manifest, string, []Result, error) {
+	manifest := &Manifest{CreatedAt: time.Now()}
+	var results []Result
+
+	for _, plan := range plans {
+		if len(plan.Files) == 0 {
+			continue
+		}
+
+		packOpts := opts
+		packOpts.Files, packOpts.OutputPath = plan.Files, plan.Output
+		packOpts.Description = fmt.Sprintf("%s (workspace %s)", opts.Description, plan.Workspace.Path)
+		err := app.Archiver.Pack(packOpts)
+		results = append(results, Result{Plan: plan, Err: err})
+		if err != nil {
+			continue
+		}
+
+		manifest.Archives = append(manifest.Archives, Entry{
+			Workspace: plan.Workspace.Name,
+			Path:      plan.Workspace.Path,
+			Archive:   filepath.Base(plan.Output),
+			Files:     len(plan.Files),
+		})
+	}
+
+	if len(manifest.Archives) == 0 {
+		return manifest, "", results, nil
+	}
+
+	path := filepath.Join(archiveDir, manifestPrefix+manifest.CreatedAt.Format(constants.TimestampFormat)+".json")
+	if err := WriteManifest(path, manifest); err != nil {
+		return manifest, "", results, err
+	}
+	return manifest, path, results, nil
+}
+
+// WriteManifest writes a manifest as indented JSON
+func WriteManifest(path string, manifest *Manifest) error {
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal workspace manifest: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write workspace manifest: %w", err)
+	}
+	return nil
+}
+
+// ReadManifest reads a manifest from disk
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workspace manifest: %w", err)
+	}
+	var manifest Manifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse workspace manifest %s: %w", path, err)
+	}
+	return &manifest, nil
+}
+
+// LatestManifest returns the most recently created manifest in dir
+func LatestManifest(dir string) (*Manifest, string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read directory %s: %w", dir, err)
+	}
+
+	var latest *Manifest
+	var latestPath string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, manifestPrefix) || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		path := filepath.Join(dir, name)
+		manifest, readErr := ReadManifest(path)
+		if readErr != nil {
+			return nil, "", readErr
+		}
+		if latest == nil || manifest.CreatedAt.After(latest.CreatedAt) {
+			latest, latestPath = manifest, path
+		}
+	}
+
+	if latest == nil {
+		return nil, "", fmt.Errorf("no workspace manifest found in %s. Run 'goingenv pack --workspaces' first", dir)
+	}
+	return latest, latestPath, nil
+}
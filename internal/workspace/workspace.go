@@ -0,0 +1,388 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Sources a workspace definition can come from
+const (
+	SourceConfig = "config"
+	SourceGoWork = "go.work"
+	SourcePnpm   = "pnpm-workspace.yaml"
+	SourceNPM    = "package.json"
+)
+
+// maxGlobDepth bounds how deep glob patterns are expanded below the root
+const maxGlobDepth = 6
+
+// skippedDirs are never considered as workspace candidates
+var skippedDirs = map[string]bool{
+	".git":         true,
+	".goingenv":    true,
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// Workspace is a sub-project that gets its own archive
+type Workspace struct {
+	Name   string `json:"name"`
+	Path   string `json:"path"` // slash-separated, relative to the discovery root
+	Source string `json:"source"`
+}
+
+// Discover returns the workspaces below root. When configured is non-empty it
+// is used verbatim (globs allowed); otherwise go.work, pnpm-workspace.yaml and
+// package.json workspaces are merged.
+func Discover(root string, configured []string) ([]Workspace, error) {
+	var found []Workspace
+
+	if len(configured) > 0 {
+		paths, err := expand(root, configured, "")
+		if err != nil {
+			return nil, err
+		}
+		found = append(found, withSource(paths, SourceConfig)...)
+		return finalize(found), nil
+	}
+
+	goWork, err := fromGoWork(root)
+	if err != nil {
+		return nil, err
+	}
+	found = append(found, goWork...)
+
+	pnpm, err := fromPnpm(root)
+	if err != nil {
+		return nil, err
+	}
+	found = append(found, pnpm...)
+
+	npm, err := fromPackageJSON(root)
+	if err != nil {
+		return nil, err
+	}
+	found = append(found, npm...)
+
+	return finalize(found), nil
+}
+
+// Find returns the workspace whose name or path matches ref
+func Find(workspaces []Workspace, ref string) (Workspace, bool) {
+	ref = normalize(ref)
+	for _, ws := range workspaces {
+		if ws.Name == ref || ws.Path == ref {
+			return ws, true
+		}
+	}
+	return Workspace{}, false
+}
+
+// fromGoWork reads the use directives of go.work
+func fromGoWork(root string) ([]Workspace, error) {
+	data, err := os.ReadFile(filepath.Join(root, "go.work"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read go.work: %w", err)
+	}
+
+	var paths []string
+	inBlock := false
+	for _, line := range strings.Split(string(data), "\n") {
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		line = strings.TrimSpace(line)
+
+		switch {
+		case inBlock && line == ")":
+			inBlock = false
+		case inBlock && line != "":
+			paths = append(paths, unquote(line))
+		case line == "use (":
+			inBlock = true
+		case strings.HasPrefix(line, "use "):
+			paths = append(paths, unquote(strings.TrimSpace(strings.TrimPrefix(line, "use "))))
+		}
+	}
+
+	var existing []string
+	for _, p := range paths {
+		if info, statErr := os.Stat(filepath.Join(root, p)); statErr == nil && info.IsDir() {
+			existing = append(existing, normalize(p))
+		}
+	}
+	return withSource(existing, SourceGoWork), nil
+}
+
+// fromPnpm reads the packages list of pnpm-workspace.yaml
+func fromPnpm(root string) ([]Workspace, error) {
+	data, err := os.ReadFile(filepath.Join(root, "pnpm-workspace.yaml"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pnpm-workspace.yaml: %w", err)
+	}
+
+	paths, err := expand(root, parsePnpmPackages(string(data)), "package.json")
+	if err != nil {
+		return nil, fmt.Errorf("pnpm-workspace.yaml: %w", err)
+	}
+	return withSource(paths, SourcePnpm), nil
+}
+
+// parsePnpmPackages extracts the packages sequence from pnpm-workspace.yaml.
+// Only the block and flow sequence forms pnpm documents are understood.
+func parsePnpmPackages(content string) []string {
+	var patterns []string
+	inPackages := false
+
+	for _, raw := range strings.Split(content, "\n") {
+		line := raw
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = line[:i]
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "#") || strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") && !strings.HasPrefix(line, "-") {
+			inPackages = false
+			key, value, ok := strings.Cut(line, ":")
+			if ok && strings.TrimSpace(key) == "packages" {
+				value = strings.TrimSpace(value)
+				if strings.HasPrefix(value, "[") {
+					for _, item := range strings.Split(strings.Trim(value, "[]"), ",") {
+						if item = unquote(strings.TrimSpace(item)); item != "" {
+							patterns = append(patterns, item)
+						}
+					}
+				} else {
+					inPackages = true
+				}
+			}
+			continue
+		}
+
+		item := strings.TrimSpace(line)
+		if inPackages && strings.HasPrefix(item, "-") {
+			patterns = append(patterns, unquote(strings.TrimSpace(strings.TrimPrefix(item, "-"))))
+		}
+	}
+
+	return patterns
+}
+
+// fromPackageJSON reads the workspaces field of package.json
+func fromPackageJSON(root string) ([]Workspace, error) {
+	data, err := os.ReadFile(filepath.Join(root, "package.json"))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package.json: %w", err)
+	}
+
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse package.json: %w", err)
+	}
+	if len(pkg.Workspaces) == 0 {
+		return nil, nil
+	}
+
+	// workspaces is either an array or {"packages": [...]} (yarn classic)
+	var patterns []string
+	if err := json.Unmarshal(pkg.Workspaces, &patterns); err != nil {
+		var nested struct {
+			Packages []string `json:"packages"`
+		}
+		if nestedErr := json.Unmarshal(pkg.Workspaces, &nested); nestedErr != nil {
+			return nil, fmt.Errorf("failed to parse package.json workspaces: %w", nestedErr)
+		}
+		patterns = nested.Packages
+	}
+
+	paths, err := expand(root, patterns, "package.json")
+	if err != nil {
+		return nil, fmt.Errorf("package.json: %w", err)
+	}
+	return withSource(paths, SourceNPM), nil
+}
+
+// expand resolves glob patterns (with ! negations) to directories below root.
+// If marker is set, only directories containing that file are returned.
+func expand(root string, patterns []string, marker string) ([]string, error) {
+	var include, exclude []*regexp.Regexp
+	for _, p := range patterns {
+		negate := strings.HasPrefix(p, "!")
+		re, err := globToRegexp(strings.TrimPrefix(p, "!"))
+		if err != nil {
+			return nil, err
+		}
+		if negate {
+			exclude = append(exclude, re)
+		} else {
+			include = append(include, re)
+		}
+	}
+	if len(include) == 0 {
+		return nil, nil
+	}
+
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return walkErr
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			return relErr
+		}
+		if rel == "." {
+			return nil
+		}
+		if skippedDirs[d.Name()] {
+			return filepath.SkipDir
+		}
+		rel = filepath.ToSlash(rel)
+		if strings.Count(rel, "/") >= maxGlobDepth {
+			return filepath.SkipDir
+		}
+		if !matchesAny(rel, include) || matchesAny(rel, exclude) {
+			return nil
+		}
+		if marker != "" {
+			if _, statErr := os.Stat(filepath.Join(path, marker)); statErr != nil {
+				return nil
+			}
+		}
+		dirs = append(dirs, rel)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to expand workspace patterns: %w", err)
+	}
+
+	return dirs, nil
+}
+
+// globToRegexp converts a workspace glob (*, ?, **) to an anchored regexp
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	pattern = normalize(pattern)
+
+	var b strings.Builder
+	b.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch {
+		case pattern[i:] == "/**":
+			b.WriteString("(?:/.*)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(?:.*/)?")
+			i += 2
+		case c == '*' && strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case c == '*':
+			b.WriteString("[^/]*")
+		case c == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	b.WriteString("$")
+
+	re, err := regexp.Compile(b.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid workspace pattern %q: %w", pattern, err)
+	}
+	return re, nil
+}
+
+// finalize drops the root and duplicates, sorts by path and assigns names
+func finalize(found []Workspace) []Workspace {
+	seen := make(map[string]bool)
+	var result []Workspace
+	for _, ws := range found {
+		if ws.Path == "." || ws.Path == "" || seen[ws.Path] {
+			continue
+		}
+		seen[ws.Path] = true
+		result = append(result, ws)
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Path < result[j].Path })
+
+	// Use the directory name unless it is ambiguous, then fall back to the path
+	bases := make(map[string]int)
+	for _, ws := range result {
+		bases[pathBase(ws.Path)]++
+	}
+	for i := range result {
+		if base := pathBase(result[i].Path); bases[base] == 1 {
+			result[i].Name = base
+		} else {
+			result[i].Name = result[i].Path
+		}
+	}
+
+	return result
+}
+
+func withSource(paths []string, source string) []Workspace {
+	workspaces := make([]Workspace, 0, len(paths))
+	for _, p := range paths {
+		workspaces = append(workspaces, Workspace{Path: normalize(p), Source: source})
+	}
+	return workspaces
+}
+
+func matchesAny(path string, patterns []*regexp.Regexp) bool {
+	for _, re := range patterns {
+		if re.MatchString(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalize converts a user-supplied path to the slash-separated relative form
+func normalize(p string) string {
+	p = filepath.ToSlash(strings.TrimSpace(p))
+	p = strings.TrimPrefix(p, "./")
+	p = strings.TrimSuffix(p, "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+func pathBase(p string) string {
+	if i := strings.LastIndex(p, "/"); i >= 0 {
+		return p[i+1:]
+	}
+	return p
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
@@ -0,0 +1,230 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func createWorkspaceTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	tmpDir, err := os.MkdirTemp("", "goingenv-workspace-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	for path, content := range files {
+		full := filepath.Join(tmpDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o700); err != nil {
+			t.Fatalf("Failed to create dir: %v", err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+	return tmpDir
+}
+
+func workspacePaths(workspaces []Workspace) []string {
+	var paths []string
+	for _, ws := range workspaces {
+		paths = append(paths, ws.Path)
+	}
+	return paths
+}
+
+func assertPaths(t *testing.T, got []Workspace, want []string) {
+	t.Helper()
+	paths := workspacePaths(got)
+	if len(paths) != len(want) {
+		t.Fatalf("workspaces = %v, want %v", paths, want)
+	}
+	for i := range want {
+		if paths[i] != want[i] {
+			t.Fatalf("workspaces = %v, want %v", paths, want)
+		}
+	}
+}
+
+func TestDiscover_GoWork(t *testing.T) {
+	root := createWorkspaceTree(t, map[string]string{
+		"go.work":      "go 1.23\n\nuse ./api // main service\n\nuse (\n\t./tools\n\t\"./missing\"\n\t.\n)\n",
+		"api/go.mod":   "module api",
+		"tools/go.mod": "module tools",
+	})
+	defer os.RemoveAll(root)
+
+	workspaces, err := Discover(root, nil)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	assertPaths(t, workspaces, []string{"api", "tools"})
+	if workspaces[0].Source != SourceGoWork {
+		t.Errorf("Source = %s, want %s", workspaces[0].Source, SourceGoWork)
+	}
+}
+
+func TestDiscover_Pnpm(t *testing.T) {
+	root := createWorkspaceTree(t, map[string]string{
+		"pnpm-workspace.yaml":               "packages:\n  - 'apps/*'\n  - \"packages/**\"\n  - '!**/fixtures/**'\n# trailing comment\n",
+		"apps/web/package.json":             "{}",
+		"apps/docs/README.md":               "no package.json here",
+		"packages/ui/package.json":          "{}",
+		"packages/ui/fixtures/package.json": "{}",
+	})
+	defer os.RemoveAll(root)
+
+	workspaces, err := Discover(root, nil)
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	assertPaths(t, workspaces, []string{"apps/web", "packages/ui"})
+}
+
+func TestDiscover_PackageJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		pkgJSON string
+	}{
+		{name: "Array form", pkgJSON: `{"workspaces": ["services/*"]}`},
+		{name: "Object form", pkgJSON: `{"workspaces": {"packages": ["services/*"]}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := createWorkspaceTree(t, map[string]string{
+				"package.json":                  tt.pkgJSON,
+				"services/api/package.json":     "{}",
+				"services/worker/package.json":  "{}",
+				"node_modules/dep/package.json": "{}",
+			})
+			defer os.RemoveAll(root)
+
+			workspaces, err := Discover(root, nil)
+			if err != nil {
+				t.Fatalf("Discover() error = %v", err)
+			}
+			assertPaths(t, workspaces, []string{"services/api", "services/worker"})
+		})
+	}
+}
+
+func TestDiscover_ConfigOverridesFiles(t *testing.T) {
+	root := createWorkspaceTree(t, map[string]string{
+		"package.json":          `{"workspaces": ["apps/*"]}`,
+		"apps/web/package.json": "{}",
+		"infra/.env":            "A=1",
+		"backend/api/.env":      "B=2",
+		"backend/billing/.env":  "C=3",
+	})
+	defer os.RemoveAll(root)
+
+	workspaces, err := Discover(root, []string{"infra", "./backend/*"})
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+	assertPaths(t, workspaces, []string{"backend/api", "backend/billing", "infra"})
+	for _, ws := range workspaces {
+		if ws.Source != SourceConfig {
+			t.Errorf("%s: Source = %s, want %s", ws.Path, ws.Source, SourceConfig)
+		}
+	}
+}
+
+func TestDiscover_Names(t *testing.T) {
+	root := createWorkspaceTree(t, map[string]string{
+		"apps/api/.env":     "A=1",
+		"services/api/.env": "B=2",
+		"services/web/.env": "C=3",
+	})
+	defer os.RemoveAll(root)
+
+	workspaces, err := Discover(root, []string{"apps/*", "services/*"})
+	if err != nil {
+		t.Fatalf("Discover() error = %v", err)
+	}
+
+	names := map[string]string{}
+	for _, ws := range workspaces {
+		names[ws.Path] = ws.Name
+	}
+	want := map[string]string{"apps/api": "apps/api", "services/api": "services/api", "services/web": "web"}
+	for path, name := range want {
+		if names[path] != name {
+			t.Errorf("name for %s = %q, want %q", path, names[path], name)
+		}
+	}
+
+	if ws, ok := Find(workspaces, "web"); !ok || ws.Path != "services/web" {
+		t.Errorf("Find(web) = %v, %v", ws, ok)
+	}
+	if ws, ok := Find(workspaces, "./apps/api/"); !ok || ws.Path != "apps/api" {
+		t.Errorf("Find(./apps/api/) = %v, %v", ws, ok)
+	}
+}
+
+func TestParsePnpmPackages_FlowSequence(t *testing.T) {
+	got := parsePnpmPackages("packages: ['apps/*', \"libs/*\"]\ncatalog:\n  react: ^18\n")
+	if len(got) != 2 || got[0] != "apps/*" || got[1] != "libs/*" {
+		t.Errorf("parsePnpmPackages() = %v", got)
+	}
+}
+
+func TestLatestManifest(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-workspace-test-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	if _, _, err := LatestManifest(tmpDir); err == nil {
+		t.Error("LatestManifest() should fail when no manifest exists")
+	}
+
+	older := &Manifest{
+		CreatedAt: time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC),
+		Archives:  []Entry{{Workspace: "api", Path: "services/api", Archive: "old.enc", Files: 1}},
+	}
+	newer := &Manifest{
+		CreatedAt: time.Date(2026, 3, 4, 0, 0, 0, 0, time.UTC),
+		Archives:  []Entry{{Workspace: "api", Path: "services/api", Archive: "new.enc", Files: 2}},
+	}
+	// File names deliberately sort opposite to creation time
+	if err := WriteManifest(filepath.Join(tmpDir, "workspaces-b.json"), older); err != nil {
+		t.Fatalf("WriteManifest() error = %v", err)
+	}
+	if err := WriteManifest(filepath.Join(tmpDir, "workspaces-a.json"), newer); err != nil {
+		t.Fatalf("WriteManifest() error = %v", err)
+	}
+
+	manifest, _, err := LatestManifest(tmpDir)
+	if err != nil {
+		t.Fatalf("LatestManifest() error = %v", err)
+	}
+	entry, ok := manifest.Find("services/api")
+	if !ok || entry.Archive != "new.enc" {
+		t.Errorf("Find() = %v, %v, want new.enc", entry, ok)
+	}
+}
+
+func TestEntry_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		entry   Entry
+		wantErr bool
+	}{
+		{name: "Workspace", entry: Entry{Path: "services/api", Archive: "api.enc"}},
+		{name: "Root workspace", entry: Entry{Path: ".", Archive: "root.enc"}},
+		{name: "Absolute path", entry: Entry{Path: "/etc", Archive: "api.enc"}, wantErr: true},
+		{name: "Escaping path", entry: Entry{Path: "services/../../x", Archive: "api.enc"}, wantErr: true},
+		{name: "Absolute archive", entry: Entry{Path: "api", Archive: "/tmp/evil.enc"}, wantErr: true},
+		{name: "Escaping archive", entry: Entry{Path: "api", Archive: "../evil.enc"}, wantErr: true},
+		{name: "Empty archive", entry: Entry{Path: "api"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := tt.entry.Validate(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
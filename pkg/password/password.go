@@ -1,26 +1,48 @@
 package password
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"strings"
 	"syscall"
+	"time"
 
 	"golang.org/x/term"
 )
 
+// ErrPasswordMismatch is returned when the confirmation differs from the password
+var ErrPasswordMismatch = errors.New("passwords do not match")
+
 // Options contains password input configuration
 type Options struct {
-	PasswordEnv string // Environment variable name
+	PasswordFile  string        // File containing the password
+	PasswordFD    int           // Open file descriptor to read the password from (0 = unset)
+	PasswordStdin bool          // Read the password from standard input
+	PasswordCmd   string        // Shell command that prints the password
+	CmdTimeout    time.Duration // Timeout for PasswordCmd (default DefaultCmdTimeout)
+	PasswordEnv   string        // Environment variable name
 }
 
 // GetPassword retrieves password using the specified options
-// Priority order: PasswordEnv -> Interactive prompt
+// Priority order: PasswordFile -> PasswordFD -> PasswordStdin -> PasswordCmd ->
+// PasswordEnv -> Interactive prompt
 func GetPassword(opts Options) (string, error) {
 	var password string
 	var err error
 
-	// Try environment variable first
+	switch {
+	case opts.PasswordFile != "":
+		return readPasswordFromFile(opts.PasswordFile)
+	case opts.PasswordFD != 0:
+		return readPasswordFromFD(opts.PasswordFD)
+	case opts.PasswordStdin:
+		return readPasswordFromStdin()
+	case opts.PasswordCmd != "":
+		return readPasswordFromCmd(opts.PasswordCmd, opts.CmdTimeout)
+	}
+
+	// Try environment variable next
 	if opts.PasswordEnv != "" {
 		password, err = readPasswordFromEnv(opts.PasswordEnv)
 		if err != nil {
@@ -34,7 +56,41 @@ func GetPassword(opts Options) (string, error) {
 	}
 
 	// Fall back to interactive prompt
-	return readPasswordInteractively()
+	return readPasswordInteractively("Enter encryption password: ")
+}
+
+// GetNewPassword retrieves a password for a new archive. It must score at
+// least minScore (see Estimate), and an interactively entered password must
+// be typed twice.
+func GetNewPassword(opts Options, minScore int) (string, error) {
+	password, err := GetPassword(opts)
+	if err != nil {
+		return "", err
+	}
+	if strengthErr := CheckStrength(password, minScore); strengthErr != nil {
+		ClearPassword(&password)
+		return "", strengthErr
+	}
+	if opts.NonInteractive() {
+		return password, nil
+	}
+
+	confirmation, err := readPasswordInteractively("Confirm encryption password: ")
+	defer ClearPassword(&confirmation)
+	if err != nil {
+		ClearPassword(&password)
+		return "", err
+	}
+	if confirmation != password {
+		ClearPassword(&password)
+		return "", ErrPasswordMismatch
+	}
+	return password, nil
+}
+
+// NonInteractive reports whether a password source other than the prompt is set
+func (o Options) NonInteractive() bool {
+	return o.PasswordFile != "" || o.PasswordFD != 0 || o.PasswordStdin || o.PasswordCmd != "" || o.PasswordEnv != ""
 }
 
 // readPasswordFromEnv reads password from environment variable
@@ -47,10 +103,10 @@ func readPasswordFromEnv(envVar string) (string, error) {
 }
 
 // readPasswordInteractively prompts user for password with hidden input
-func readPasswordInteractively() (string, error) {
-	fmt.Print("Enter encryption password: ")
+func readPasswordInteractively(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
 	passwordBytes, err := term.ReadPassword(syscall.Stdin)
-	fmt.Println() // Add newline after hidden input
+	fmt.Fprintln(os.Stderr) // Add newline after hidden input
 
 	if err != nil {
 		return "", fmt.Errorf("failed to read password: %w", err)
@@ -89,5 +145,33 @@ func ValidatePasswordOptions(opts Options) error {
 		}
 	}
 
+	if opts.PasswordFile != "" && strings.TrimSpace(opts.PasswordFile) == "" {
+		return fmt.Errorf("password file path cannot be empty")
+	}
+
+	if opts.PasswordFD < 0 || opts.PasswordFD == 1 || opts.PasswordFD == 2 {
+		return fmt.Errorf("invalid password file descriptor %d (use --password-stdin for standard input)", opts.PasswordFD)
+	}
+
+	if opts.PasswordCmd != "" && strings.TrimSpace(opts.PasswordCmd) == "" {
+		return fmt.Errorf("password command cannot be empty")
+	}
+
+	if opts.CmdTimeout < 0 {
+		return fmt.Errorf("password command timeout cannot be negative")
+	}
+
+	// The environment variable is a fallback, but the explicit sources are
+	// exclusive: silently picking one would hide a misconfiguration.
+	sources := 0
+	for _, set := range []bool{opts.PasswordFile != "", opts.PasswordFD != 0, opts.PasswordStdin, opts.PasswordCmd != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources > 1 {
+		return fmt.Errorf("conflicting password sources: use only one of --password-file, --password-fd, --password-stdin, --password-cmd")
+	}
+
 	return nil
 }
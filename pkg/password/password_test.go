@@ -2,8 +2,10 @@ package password
 
 import (
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetPasswordFromEnv(t *testing.T) {
@@ -89,6 +91,40 @@ func TestValidatePasswordOptions(t *testing.T) {
 			expectError:   true,
 			errorContains: "cannot be empty",
 		},
+		{
+			name:        "valid file descriptor",
+			opts:        Options{PasswordFD: 3},
+			expectError: false,
+		},
+		{
+			name:          "stdout file descriptor",
+			opts:          Options{PasswordFD: 1},
+			expectError:   true,
+			errorContains: "invalid password file descriptor",
+		},
+		{
+			name:          "blank password command",
+			opts:          Options{PasswordCmd: "  "},
+			expectError:   true,
+			errorContains: "cannot be empty",
+		},
+		{
+			name:          "file and command together",
+			opts:          Options{PasswordFile: "/run/secrets/pw", PasswordCmd: "pass show pw"},
+			expectError:   true,
+			errorContains: "conflicting password sources",
+		},
+		{
+			name:          "stdin and file descriptor together",
+			opts:          Options{PasswordStdin: true, PasswordFD: 3},
+			expectError:   true,
+			errorContains: "conflicting password sources",
+		},
+		{
+			name:        "file with env fallback",
+			opts:        Options{PasswordFile: "/run/secrets/pw", PasswordEnv: "GOINGENV_PASSWORD"},
+			expectError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -183,3 +219,120 @@ func TestClearPassword(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPasswordSources(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "goingenv-password-*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	writeSecret := func(name, content string, mode os.FileMode) string {
+		path := filepath.Join(tmpDir, name)
+		if writeErr := os.WriteFile(path, []byte(content), mode); writeErr != nil {
+			t.Fatalf("Failed to write %s: %v", name, writeErr)
+		}
+		if chmodErr := os.Chmod(path, mode); chmodErr != nil {
+			t.Fatalf("Failed to chmod %s: %v", name, chmodErr)
+		}
+		return path
+	}
+
+	envVar := "TEST_PASSWORD_SOURCES"
+	t.Setenv(envVar, "env-password")
+
+	tests := []struct {
+		name          string
+		opts          Options
+		expectedPass  string
+		errorContains string
+	}{
+		{
+			name:         "file",
+			opts:         Options{PasswordFile: writeSecret("secret", "file-password\n", 0o600)},
+			expectedPass: "file-password",
+		},
+		{
+			name:         "file wins over env",
+			opts:         Options{PasswordFile: writeSecret("secret-crlf", "file-password\r\n", 0o600), PasswordEnv: envVar},
+			expectedPass: "file-password",
+		},
+		{
+			name:          "writable by others",
+			opts:          Options{PasswordFile: writeSecret("open", "file-password", 0o666)},
+			errorContains: "writable by other users",
+		},
+		{
+			name:          "empty file",
+			opts:          Options{PasswordFile: writeSecret("empty", "\n", 0o600)},
+			errorContains: "cannot be empty",
+		},
+		{
+			name:          "missing file",
+			opts:          Options{PasswordFile: filepath.Join(tmpDir, "missing")},
+			errorContains: "failed to read password file",
+		},
+		{
+			name:          "directory",
+			opts:          Options{PasswordFile: tmpDir},
+			errorContains: "not a regular file",
+		},
+		{
+			name:         "command",
+			opts:         Options{PasswordCmd: "echo cmd-password", PasswordEnv: envVar},
+			expectedPass: "cmd-password",
+		},
+		{
+			name:          "failing command",
+			opts:          Options{PasswordCmd: "exit 3"},
+			errorContains: "password command failed",
+		},
+		{
+			name:          "command timeout",
+			opts:          Options{PasswordCmd: "sleep 2", CmdTimeout: 100 * time.Millisecond},
+			errorContains: "timed out",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			password, err := GetPassword(tt.opts)
+			if tt.errorContains != "" {
+				if err == nil {
+					t.Fatalf("Expected error containing '%s', got password '%s'", tt.errorContains, password)
+				}
+				if !strings.Contains(err.Error(), tt.errorContains) {
+					t.Errorf("Expected error to contain '%s', got: %v", tt.errorContains, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if password != tt.expectedPass {
+				t.Errorf("Expected password '%s', got '%s'", tt.expectedPass, password)
+			}
+		})
+	}
+}
+
+func TestGetPasswordFromFD(t *testing.T) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	defer r.Close()
+
+	go func() {
+		_, _ = w.WriteString("fd-password\n") //nolint:errcheck // reader reports short writes
+		w.Close()
+	}()
+
+	password, err := GetPassword(Options{PasswordFD: int(r.Fd())})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if password != "fd-password" {
+		t.Errorf("Expected password 'fd-password', got '%s'", password)
+	}
+}
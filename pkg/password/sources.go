@@ -0,0 +1,161 @@
+package password
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// DefaultCmdTimeout bounds how long a password command may run
+const DefaultCmdTimeout = 30 * time.Second
+
+// maxPasswordSize is the most bytes read from a file, descriptor or command
+const maxPasswordSize = 64 * 1024
+
+// readPasswordFromFile reads the password from a file, refusing files other
+// users can modify
+func readPasswordFromFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password file: %w", err)
+	}
+	defer f.Close()
+
+	// Stat the open handle so the check covers the file actually read,
+	// even if the path is swapped after opening.
+	info, err := f.Stat()
+	if err != nil {
+		return "", fmt.Errorf("failed to read password file: %w", err)
+	}
+	if permErr := checkPasswordFileMode(path, info); permErr != nil {
+		return "", permErr
+	}
+
+	password, err := readSecret(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password file %s: %w", path, err)
+	}
+	return password, nil
+}
+
+// checkPasswordFileMode rejects password files that are not regular files or
+// are writable by group or others, and warns when others can read them.
+// Permission bits are not meaningful on Windows, so only the type is checked.
+func checkPasswordFileMode(path string, info os.FileInfo) error {
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("password file %s is not a regular file", path)
+	}
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	perm := info.Mode().Perm()
+	if perm&0o022 != 0 {
+		return fmt.Errorf("password file %s is writable by other users (mode %04o); run 'chmod 600 %s'", path, perm, path)
+	}
+	if perm&0o004 != 0 {
+		fmt.Fprintf(os.Stderr, "WARNING: Password file '%s' is readable by all users (mode %04o)\n", path, perm)
+	}
+	return nil
+}
+
+// readPasswordFromFD reads the password from an inherited file descriptor,
+// e.g. --password-fd 3 3<secret
+func readPasswordFromFD(fd int) (string, error) {
+	f := os.NewFile(uintptr(fd), fmt.Sprintf("fd%d", fd)) //nolint:gosec // G115: fd is validated non-negative
+	if f == nil {
+		return "", fmt.Errorf("invalid password file descriptor %d", fd)
+	}
+	defer f.Close()
+
+	password, err := readSecret(f)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password from file descriptor %d: %w", fd, err)
+	}
+	return password, nil
+}
+
+// readPasswordFromStdin reads the password from standard input until EOF
+func readPasswordFromStdin() (string, error) {
+	password, err := readSecret(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password from stdin: %w", err)
+	}
+	return password, nil
+}
+
+// readPasswordFromCmd runs a shell command and uses its standard output as the
+// password. The command's stderr and stdin are passed through so password
+// managers can prompt.
+func readPasswordFromCmd(command string, timeout time.Duration) (string, error) {
+	if timeout == 0 {
+		timeout = DefaultCmdTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+
+	cmd := shellCommand(ctx, command)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	cmd.WaitDelay = time.Second
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("password command timed out after %s", timeout)
+	}
+	if err != nil {
+		return "", fmt.Errorf("password command failed: %w", err)
+	}
+
+	output := stdout.Bytes()
+	defer clearBytes(output)
+
+	password, err := readSecret(bytes.NewReader(output))
+	if err != nil {
+		return "", fmt.Errorf("password command output: %w", err)
+	}
+	return password, nil
+}
+
+// shellCommand runs command through the platform shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/C", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
+// readSecret reads a password from r, dropping trailing line endings
+func readSecret(r io.Reader) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxPasswordSize+1))
+	defer clearBytes(data)
+	if err != nil {
+		return "", err
+	}
+	if len(data) > maxPasswordSize {
+		return "", fmt.Errorf("password is larger than %d bytes", maxPasswordSize)
+	}
+
+	password := strings.TrimRight(string(data), "\r\n")
+	if password == "" {
+		return "", fmt.Errorf("password cannot be empty")
+	}
+	return password, nil
+}
+
+// clearBytes zeroes a byte slice
+func clearBytes(data []byte) {
+	for i := range data {
+		data[i] = 0
+	}
+}
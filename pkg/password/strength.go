@@ -0,0 +1,172 @@
+package password
+
+import (
+	_ "embed"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"goingenv/pkg/types"
+)
+
+// Strength scores range from 0 (trivially guessable) to MaxScore
+const (
+	MaxScore        = 4
+	DefaultMinScore = 2
+)
+
+// minCommonSubstring is the shortest common password that is also searched
+// for inside longer passwords; shorter entries only match the whole password
+const minCommonSubstring = 6
+
+//go:embed common_passwords.txt
+var commonPasswordList string
+
+// commonPasswords is the embedded list of frequently used passwords;
+// commonSubstrings holds its longer entries, longest first
+var commonPasswords, commonSubstrings = loadCommonPasswords(commonPasswordList)
+
+// scoreThresholds are the entropy bits needed to reach scores 1 through 4
+var scoreThresholds = [MaxScore]float64{28, 36, 60, 80}
+
+// scoreLabels describe each score
+var scoreLabels = [MaxScore + 1]string{"very weak", "weak", "fair", "strong", "very strong"}
+
+// Strength is an offline estimate of how hard a password is to guess
+type Strength struct {
+	Score   int     // 0 (very weak) to MaxScore (very strong)
+	Entropy float64 // estimated bits of entropy
+	Warning string  // main weakness found, if any
+}
+
+// Label returns a human-readable name for the score
+func (s Strength) Label() string {
+	return scoreLabels[s.Score]
+}
+
+// Estimate scores a password by its character pool and length, discounting
+// common passwords, repeated characters and runs such as "abc" or "321"
+func Estimate(password string) Strength {
+	if password == "" {
+		return Strength{Warning: "password is empty"}
+	}
+
+	lower := strings.ToLower(password)
+	if commonPasswords[lower] || commonPasswords[unleet(lower)] {
+		return Strength{Warning: "this is a commonly used password"}
+	}
+
+	warning := ""
+	// Each common password inside the input costs only as much as picking
+	// one entry from the list
+	listBits := math.Log2(float64(len(commonPasswords)))
+	bits := 0.0
+	rest := lower
+	for _, word := range commonSubstrings {
+		for _, candidate := range []string{rest, unleet(rest)} {
+			if i := strings.Index(candidate, word); i >= 0 {
+				rest = rest[:i] + rest[i+len(word):]
+				bits += listBits
+				warning = fmt.Sprintf("contains the common password %q", word)
+				break
+			}
+		}
+	}
+
+	poolBits := math.Log2(float64(charPool(password)))
+	var prev rune
+	for i, r := range rest {
+		switch {
+		case i > 0 && r == prev:
+			bits++ // repeated character
+		case i > 0 && (r == prev+1 || r == prev-1):
+			bits++ // sequence like "abc" or "321"
+		default:
+			bits += poolBits
+		}
+		prev = r
+	}
+
+	s := Strength{Entropy: bits, Warning: warning}
+	for s.Score < MaxScore && bits >= scoreThresholds[s.Score] {
+		s.Score++
+	}
+	if s.Warning == "" && s.Score < DefaultMinScore {
+		s.Warning = "use a longer password or a passphrase of several words"
+	}
+	return s
+}
+
+// CheckStrength returns a validation error when password scores below
+// minScore. A minScore of 0 disables the check.
+func CheckStrength(password string, minScore int) error {
+	s := Estimate(password)
+	if s.Score >= minScore {
+		return nil
+	}
+	return &types.ValidationError{
+		Field: "password",
+		Value: s.Score,
+		Message: fmt.Sprintf("too weak (%s, score %d of %d; at least %d required): %s",
+			s.Label(), s.Score, MaxScore, minScore, s.Warning),
+	}
+}
+
+// charPool estimates the alphabet size from the character classes used
+func charPool(password string) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII:
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	pool := 0
+	for _, class := range []struct {
+		used bool
+		size int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} {
+		if class.used {
+			pool += class.size
+		}
+	}
+	return pool
+}
+
+// unleet undoes common character substitutions such as "p@ssw0rd"
+func unleet(s string) string {
+	return strings.NewReplacer("@", "a", "4", "a", "3", "e", "1", "i", "!", "i",
+		"0", "o", "$", "s", "5", "s", "7", "t").Replace(s)
+}
+
+// loadCommonPasswords parses the embedded list into a set and the entries
+// worth searching for inside longer passwords
+func loadCommonPasswords(list string) (set map[string]bool, substrings []string) {
+	set = make(map[string]bool)
+	for _, line := range strings.Split(list, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			set[line] = true
+			if len(line) >= minCommonSubstring {
+				substrings = append(substrings, line)
+			}
+		}
+	}
+	sort.Slice(substrings, func(i, j int) bool {
+		if len(substrings[i]) != len(substrings[j]) {
+			return len(substrings[i]) > len(substrings[j])
+		}
+		return substrings[i] < substrings[j]
+	})
+	return set, substrings
+}
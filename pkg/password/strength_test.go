@@ -0,0 +1,89 @@
+package password
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"goingenv/pkg/types"
+)
+
+func TestEstimate(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		minScore int
+		maxScore int
+		warning  string
+	}{
+		{name: "empty", password: "", maxScore: 0, warning: "empty"},
+		{name: "common password", password: "password", maxScore: 0, warning: "commonly used"},
+		{name: "common password with substitutions", password: "P@ssw0rd", maxScore: 0, warning: "commonly used"},
+		{name: "common password with suffix", password: "password123!", maxScore: 1, warning: "common password"},
+		{name: "repeated characters", password: "aaaaaaaaaaaaaaaa", maxScore: 0},
+		{name: "sequence", password: "abcdefghijklmnop", maxScore: 0},
+		{name: "short random", password: "kX9#", maxScore: 1},
+		{name: "mixed classes", password: "Tr0ub4dor&3", minScore: 3, maxScore: 3},
+		{name: "passphrase", password: "correct horse battery staple", minScore: 4, maxScore: 4},
+		{name: "test fixture", password: "test-password-123", minScore: DefaultMinScore, maxScore: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := Estimate(tt.password)
+			if s.Score < tt.minScore || s.Score > tt.maxScore {
+				t.Errorf("Estimate(%q).Score = %d (%.1f bits), want %d-%d", tt.password, s.Score, s.Entropy, tt.minScore, tt.maxScore)
+			}
+			if tt.warning != "" && !strings.Contains(s.Warning, tt.warning) {
+				t.Errorf("Estimate(%q).Warning = %q, want it to contain %q", tt.password, s.Warning, tt.warning)
+			}
+			if s.Label() == "" {
+				t.Errorf("Estimate(%q).Label() is empty", tt.password)
+			}
+		})
+	}
+}
+
+func TestCheckStrength(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		minScore int
+		wantErr  bool
+	}{
+		{name: "disabled", password: "password", minScore: 0, wantErr: false},
+		{name: "weak rejected", password: "hunter2", minScore: DefaultMinScore, wantErr: true},
+		{name: "strong accepted", password: "correct horse battery staple", minScore: MaxScore, wantErr: false},
+		{name: "fair below strict minimum", password: "test-password-123", minScore: MaxScore, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckStrength(tt.password, tt.minScore)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckStrength() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var validationErr *types.ValidationError
+			if err != nil && !errors.As(err, &validationErr) {
+				t.Errorf("CheckStrength() error type = %T, want *types.ValidationError", err)
+			}
+		})
+	}
+}
+
+func TestGetNewPassword(t *testing.T) {
+	t.Setenv("TEST_NEW_PASSWORD_WEAK", "hunter2")
+	t.Setenv("TEST_NEW_PASSWORD_STRONG", "correct horse battery staple")
+
+	if _, err := GetNewPassword(Options{PasswordEnv: "TEST_NEW_PASSWORD_WEAK"}, DefaultMinScore); err == nil {
+		t.Error("GetNewPassword() accepted a weak password")
+	}
+
+	got, err := GetNewPassword(Options{PasswordEnv: "TEST_NEW_PASSWORD_STRONG"}, DefaultMinScore)
+	if err != nil {
+		t.Fatalf("GetNewPassword() error = %v", err)
+	}
+	if got != "correct horse battery staple" {
+		t.Errorf("GetNewPassword() = %q, want the env password", got)
+	}
+}
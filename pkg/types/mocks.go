@@ -29,6 +29,10 @@ type MockArchiver struct {
 	PackFunc                 func(opts PackOptions) error
 	UnpackFunc               func(opts UnpackOptions) error
 	ListFunc                 func(archivePath, password string) (*Archive, error)
+	ReadFunc                 func(archivePath, password string) (*Archive, map[string][]byte, error)
+	RekeyFunc                func(archivePath, oldPassword, newPassword string) error
+	UpdateFunc               func(opts UpdateOptions) (*Archive, error)
+	VerifyFunc               func(archivePath, password string) (*VerifyResult, error)
 	GetAvailableArchivesFunc func(dir string) ([]string, error)
 }
 
@@ -53,6 +57,34 @@ func (m *MockArchiver) List(archivePath, password string) (*Archive, error) {
 	return &Archive{}, nil
 }
 
+func (m *MockArchiver) Read(archivePath, password string) (*Archive, map[string][]byte, error) {
+	if m.ReadFunc != nil {
+		return m.ReadFunc(archivePath, password)
+	}
+	return &Archive{}, map[string][]byte{}, nil
+}
+
+func (m *MockArchiver) Rekey(archivePath, oldPassword, newPassword string) error {
+	if m.RekeyFunc != nil {
+		return m.RekeyFunc(archivePath, oldPassword, newPassword)
+	}
+	return nil
+}
+
+func (m *MockArchiver) Update(opts UpdateOptions) (*Archive, error) {
+	if m.UpdateFunc != nil {
+		return m.UpdateFunc(opts)
+	}
+	return &Archive{}, nil
+}
+
+func (m *MockArchiver) Verify(archivePath, password string) (*VerifyResult, error) {
+	if m.VerifyFunc != nil {
+		return m.VerifyFunc(archivePath, password)
+	}
+	return &VerifyResult{Archive: &Archive{}}, nil
+}
+
 func (m *MockArchiver) GetAvailableArchives(dir string) ([]string, error) {
 	if m.GetAvailableArchivesFunc != nil {
 		return m.GetAvailableArchivesFunc(dir)
@@ -91,6 +123,7 @@ func (m *MockCryptor) ValidatePassword(data []byte, password string) error {
 // MockConfigManager implements ConfigManager interface for testing
 type MockConfigManager struct {
 	LoadFunc       func() (*Config, error)
+	LoadUserFunc   func() (*Config, error)
 	SaveFunc       func(config *Config) error
 	GetDefaultFunc func() *Config
 	ValidateFunc   func(config *Config) error
@@ -103,6 +136,13 @@ func (m *MockConfigManager) Load() (*Config, error) {
 	return &Config{}, nil
 }
 
+func (m *MockConfigManager) LoadUser() (*Config, error) {
+	if m.LoadUserFunc != nil {
+		return m.LoadUserFunc()
+	}
+	return &Config{}, nil
+}
+
 func (m *MockConfigManager) Save(config *Config) error {
 	if m.SaveFunc != nil {
 		return m.SaveFunc(config)
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"errors"
+	"fmt"
 	"time"
 )
 
@@ -11,24 +13,93 @@ type EnvFile struct {
 	Size         int64     `json:"size"`
 	ModTime      time.Time `json:"mod_time"`
 	Checksum     string    `json:"checksum"`
+	LinkTarget   string    `json:"link_target,omitempty"`
 }
 
 // Archive represents the structure of an encrypted archive
 type Archive struct {
-	CreatedAt   time.Time `json:"created_at"`
-	Files       []EnvFile `json:"files"`
-	TotalSize   int64     `json:"total_size"`
-	Description string    `json:"description"`
-	Version     string    `json:"version"`
+	CreatedAt   time.Time  `json:"created_at"`
+	Files       []EnvFile  `json:"files"`
+	TotalSize   int64      `json:"total_size"`
+	Description string     `json:"description"`
+	Version     string     `json:"version"`
+	Profile     string     `json:"profile,omitempty"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"` // last in-place change, if any
+	NotBefore   *time.Time `json:"not_before,omitempty"` // unpack refuses the archive before this time
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"` // unpack refuses the archive from this time on
+}
+
+// Archive validity states reported by Archive.Validity
+const (
+	ValidityNotYet  = "not valid yet"
+	ValidityExpired = "expired"
+)
+
+// Validity returns ValidityNotYet when now is before the archive's
+// NotBefore time, ValidityExpired when it is at or after its ExpiresAt
+// time, and "" otherwise
+func (a *Archive) Validity(now time.Time) string {
+	switch {
+	case a.NotBefore != nil && now.Before(*a.NotBefore):
+		return ValidityNotYet
+	case a.ExpiresAt != nil && !now.Before(*a.ExpiresAt):
+		return ValidityExpired
+	}
+	return ""
+}
+
+// CheckValidity returns an error wrapping ErrArchiveExpired when now is
+// outside the archive's validity period. The period is part of the
+// encrypted metadata, so it cannot be changed without the password.
+func (a *Archive) CheckValidity(now time.Time) error {
+	const layout = "2006-01-02 15:04:05 MST"
+	switch a.Validity(now) {
+	case ValidityNotYet:
+		return fmt.Errorf("%w: not valid before %s", ErrArchiveExpired, a.NotBefore.Local().Format(layout))
+	case ValidityExpired:
+		return fmt.Errorf("%w: expired %s", ErrArchiveExpired, a.ExpiresAt.Local().Format(layout))
+	}
+	return nil
 }
 
 // Config holds application configuration
 type Config struct {
-	DefaultDepth       int      `json:"default_depth" validate:"min=1,max=10"`
-	EnvPatterns        []string `json:"env_patterns" validate:"required,min=1"`
-	EnvExcludePatterns []string `json:"env_exclude_patterns"`
-	ExcludePatterns    []string `json:"exclude_patterns"`
-	MaxFileSize        int64    `json:"max_file_size"`
+	Version            int                `json:"version,omitempty"` // config schema version
+	DefaultDepth       int                `json:"default_depth" validate:"min=1,max=10"`
+	EnvPatterns        []string           `json:"env_patterns" validate:"required,min=1"`
+	EnvExcludePatterns []string           `json:"env_exclude_patterns"`
+	ExcludePatterns    []string           `json:"exclude_patterns"`
+	MaxFileSize        int64              `json:"max_file_size"`
+	MinPasswordScore   int                `json:"min_password_score"` // 0-4, 0 disables the strength check
+	SymlinkPolicy      string             `json:"symlink_policy,omitempty"`
+	SignaturePolicy    string             `json:"signature_policy,omitempty"`
+	AuditLog           string             `json:"audit_log,omitempty"`
+	Workspaces         []string           `json:"workspaces,omitempty"`
+	Profiles           map[string]Profile `json:"profiles,omitempty"`
+	Retention          *Retention         `json:"retention,omitempty"` // default prune rules; profiles may override
+}
+
+// Profile is a named set of pack/unpack options for one environment, such as
+// dev, staging or prod
+type Profile struct {
+	EnvPatterns     []string   `json:"env_patterns,omitempty"`
+	ExcludePatterns []string   `json:"exclude_patterns,omitempty"`
+	ArchivePrefix   string     `json:"archive_prefix,omitempty"` // defaults to the profile name
+	PasswordEnv     string     `json:"password_env,omitempty"`
+	PasswordFile    string     `json:"password_file,omitempty"`
+	PasswordFD      int        `json:"password_fd,omitempty"`
+	PasswordCmd     string     `json:"password_cmd,omitempty"`
+	Target          string     `json:"target,omitempty"` // default unpack directory
+	Retention       *Retention `json:"retention,omitempty"`
+}
+
+// Retention selects the archives 'goingenv prune' keeps. Zero values
+// disable a rule; an archive is kept if any rule keeps it.
+type Retention struct {
+	KeepLast   int    `json:"keep_last,omitempty"`
+	KeepDaily  int    `json:"keep_daily,omitempty"`
+	KeepWeekly int    `json:"keep_weekly,omitempty"`
+	OlderThan  string `json:"older_than,omitempty"` // only prune archives older than this, e.g. "90d"
 }
 
 // App holds all the application dependencies
@@ -40,6 +111,43 @@ type App struct {
 	ConfigMgr ConfigManager
 }
 
+// Symlink policies control how the scanner treats symbolic links
+const (
+	// SymlinkSkip ignores symlinked files and directories
+	SymlinkSkip = "skip"
+	// SymlinkFollow packs the link target's content and descends into linked directories
+	SymlinkFollow = "follow"
+	// SymlinkPreserve stores symlinked env files as links inside the archive
+	SymlinkPreserve = "preserve-as-link"
+)
+
+// SymlinkPolicies lists the accepted symlink policy values
+var SymlinkPolicies = []string{SymlinkSkip, SymlinkFollow, SymlinkPreserve}
+
+// Signature policies control how unpack and list treat archive signatures
+const (
+	// SignatureOff skips signature checks
+	SignatureOff = "off"
+	// SignatureWarn warns about unsigned archives and unknown signers
+	SignatureWarn = "warn"
+	// SignatureRequire refuses archives without a trusted signature
+	SignatureRequire = "require"
+)
+
+// SignaturePolicies lists the accepted signature policy values
+var SignaturePolicies = []string{SignatureOff, SignatureWarn, SignatureRequire}
+
+// Audit log modes choose where operations are recorded
+const (
+	// AuditProject records operations in the project's .goingenv/audit.log
+	AuditProject = "project"
+	// AuditProjectAndUser also records them in the user's state directory
+	AuditProjectAndUser = "project-and-user"
+)
+
+// AuditLogModes lists the accepted audit log values
+var AuditLogModes = []string{AuditProject, AuditProjectAndUser}
+
 // ScanOptions represents options for file scanning
 type ScanOptions struct {
 	RootPath           string
@@ -47,6 +155,7 @@ type ScanOptions struct {
 	Patterns           []string
 	EnvExcludePatterns []string
 	ExcludePatterns    []string
+	SymlinkPolicy      string
 }
 
 // PackOptions represents options for packing files
@@ -55,6 +164,20 @@ type PackOptions struct {
 	OutputPath  string
 	Password    string
 	Description string
+	Profile     string
+	NotBefore   *time.Time
+	ExpiresAt   *time.Time
+}
+
+// UpdateOptions represents changes to apply to an existing archive
+type UpdateOptions struct {
+	ArchivePath string
+	Password    string
+	Put         []EnvFile // files to add, replacing entries with the same relative path
+	Remove      []string  // relative paths of entries to remove
+	// Contents holds the content of Put files by relative path; files
+	// without an entry are read from their Path
+	Contents map[string][]byte
 }
 
 // UnpackOptions represents options for unpacking files
@@ -64,6 +187,8 @@ type UnpackOptions struct {
 	TargetDir   string
 	Overwrite   bool
 	Backup      bool
+	// IgnoreExpiry unpacks archives outside their validity period
+	IgnoreExpiry bool
 }
 
 // Interfaces for better testability and decoupling
@@ -74,11 +199,28 @@ type Scanner interface {
 	ValidateFile(path string) error
 }
 
+// VerifyResult is the outcome of checking every entry of an archive against
+// its metadata
+type VerifyResult struct {
+	Archive  *Archive // metadata; nil when it could not be read
+	Entries  int      // file and link entries checked
+	Problems []string // empty when the archive is intact
+}
+
+// OK reports whether the archive passed verification
+func (r *VerifyResult) OK() bool {
+	return len(r.Problems) == 0
+}
+
 // Archiver interface for archive operations
 type Archiver interface {
 	Pack(opts PackOptions) error
 	Unpack(opts UnpackOptions) error
 	List(archivePath, password string) (*Archive, error)
+	Read(archivePath, password string) (*Archive, map[string][]byte, error)
+	Rekey(archivePath, oldPassword, newPassword string) error
+	Update(opts UpdateOptions) (*Archive, error)
+	Verify(archivePath, password string) (*VerifyResult, error)
 	GetAvailableArchives(dir string) ([]string, error)
 }
 
@@ -92,6 +234,7 @@ type Cryptor interface {
 // ConfigManager interface for configuration management
 type ConfigManager interface {
 	Load() (*Config, error)
+	LoadUser() (*Config, error) // the user layer alone, for saving changes
 	Save(config *Config) error
 	GetDefault() *Config
 	Validate(config *Config) error
@@ -116,6 +259,20 @@ type MenuItem struct {
 	Action      string
 }
 
+// Sentinel errors for failures callers need to tell apart. They are wrapped
+// by the error types below and matched with errors.Is.
+var (
+	ErrWrongPassword   = errors.New("invalid password or corrupted data")
+	ErrArchiveNotFound = errors.New("archive not found")
+	ErrConflict        = errors.New("file conflicts detected")
+	ErrNotInitialized  = errors.New("goingenv is not initialized in this directory")
+	ErrBadSignature    = errors.New("archive signature is invalid")
+	ErrUntrustedSigner = errors.New("archive is not signed by a trusted signer")
+	ErrIntegrity       = errors.New("archive integrity check failed")
+	ErrPlaintextStaged = errors.New("plaintext env files are staged for commit")
+	ErrArchiveExpired  = errors.New("archive is outside its validity period")
+)
+
 // Custom error types for better error handling
 
 // ScanError represents an error during file scanning
@@ -128,6 +285,10 @@ func (e *ScanError) Error() string {
 	return "scan error at " + e.Path + ": " + e.Err.Error()
 }
 
+func (e *ScanError) Unwrap() error {
+	return e.Err
+}
+
 // ArchiveError represents an error during archive operations
 type ArchiveError struct {
 	Operation string
@@ -139,6 +300,10 @@ func (e *ArchiveError) Error() string {
 	return e.Operation + " error for " + e.Path + ": " + e.Err.Error()
 }
 
+func (e *ArchiveError) Unwrap() error {
+	return e.Err
+}
+
 // CryptoError represents an error during cryptographic operations
 type CryptoError struct {
 	Operation string
@@ -149,6 +314,10 @@ func (e *CryptoError) Error() string {
 	return "crypto " + e.Operation + " error: " + e.Err.Error()
 }
 
+func (e *CryptoError) Unwrap() error {
+	return e.Err
+}
+
 // ValidationError represents a validation error
 type ValidationError struct {
 	Field   string
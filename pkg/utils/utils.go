@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/user"
 	"path/filepath"
 	"strings"
 	"time"
@@ -40,6 +41,33 @@ func CalculateFileChecksum(filePath string) (string, error) {
 	return fmt.Sprintf("%x", hash.Sum(nil)), nil
 }
 
+// WriteFileAtomic writes data to a temporary file in the same directory and
+// renames it over path, so readers never observe a partially written file
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer os.Remove(tmpPath) // no-op once the rename succeeds
+
+	err = tmp.Chmod(perm)
+	if err == nil {
+		_, err = tmp.Write(data)
+	}
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
 // SanitizeFilename sanitizes a filename for safe use in file paths
 func SanitizeFilename(filename string) string {
 	result := ""
@@ -140,3 +168,13 @@ func FormatTimeAgo(t time.Time) string {
 		return t.Format("2006-01-02")
 	}
 }
+
+// CurrentUserName returns the current user's login name without any Windows
+// domain, or "" when it cannot be determined
+func CurrentUserName() string {
+	u, err := user.Current()
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(filepath.ToSlash(u.Username))
+}
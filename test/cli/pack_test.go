@@ -52,7 +52,7 @@ func TestPack_NotInitialized(t *testing.T) {
 	result := testutils.RunCLIWithPassword(t, tmpDir, fixtures.Password, "pack")
 
 	testutils.AssertFailure(t, result)
-	testutils.AssertExitCode(t, result, 1)
+	testutils.AssertExitCode(t, result, 4)
 }
 
 func TestPack_NoEnvFilesFound(t *testing.T) {
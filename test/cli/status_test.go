@@ -34,7 +34,7 @@ func TestStatus_NotInitialized(t *testing.T) {
 	if result.Success() {
 		testutils.AssertOutputContains(t, result, "not")
 	} else {
-		testutils.AssertExitCode(t, result, 1)
+		testutils.AssertExitCode(t, result, 4)
 	}
 	_ = output
 }
@@ -50,7 +50,7 @@ func TestUnpack_WrongPassword(t *testing.T) {
 	result := testutils.RunCLIWithPassword(t, tmpDir, fixtures.WrongPassword, "unpack", "--file", archivePath)
 
 	testutils.AssertFailure(t, result)
-	testutils.AssertExitCode(t, result, 1)
+	testutils.AssertExitCode(t, result, 5)
 }
 
 func TestUnpack_NonExistentArchive(t *testing.T) {
@@ -372,7 +372,7 @@ func TestE2E_ErrorHandling(t *testing.T) {
 	t.Run("PackWithoutInit", func(t *testing.T) {
 		result := testutils.RunBinaryWithPassword(t, binary, tmpDir, fixtures.Password, "pack")
 		testutils.AssertFailure(t, result)
-		testutils.AssertExitCode(t, result, 1)
+		testutils.AssertExitCode(t, result, 4)
 	})
 
 	t.Run("UnpackWithWrongPassword", func(t *testing.T) {